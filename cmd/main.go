@@ -5,6 +5,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -22,6 +25,8 @@ import (
 	"github.com/mysteriumnetwork/discovery/health"
 	"github.com/mysteriumnetwork/discovery/listener"
 	"github.com/mysteriumnetwork/discovery/middleware"
+	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	"github.com/mysteriumnetwork/discovery/price/store"
 	"github.com/mysteriumnetwork/discovery/proposal"
 	"github.com/mysteriumnetwork/discovery/proposal/aggregate"
 	"github.com/mysteriumnetwork/discovery/quality"
@@ -66,7 +71,19 @@ func main() {
 	aggregatedRepo := aggregate.NewRepository(cfg.ProposalsHardLimitPerCountry, cfg.ProposalsSoftLimitPerCountry)
 	qualityOracleAPI := oracleapi.New(cfg.QualityOracleURL.String())
 	qualityService := quality.NewService(qualityOracleAPI, cfg.QualityCacheTTL)
-	proposalService := proposal.NewService(proposalRepo, aggregatedRepo, qualityService)
+	qualityAggregator, err := aggregate.NewQualityAggregator(cfg.AggregatedQualityMode, cfg.AggregatedQualityWeights)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to configure aggregated quality")
+	}
+	var pricer proposal.Pricer
+	if cfg.ProposalPrices {
+		if getter, err := newPriceGetter(cfg); err != nil {
+			log.Err(err).Msg("Failed to initialize the price getter, serving proposals without prices")
+		} else {
+			pricer = getter
+		}
+	}
+	proposalService := proposal.NewService(proposalRepo, aggregatedRepo, qualityService, qualityAggregator, pricer)
 	go proposalService.StartExpirationJob()
 	defer proposalService.Shutdown()
 
@@ -86,6 +103,7 @@ func main() {
 		cfg.ProposalsCacheTTL,
 		cfg.ProposalsCacheLimit,
 		cfg.CountriesCacheLimit,
+		cfg.StatsCacheLimit,
 	)
 	proposalsAPI.RegisterRoutes(v3)
 	proposalsAPI.RegisterRoutes(v4)
@@ -108,6 +126,28 @@ func main() {
 	}
 }
 
+func newPriceGetter(cfg *config.Options) (*pricingbyservice.PriceGetter, error) {
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:    cfg.RedisAddress,
+		Password: cfg.RedisPass,
+		DB:       cfg.RedisDB,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("could not reach redis: %w", err)
+	}
+
+	getter, err := pricingbyservice.NewPriceGetter(store.NewRedis(rdb))
+	if err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("failed to initialize price getter by service: %w", err)
+	}
+
+	return getter, nil
+}
+
 func connectToBroker(brokerURL string, proposalRepo *proposal.Repository, aggregatedRepo *aggregate.Repository) *listener.Listener {
 	brokerListener := listener.New(brokerURL, proposalRepo, aggregatedRepo)
 
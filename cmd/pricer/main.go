@@ -6,19 +6,28 @@ package main
 
 import (
 	"context"
+	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 	_ "go.uber.org/automaxprocs"
 
 	"github.com/mysteriumnetwork/discovery/config"
 	_ "github.com/mysteriumnetwork/discovery/docs"
+	"github.com/mysteriumnetwork/discovery/metrics"
 	"github.com/mysteriumnetwork/discovery/middleware"
 	"github.com/mysteriumnetwork/discovery/price"
+	"github.com/mysteriumnetwork/discovery/price/pricesig"
 	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	"github.com/mysteriumnetwork/discovery/price/store"
+	"github.com/mysteriumnetwork/discovery/sidecar"
 	mlog "github.com/mysteriumnetwork/logger"
 )
 
@@ -43,46 +52,103 @@ func main() {
 	r.Use(middleware.ErrorHandler)
 	r.Use(middleware.Logger)
 
-	rdb := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs:    cfg.RedisAddress,
-		Password: cfg.RedisPass,
-		DB:       cfg.RedisDB,
+	st, err := store.Open(store.Options{
+		Type: cfg.StoreType,
+		File: cfg.StoreFile,
+		Redis: &redis.UniversalOptions{
+			Addrs:    cfg.RedisAddress,
+			Password: cfg.RedisPass,
+			DB:       cfg.RedisDB,
+		},
 	})
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
-	st := rdb.Ping(ctx)
-	err = st.Err()
 	if err != nil {
-		log.Fatal().Err(err).Msg("could not reach redis")
+		log.Fatal().Err(err).Msg("could not open store")
+	}
+	defer func() {
+		if err := st.Close(); err != nil {
+			log.Err(err).Msg("could not close store")
+		}
+	}()
+
+	if cfg.EmbedSidecar {
+		sideCfg, err := sidecar.ReadEmbeddedConfig()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to read sidecar config")
+		}
+		metrics.InitialiseMonitoring()
+		r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+		updater, err := sidecar.StartUpdater(sideCfg, st)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to start price updater")
+		}
+		defer updater.Stop()
+		log.Info().Msg("embedded price updater started")
 	}
 
 	v4 := r.Group("/api/v4")
 
-	cfger := pricingbyservice.NewConfigProviderDB(rdb)
-
-	_, err = cfger.Get()
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to load cfg")
-	}
-
-	cfgerByService := pricingbyservice.NewConfigProviderDB(rdb)
-	_, err = cfger.Get()
+	cfgerByService := pricingbyservice.NewConfigProviderDB(st)
+	_, err = cfgerByService.Get()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to load cfg by service")
 	}
 
-	getterByService, err := pricingbyservice.NewPriceGetter(rdb)
+	getterByService, err := pricingbyservice.NewPriceGetter(st)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to initialize price getter by service")
 	}
+	getterByService.Start()
+	defer getterByService.Stop()
 
 	ac := middleware.NewJWTChecker(cfg.SentinelURL, cfg.UniverseJWTSecret)
-	price.NewAPIByService(rdb, getterByService, cfgerByService, ac).RegisterRoutes(v4)
+	history := pricingbyservice.NewPriceHistoryStorage(st, 0)
+	demandIndexes := pricingbyservice.NewDemandIndexStorage(st, 0)
+	if _, ok := os.LookupEnv("PRICE_SIGNING_SECRET"); ok {
+		log.Warn().Msg("PRICE_SIGNING_SECRET is deprecated and ignored, /prices/at is signed with PRICE_SIGNING_KEY")
+	}
+	var signer *pricesig.Signer
+	if cfg.PriceSigningKey != "" {
+		key, err := pricesig.ParsePrivateKey(cfg.PriceSigningKey)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to parse price signing key")
+		}
+		signer = pricesig.NewSigner(key)
+		log.Info().Msgf("prices signed with key %s", signer.PublicKey().KeyID)
+	}
+	price.NewAPIByService(st, getterByService, cfgerByService, history, demandIndexes, signer, ac).RegisterRoutes(v4)
 
-	if err := r.Run(); err != nil {
+	srv := &http.Server{
+		Addr:    address(),
+		Handler: r,
+	}
+	failed := make(chan error, 1)
+	go func() {
+		failed <- srv.ListenAndServe()
+	}()
+
+	// shut down gracefully so the deferred stops and the pending writes of the file store run
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-failed:
 		log.Err(err).Send()
 		return
+	case <-sigs:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Err(err).Msg("server shutdown failed")
+	}
+}
+
+// address is the address to listen on, on the PORT or 8080 like gin.
+func address() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
 	}
+	return ":8080"
 }
 
 func printBanner() {
@@ -0,0 +1,165 @@
+// Copyright (c) 2021 BlockDev AG
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// pricesim replays recorded MYST rates and demand indexes through the price
+// generation of the pricer to backtest a price config before deploying it.
+//
+//	pricesim -config config.json -series series.json -countries US,DE -format csv -stats stats.csv
+//
+// The config is a price config as returned by /prices/config. The series is a
+// JSON array of steps, e.g. [{"time": "2024-01-01T00:00:00Z", "myst_usd": 0.2,
+// "demand_indexes": {"US": 0.3}}], where demand indexes and supply carry over
+// to the following steps until changed.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	"github.com/rs/zerolog"
+)
+
+func main() {
+	configPath := flag.String("config", "", "Price config JSON file")
+	seriesPath := flag.String("series", "", "JSON file of the steps to replay")
+	countries := flag.String("countries", "", "Comma separated countries to output, the defaults when empty")
+	format := flag.String("format", "csv", "Output format: csv or json")
+	outPath := flag.String("out", "", "File of the price series, stdout when empty")
+	statsPath := flag.String("stats", "", "File of the summary stats in csv format, stderr when empty")
+	flag.Parse()
+
+	// the per update logs of the pricer would drown the stats
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+
+	if err := run(*configPath, *seriesPath, *countries, *format, *outPath, *statsPath); err != nil {
+		fmt.Fprintln(os.Stderr, "pricesim:", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath, seriesPath, countries, format, outPath, statsPath string) error {
+	if configPath == "" || seriesPath == "" {
+		return fmt.Errorf("-config and -series are required")
+	}
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	var cfg pricingbyservice.Config
+	if err := readJSON(configPath, &cfg); err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	var steps []pricingbyservice.SimulationStep
+	if err := readJSON(seriesPath, &steps); err != nil {
+		return fmt.Errorf("read series: %w", err)
+	}
+
+	var selected []string
+	if countries != "" {
+		selected = strings.Split(countries, ",")
+	}
+	res, err := pricingbyservice.Simulate(cfg, steps, selected)
+	if err != nil {
+		return err
+	}
+
+	out := io.Writer(os.Stdout)
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	if err := writePointsCSV(out, res.Points); err != nil {
+		return err
+	}
+
+	stats := io.Writer(os.Stderr)
+	if statsPath != "" {
+		f, err := os.Create(statsPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		stats = f
+	}
+	return writeStatsCSV(stats, res.Stats)
+}
+
+func readJSON(path string, v interface{}) error {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(blob, v)
+}
+
+func writePointsCSV(w io.Writer, points []pricingbyservice.SimulationPoint) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "country", "node_type", "service_type", "myst_rate", "price_per_hour", "price_per_gib", "boost_multiplier", "clamped"})
+	for _, p := range points {
+		cw.Write([]string{
+			p.Time.UTC().Format(time.RFC3339),
+			p.Country,
+			p.NodeType,
+			string(p.ServiceType),
+			formatFloat(p.MystRate),
+			formatFloat(p.PricePerHour),
+			formatFloat(p.PricePerGiB),
+			formatFloat(p.BoostMultiplier),
+			strconv.FormatBool(p.Clamped),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeStatsCSV(w io.Writer, stats []pricingbyservice.SimulationStats) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"country", "node_type", "service_type", "steps",
+		"min_price_per_gib", "max_price_per_gib", "mean_price_per_gib",
+		"per_hour_volatility", "per_gib_volatility", "max_per_gib_change",
+		"boost_frequency", "clamps",
+	})
+	for _, s := range stats {
+		cw.Write([]string{
+			s.Country,
+			s.NodeType,
+			string(s.ServiceType),
+			strconv.Itoa(s.Steps),
+			formatFloat(s.MinPricePerGiB),
+			formatFloat(s.MaxPricePerGiB),
+			formatFloat(s.MeanPricePerGiB),
+			formatFloat(s.PerHourVolatility),
+			formatFloat(s.PerGiBVolatility),
+			formatFloat(s.MaxPerGiBChange),
+			formatFloat(s.BoostFrequency),
+			strconv.Itoa(s.Clamps),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
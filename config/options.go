@@ -25,9 +25,21 @@ type Options struct {
 	RedisPass    string
 	RedisDB      int
 
+	// ProposalPrices enriches the proposals with the prices stored in Redis.
+	ProposalPrices bool
+
+	// StoreType is the store of the pricer: redis, memory or file.
+	StoreType string
+	StoreFile string
+	// EmbedSidecar runs the price updater of the sidecar within the pricer.
+	EmbedSidecar bool
+
 	UniverseJWTSecret string
 	SentinelURL       string
 
+	// PriceSigningKey is the base64 encoded ed25519 key the prices are signed with.
+	PriceSigningKey string
+
 	DevPass      string
 	InternalPass string
 
@@ -41,6 +53,10 @@ type Options struct {
 	ProposalsCacheTTL   time.Duration
 	ProposalsCacheLimit int
 	CountriesCacheLimit int
+	StatsCacheLimit     int
+
+	AggregatedQualityMode    string
+	AggregatedQualityWeights map[string]float64
 }
 
 func ReadDiscovery() (*Options, error) {
@@ -64,6 +80,10 @@ func ReadDiscovery() (*Options, error) {
 	if err != nil {
 		return nil, err
 	}
+	statsCacheLimit, err := OptionalEnvInt("STATS_CACHE_LIMIT", "100")
+	if err != nil {
+		return nil, err
+	}
 	brokerURL, err := RequiredEnvURLs("BROKER_URL")
 	if err != nil {
 		return nil, err
@@ -93,6 +113,24 @@ func ReadDiscovery() (*Options, error) {
 		return nil, fmt.Errorf("failed to parse max requests limit: %w", err)
 	}
 
+	var redisAddress []string
+	if addr := OptionalEnv("REDIS_ADDRESS", ""); addr != "" {
+		redisAddress = strings.Split(addr, ";")
+	}
+	redisPass := OptionalEnv("REDIS_PASS", "")
+	redisDB, err := OptionalEnvInt("REDIS_DB", "0")
+	if err != nil {
+		return nil, err
+	}
+
+	proposalPrices := OptionalEnvBool("PROPOSAL_PRICES")
+
+	aggregatedQualityMode := OptionalEnv("AGGREGATED_QUALITY_MODE", "max")
+	aggregatedQualityWeights, err := OptionalEnvFloatMap("AGGREGATED_QUALITY_WEIGHTS", "")
+	if err != nil {
+		return nil, err
+	}
+
 	return &Options{
 		QualityOracleURL:             *qualityOracleURL,
 		QualityCacheTTL:              *qualityCacheTTL,
@@ -103,10 +141,17 @@ func ReadDiscovery() (*Options, error) {
 		ProposalsCacheTTL:            *proposalsCacheTTL,
 		ProposalsCacheLimit:          proposalsCacheLimit,
 		CountriesCacheLimit:          countriesCacheLimit,
+		StatsCacheLimit:              statsCacheLimit,
 		CompatibilityMin:             compatibility,
 		LogLevel:                     logLevel,
 		ProposalsHardLimitPerCountry: proposalsHardLimitPerCountry,
 		ProposalsSoftLimitPerCountry: proposalsSoftLimitPerCountry,
+		AggregatedQualityMode:        aggregatedQualityMode,
+		AggregatedQualityWeights:     aggregatedQualityWeights,
+		RedisAddress:                 redisAddress,
+		RedisPass:                    redisPass,
+		RedisDB:                      redisDB,
+		ProposalPrices:               proposalPrices,
 	}, nil
 }
 
@@ -115,9 +160,9 @@ func ReadPricer() (*Options, error) {
 	if err != nil {
 		return nil, err
 	}
-	redisAddress, err := RequiredEnv("REDIS_ADDRESS")
-	if err != nil {
-		return nil, err
+	var redisAddress []string
+	if addr := OptionalEnv("REDIS_ADDRESS", ""); addr != "" {
+		redisAddress = strings.Split(addr, ";")
 	}
 
 	sentinelURL, err := RequiredEnv("SENTINEL_URL")
@@ -138,10 +183,18 @@ func ReadPricer() (*Options, error) {
 	}
 
 	logLevel := OptionalEnv("LOG_LEVEL", "debug")
+	priceSigningKey := OptionalEnv("PRICE_SIGNING_KEY", "")
+	storeType := OptionalEnv("STORE", "redis")
+	storeFile := OptionalEnv("STORE_FILE", "")
+	embedSidecar := OptionalEnvBool("EMBED_SIDECAR")
 
 	return &Options{
+		PriceSigningKey:   priceSigningKey,
 		UniverseJWTSecret: universeJWTSecret,
-		RedisAddress:      strings.Split(redisAddress, ";"),
+		RedisAddress:      redisAddress,
+		StoreType:         storeType,
+		StoreFile:         storeFile,
+		EmbedSidecar:      embedSidecar,
 		RedisPass:         redisPass,
 		RedisDB:           redisDBint,
 		SentinelURL:       sentinelURL,
@@ -227,6 +280,29 @@ func OptionalEnvInt(key string, defaults string) (int, error) {
 	return int(intVal), nil
 }
 
+// OptionalEnvFloatMap parses values in the "key:value;key:value" format.
+func OptionalEnvFloatMap(key string, defaults string) (map[string]float64, error) {
+	strVal := OptionalEnv(key, defaults)
+	res := make(map[string]float64)
+	if strVal == "" {
+		return res, nil
+	}
+
+	for _, pair := range strings.Split(strVal, ";") {
+		k, v, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("failed to parse %s from value '%s'", key, strVal)
+		}
+		floatVal, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s from value '%s'", key, strVal)
+		}
+		res[strings.TrimSpace(k)] = floatVal
+	}
+
+	return res, nil
+}
+
 func OptionalEnvURL(key string, defaults string) (*url.URL, error) {
 	strVal := OptionalEnv(key, defaults)
 	parsedURL, err := url.Parse(strVal)
@@ -32,46 +32,46 @@ func TestMain(m *testing.M) {
 func Test_LatestPrices(t *testing.T) {
 	prices, err := PricerAPI.LatestPrices()
 	assert.NoError(t, err)
-	assert.NotNil(t, prices.Defaults.Current.Residential.DVPN.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Residential.DVPN.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Residential.Scraping.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Residential.Scraping.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Residential.QUICScraping.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Residential.QUICScraping.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Residential.Wireguard.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Residential.Wireguard.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Residential.DataTransfer.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Residential.DataTransfer.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Other.DVPN.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Other.DVPN.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Other.Scraping.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Other.Scraping.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Other.QUICScraping.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Other.QUICScraping.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Other.Wireguard.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Other.Wireguard.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Current.Other.DataTransfer.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Current.Other.DataTransfer.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.DVPN.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.DVPN.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.Scraping.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.Scraping.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.QUICScraping.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.QUICScraping.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.Wireguard.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.Wireguard.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.DataTransfer.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Residential.DataTransfer.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Other.DVPN.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Other.DVPN.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Other.Scraping.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Other.Scraping.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Other.QUICScraping.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Other.QUICScraping.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Other.Wireguard.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Other.Wireguard.PricePerHour)
-	assert.NotNil(t, prices.Defaults.Previous.Other.DataTransfer.PricePerGiB)
-	assert.NotNil(t, prices.Defaults.Previous.Other.DataTransfer.PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeDVPN].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeDVPN].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeScraping].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeScraping].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeQUICScraping].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeQUICScraping].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeWireguard].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeWireguard].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeDataTransfer].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Residential[pricingbyservice.ServiceTypeDataTransfer].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeDVPN].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeDVPN].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeScraping].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeScraping].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeQUICScraping].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeQUICScraping].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeWireguard].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeWireguard].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeDataTransfer].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Current.Other[pricingbyservice.ServiceTypeDataTransfer].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeDVPN].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeDVPN].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeScraping].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeScraping].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeQUICScraping].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeQUICScraping].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeWireguard].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeWireguard].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeDataTransfer].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Residential[pricingbyservice.ServiceTypeDataTransfer].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeDVPN].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeDVPN].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeScraping].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeScraping].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeQUICScraping].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeQUICScraping].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeWireguard].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeWireguard].PricePerHour)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeDataTransfer].PricePerGiB)
+	assert.NotNil(t, prices.Defaults.Previous.Other[pricingbyservice.ServiceTypeDataTransfer].PricePerHour)
 
 	assert.Greater(t, prices.CurrentValidUntil.UnixNano(), time.Unix(0, 0).UnixNano())
 	assert.Greater(t, prices.PreviousValidUntil.UnixNano(), time.Unix(0, 0).UnixNano())
@@ -113,7 +113,9 @@ func Test_PostConfig(t *testing.T) {
 		toSend := pricingbyservice.Config{}
 		err := json.Unmarshal([]byte(expectedPricingConfig), &toSend)
 		assert.NoError(t, err)
-		toSend.BasePrices.Other.Wireguard.PricePerGiB = 11
+		wireguard := toSend.BasePrices.Other[pricingbyservice.ServiceTypeWireguard]
+		wireguard.PricePerGiB = 11
+		toSend.BasePrices.Other[pricingbyservice.ServiceTypeWireguard] = wireguard
 
 		err = PricerAPI.UpdatePriceConfig(validToken, toSend)
 		assert.NoError(t, err)
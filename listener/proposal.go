@@ -92,6 +92,14 @@ func (l *Listener) Listen() error {
 		return err
 	}
 
+	if _, err := conn.Subscribe("*.proposal-register.v4", l.handleV4Proposal); err != nil {
+		return err
+	}
+
+	if _, err := conn.Subscribe("*.proposal-ping.v4", l.handleV4Proposal); err != nil {
+		return err
+	}
+
 	if _, err := conn.Subscribe("*.proposal-unregister.v3", func(msg *nats.Msg) {
 		unregisterMsg := v3.ProposalUnregisterMessage{}
 		if err := json.Unmarshal(msg.Data, &unregisterMsg); err != nil {
@@ -102,7 +110,26 @@ func (l *Listener) Listen() error {
 				Msg("Failed to unregister proposal")
 		} else {
 			l.repository.Remove(unregisterMsg.Key())
-			l.aggregated.Remove(unregisterMsg.Proposal.ProviderID) // v4 uses ProviderID as key
+			l.aggregated.Remove(unregisterMsg.Proposal.ProviderID, unregisterMsg.Proposal.ServiceType)
+		}
+	}); err != nil {
+		return err
+	}
+
+	if _, err := conn.Subscribe("*.proposal-unregister.v4", func(msg *nats.Msg) {
+		unregisterMsg := aggregate.ProposalUnregisterMessage{}
+		if err := json.Unmarshal(msg.Data, &unregisterMsg); err != nil {
+			log.Err(err).Msg("Failed to unregister v4 proposal")
+		} else if unregisterMsg.IsEmpty() {
+			log.Err(errors.New("unknown format")).
+				Bytes("message", msg.Data).
+				Msg("Failed to unregister v4 proposal")
+		} else if len(unregisterMsg.Proposal.Services) == 0 {
+			l.aggregated.RemoveProvider(unregisterMsg.Proposal.ProviderID)
+		} else {
+			for _, s := range unregisterMsg.Proposal.Services {
+				l.aggregated.Remove(unregisterMsg.Proposal.ProviderID, s.ServiceType)
+			}
 		}
 	}); err != nil {
 		return err
@@ -119,7 +146,7 @@ func (l *Listener) Listen() error {
 				Msg("Failed to unregister proposal")
 		} else {
 			l.repository.Remove(unregisterMsg.Key())
-			l.aggregated.Remove(unregisterMsg.Proposal.ProviderID) // v4 uses ProviderID as key
+			l.aggregated.Remove(unregisterMsg.Proposal.ProviderID, unregisterMsg.Proposal.ServiceType)
 		}
 	}); err != nil {
 		return err
@@ -128,6 +155,20 @@ func (l *Listener) Listen() error {
 	return nil
 }
 
+// handleV4Proposal stores the aggregated proposal of a v4 register or ping message.
+func (l *Listener) handleV4Proposal(msg *nats.Msg) {
+	pingMsg := aggregate.ProposalPingMessage{}
+	if err := json.Unmarshal(msg.Data, &pingMsg); err != nil {
+		log.Err(err).Msg("Failed to parse v4 proposal")
+	} else if pingMsg.IsEmpty() {
+		log.Err(errors.New("unknown format")).
+			Bytes("message", msg.Data).
+			Msg("Failed to parse v4 proposal")
+	} else if err := l.aggregated.Store(pingMsg.Proposal); err != nil {
+		log.Err(err).Msg("Failed to store v4 proposal")
+	}
+}
+
 func (l *Listener) Shutdown() {
 	log.Info().Msg("Shutting down broker listener")
 	l.conn.Close()
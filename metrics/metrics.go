@@ -10,8 +10,95 @@ var CurrentPriceByCountry = prometheus.NewGaugeVec(
 	[]string{"country_code", "node_type", "service_type", "price_type"},
 )
 
+var MystRate = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "discovery_myst_rate",
+		Help: "MYST rate in the base currency read from the market and the smoothed one used for pricing",
+	},
+	[]string{"rate", "currency"},
+)
+
+var PriceClamps = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "discovery_price_clamps_total",
+		Help: "Prices clamped for changing more than allowed in a single price update",
+	},
+	[]string{"node_type", "service_type", "price_type", "direction"},
+)
+
+var MarketSourcePrice = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "discovery_market_source_myst_rate",
+		Help: "MYST rate in a fiat currency reported by a market source",
+	},
+	[]string{"source", "currency"},
+)
+
+var MarketSourceDeviation = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "discovery_market_source_deviation",
+		Help: "Relative deviation of the MYST rate of a market source from the median of all sources",
+	},
+	[]string{"source", "currency"},
+)
+
+var MarketSourceOutliers = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "discovery_market_source_outliers_total",
+		Help: "MYST rates of a market source dropped as outliers",
+	},
+	[]string{"source", "currency"},
+)
+
+var MarketSourceDisagreements = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "discovery_market_source_disagreements_total",
+		Help: "MYST rates the market sources disagree on without enough of them agreeing to drop the outliers",
+	},
+	[]string{"currency"},
+)
+
+var MarketSourceErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "discovery_market_source_errors_total",
+		Help: "Failed MYST/USD rate requests to a market source",
+	},
+	[]string{"source"},
+)
+
+var MarketPriceAge = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "discovery_market_price_age_seconds",
+		Help: "Age of the last good aggregated MYST/USD rate",
+	},
+)
+
+var MarketPriceStale = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "discovery_market_price_stale",
+		Help: "1 when the aggregated MYST/USD rate is older than allowed and the last good one is used",
+	},
+)
+
+var PricerLeader = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "discovery_pricer_leader",
+		Help: "1 when this pricer replica holds the leadership and updates the prices",
+	},
+)
+
 func InitialiseMonitoring() {
 	prometheus.MustRegister(
 		CurrentPriceByCountry,
+		MystRate,
+		PriceClamps,
+		MarketSourcePrice,
+		MarketSourceDeviation,
+		MarketSourceOutliers,
+		MarketSourceDisagreements,
+		MarketSourceErrors,
+		MarketPriceAge,
+		MarketPriceStale,
+		PricerLeader,
 	)
 }
@@ -19,6 +19,14 @@ import (
 
 const discoveryAudienceName = "discovery"
 
+// JWTSubjectKey is the gin context key holding the subject of the authorized token.
+const JWTSubjectKey = "jwt_subject"
+
+// JWTSubject returns the subject of the token authorized by JWTAuthorized.
+func JWTSubject(c *gin.Context) string {
+	return c.GetString(JWTSubjectKey)
+}
+
 type JWTChecker struct {
 	SentinelURL string
 	Secret      string
@@ -51,9 +59,10 @@ func (j *JWTChecker) JWTAuthorized() func(*gin.Context) {
 		}
 
 		jwtToken := authHeader[1]
-		if err := j.newCheck(jwtToken); err != nil {
+		subject, err := j.newCheck(jwtToken)
+		if err != nil {
 			log.Warn().Err(err).Msg("new jwt check failed")
-			if err := j.oldCheck(jwtToken); err != nil {
+			if subject, err = j.oldCheck(jwtToken); err != nil {
 				c.AbortWithStatusJSON(
 					http.StatusUnauthorized,
 					map[string]string{
@@ -66,6 +75,7 @@ func (j *JWTChecker) JWTAuthorized() func(*gin.Context) {
 			}
 		}
 
+		c.Set(JWTSubjectKey, subject)
 		c.Next()
 	}
 }
@@ -137,22 +147,27 @@ func (j *JWTChecker) valid(jwtToken string) (bool, error) {
 	return false, errors.New("failed to validate token: error response from validation server")
 }
 
-func (j *JWTChecker) newCheck(jtoken string) error {
+func (j *JWTChecker) newCheck(jtoken string) (string, error) {
 	if valid, err := j.valid(jtoken); err != nil {
-		return err
+		return "", err
 	} else if !valid {
-		return errors.New("token invalid")
+		return "", errors.New("token invalid")
 	}
 
 	key, err := j.getPublicKey()
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	claims, err := token.NewValidatorJWT(key).ValidateForAudienceExtract(jtoken, discoveryAudienceName)
+	if err != nil {
+		return "", err
 	}
 
-	return token.NewValidatorJWT(key).ValidateForAudience(jtoken, discoveryAudienceName)
+	return claims.Subject, nil
 }
 
-func (j *JWTChecker) oldCheck(jtoken string) error {
+func (j *JWTChecker) oldCheck(jtoken string) (string, error) {
 	token, err := jwt.Parse(jtoken, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
@@ -160,18 +175,19 @@ func (j *JWTChecker) oldCheck(jtoken string) error {
 		return []byte(j.Secret), nil
 	})
 	if err != nil {
-		return errors.New("unauthorized")
+		return "", errors.New("unauthorized")
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
-			return errors.New("expired")
+			return "", errors.New("expired")
 		}
-		return nil
+		subject, _ := claims["sub"].(string)
+		return subject, nil
 
 	}
 
-	return errors.New("token invalid")
+	return "", errors.New("token invalid")
 }
 
 func body(in string) io.Reader {
@@ -2,15 +2,23 @@ package price
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 
+	"github.com/mysteriumnetwork/discovery/middleware"
+	"github.com/mysteriumnetwork/discovery/price/pricesig"
 	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	"github.com/mysteriumnetwork/discovery/price/store"
 	"github.com/mysteriumnetwork/go-rest/apierror"
 )
 
@@ -18,16 +26,35 @@ const (
 	errCodeParsingJson = "err_parsing_config"
 	errCodeMarshalJson = "err_marshal_prices"
 
-	errCodeNoConfig     = "err_no_config"
-	errCodeUpdateConfig = "err_update_config"
+	errCodeNoConfig       = "err_no_config"
+	errCodeUpdateConfig   = "err_update_config"
+	errCodeConfigVersions = "err_config_versions"
 
 	errRedisPingFailed = "failed_redis_ping"
+
+	errCodeInvalidQuery = "err_invalid_query"
+	errCodeNoHistory    = "err_no_history"
 )
 
+const maxPriceHistoryRange = 31 * 24 * time.Hour
+
+// maxPublicPriceHistoryRange caps the price history range of the callers without a token.
+const maxPublicPriceHistoryRange = 24 * time.Hour
+
+// priceStreamKeepAlive is how often the price stream sends a comment to keep idle connections open.
+const priceStreamKeepAlive = 30 * time.Second
+
 type APIByService struct {
-	pricer latestPricer
-	cfger  pricingbyservice.ConfigProvider
-	redis  redis.UniversalClient
+	pricer    latestPricer
+	stream    priceStream
+	cfger     pricingbyservice.ConfigProvider
+	versions  configVersions
+	campaigns campaigns
+	store     store.Store
+	history   priceHistory
+	demand    demandIndexes
+
+	signer *pricesig.Signer
 
 	ac authCheck
 }
@@ -36,34 +63,401 @@ type latestPricer interface {
 	GetPrices() pricingbyservice.LatestPrices
 }
 
+type priceStream interface {
+	Listen() (<-chan pricingbyservice.LatestPrices, func())
+}
+
+type configVersions interface {
+	Versions(limit int) ([]pricingbyservice.ConfigVersion, error)
+	Version(version int64) (pricingbyservice.ConfigVersion, error)
+	Rollback(version int64, author string) error
+}
+
+type campaigns interface {
+	UpsertCampaign(campaign pricingbyservice.Campaign, change pricingbyservice.ConfigChange) error
+	RemoveCampaign(id string, change pricingbyservice.ConfigChange) error
+}
+
+type priceHistory interface {
+	Series(ctx context.Context, from, to time.Time, q pricingbyservice.PriceHistoryQuery) ([]pricingbyservice.PriceHistoryPoint, error)
+	At(ctx context.Context, tm time.Time) (current, previous *pricingbyservice.PriceSnapshot, err error)
+}
+
+type demandIndexes interface {
+	Latest(ctx context.Context) (*pricingbyservice.DemandIndexSnapshot, error)
+	Range(ctx context.Context, from, to time.Time) ([]pricingbyservice.DemandIndexSnapshot, error)
+	Boost(ctx context.Context) (*pricingbyservice.DemandBoost, error)
+}
+
 type authCheck interface {
 	JWTAuthorized() func(*gin.Context)
 }
 
-func NewAPIByService(redis redis.UniversalClient, pricer *pricingbyservice.PriceGetter, cfger pricingbyservice.ConfigProvider, ac authCheck) *APIByService {
+func NewAPIByService(st store.Store, pricer *pricingbyservice.PriceGetter, cfger *pricingbyservice.ConfigProviderDB, history *pricingbyservice.PriceHistoryStorage, demand *pricingbyservice.DemandIndexStorage, signer *pricesig.Signer, ac authCheck) *APIByService {
 	return &APIByService{
-		pricer: pricer,
-		cfger:  cfger,
-		redis:  redis,
-		ac:     ac,
+		pricer:    pricer,
+		stream:    pricer,
+		cfger:     cfger,
+		versions:  cfger,
+		campaigns: cfger,
+		store:     st,
+		history:   history,
+		demand:    demand,
+		signer:    signer,
+		ac:        ac,
 	}
 }
 
 // LatestPrices returns latest prices
 // @Summary Latest Prices
-// @Description Latest Prices
+// @Description Latest Prices. The ETag does not depend on the current server time, so unchanged prices return 304 to If-None-Match.
+// @Description When a signing key is configured, the X-Price-Signature header holds the ed25519 signature of the response body, see /prices/public-key.
+// @Param countries query string false "Comma separated country codes. All when empty, the defaults are always returned."
+// @Param node_types query string false "Comma separated node types: residential or other. All when empty."
+// @Param service_types query string false "Comma separated service types. All when empty."
+// @Param compact query bool false "Return only the country prices which differ from the defaults"
 // @Product json
 // @Success 200 {array} pricing.LatestPrices
+// @Success 304
 // @Router /prices [get]
 // @Tags prices
 func (a *APIByService) LatestPrices(c *gin.Context) {
-	blob, err := json.Marshal(a.pricer.GetPrices())
+	filter, ok := latestPricesFilter(c)
+	if !ok {
+		return
+	}
+
+	lp := a.pricer.GetPrices()
+	if filter != nil {
+		lp = lp.Filter(*filter)
+	}
+
+	serverTime := lp.CurrentServerTime
+	lp.CurrentServerTime = time.Time{}
+	blob, err := json.Marshal(lp)
+	if err != nil {
+		log.Err(err).Msg("Failed to marshal latest prices")
+		c.Error(apierror.Internal(err.Error(), errCodeMarshalJson))
+		return
+	}
+	etag := priceETag(blob)
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	lp.CurrentServerTime = serverTime
+	blob, err = json.Marshal(lp)
 	if err != nil {
 		log.Err(err).Msg("Failed to marshal latest prices")
 		c.Error(apierror.Internal(err.Error(), errCodeMarshalJson))
 		return
 	}
 
+	a.sign(c, blob)
+	c.Data(http.StatusOK, gin.MIMEJSON, blob)
+}
+
+// sign sets the signature headers of the response body when a signing key is configured.
+func (a *APIByService) sign(c *gin.Context, body []byte) {
+	if a.signer == nil {
+		return
+	}
+	c.Header(pricesig.SignatureHeader, a.signer.Sign(body))
+	c.Header(pricesig.KeyIDHeader, a.signer.PublicKey().KeyID)
+}
+
+// PublicKey returns the key the prices are signed with
+// @Summary Price signing key
+// @Description The ed25519 public key verifying the X-Price-Signature header of the price responses. Clients should pin it instead of fetching it along with the prices.
+// @Product json
+// @Success 200 {object} pricesig.PublicKey
+// @Router /prices/public-key [get]
+// @Tags prices
+func (a *APIByService) PublicKey(c *gin.Context) {
+	if a.signer == nil {
+		c.Error(apierror.NotFound("prices are not signed"))
+		return
+	}
+	c.JSON(http.StatusOK, a.signer.PublicKey())
+}
+
+// latestPricesFilter returns nil when the query selects all prices.
+func latestPricesFilter(c *gin.Context) (*pricingbyservice.LatestPricesFilter, bool) {
+	filter := pricingbyservice.LatestPricesFilter{
+		Countries: queryList(c, "countries"),
+		NodeTypes: queryList(c, "node_types"),
+	}
+	for _, country := range filter.Countries {
+		if err := pricingbyservice.ISO3166CountryCode(country).Validate(); err != nil {
+			c.Error(apierror.BadRequest(err.Error(), errCodeInvalidQuery))
+			return nil, false
+		}
+	}
+	for _, nodeType := range filter.NodeTypes {
+		if nodeType != pricingbyservice.NodeTypeResidential && nodeType != pricingbyservice.NodeTypeOther {
+			c.Error(apierror.BadRequest("node_types should be residential or other", errCodeInvalidQuery))
+			return nil, false
+		}
+	}
+	for _, serviceType := range queryList(c, "service_types") {
+		if err := pricingbyservice.ServiceType(serviceType).Validate(); err != nil {
+			c.Error(apierror.BadRequest(err.Error(), errCodeInvalidQuery))
+			return nil, false
+		}
+		filter.ServiceTypes = append(filter.ServiceTypes, pricingbyservice.ServiceType(serviceType))
+	}
+	if v := c.Query("compact"); v != "" {
+		compact, err := strconv.ParseBool(v)
+		if err != nil {
+			c.Error(apierror.BadRequest("compact should be a boolean", errCodeInvalidQuery))
+			return nil, false
+		}
+		filter.Compact = compact
+	}
+
+	if len(filter.Countries) == 0 && len(filter.NodeTypes) == 0 && len(filter.ServiceTypes) == 0 && !filter.Compact {
+		return nil, true
+	}
+	return &filter, true
+}
+
+// queryList splits comma separated query values, e.g. ?countries=US,DE&countries=LT.
+func queryList(c *gin.Context, key string) []string {
+	var res []string
+	for _, value := range c.QueryArray(key) {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				res = append(res, v)
+			}
+		}
+	}
+	return res
+}
+
+func priceETag(blob []byte) string {
+	sum := sha256.Sum256(blob)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches tells if any of the If-None-Match tags matches, weak ones included.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// PriceStream streams the latest prices
+// @Summary Price stream
+// @Description Server-sent events of the latest prices. A prices event is sent on connect and each time the prices change.
+// @Description The event holds the prices along with their signature and key id when the prices are signed.
+// @Produce text/event-stream
+// @Success 200 {object} pricesig.PriceEvent
+// @Router /prices/stream [get]
+// @Tags prices
+func (a *APIByService) PriceStream(c *gin.Context) {
+	updates, stop := a.stream.Listen()
+	defer stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// disables response buffering of nginx
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !a.writePriceEvent(c, a.pricer.GetPrices()) {
+		return
+	}
+
+	keepAlive := time.NewTicker(priceStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case lp, ok := <-updates:
+			if !ok || !a.writePriceEvent(c, lp) {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func (a *APIByService) writePriceEvent(c *gin.Context, lp pricingbyservice.LatestPrices) bool {
+	prices, err := json.Marshal(lp)
+	if err != nil {
+		log.Err(err).Msg("Failed to marshal streamed prices")
+		return false
+	}
+	event := pricesig.PriceEvent{Prices: prices}
+	if a.signer != nil {
+		event.Signature = a.signer.Sign(prices)
+		event.KeyID = a.signer.PublicKey().KeyID
+	}
+	blob, err := json.Marshal(event)
+	if err != nil {
+		log.Err(err).Msg("Failed to marshal streamed prices")
+		return false
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: prices\ndata: %s\n\n", blob); err != nil {
+		return false
+	}
+	c.Writer.Flush()
+	return true
+}
+
+// PriceHistory returns the history of prices
+// @Summary Price history
+// @Description Prices of a country over time. Countries without prices of their own return the defaults.
+// @Description The range is capped to 24 hours without a token and to 31 days with one.
+// @Param country query string false "Country code, defaults are returned when empty"
+// @Param node_type query string false "Node type: residential or other. All when empty."
+// @Param service_type query string false "Service type. All when empty."
+// @Param from query string false "Start of the range in RFC3339, defaults to 24 hours before to"
+// @Param to query string false "End of the range in RFC3339, defaults to now"
+// @Param step query string false "Downsampling step, e.g. 1h. Keeps the last price of each step."
+// @Product json
+// @Success 200 {array} pricingbyservice.PriceHistoryPoint
+// @Router /prices/history [get]
+// @Tags prices
+func (a *APIByService) PriceHistory(c *gin.Context) {
+	q := pricingbyservice.PriceHistoryQuery{
+		Country:     c.Query("country"),
+		NodeType:    c.Query("node_type"),
+		ServiceType: pricingbyservice.ServiceType(c.Query("service_type")),
+	}
+	if q.Country != "" {
+		if err := pricingbyservice.ISO3166CountryCode(q.Country).Validate(); err != nil {
+			c.Error(apierror.BadRequest(err.Error(), errCodeInvalidQuery))
+			return
+		}
+	}
+	switch q.NodeType {
+	case "", pricingbyservice.NodeTypeResidential, pricingbyservice.NodeTypeOther:
+	default:
+		c.Error(apierror.BadRequest("node_type should be residential or other", errCodeInvalidQuery))
+		return
+	}
+	if q.ServiceType != "" {
+		if err := q.ServiceType.Validate(); err != nil {
+			c.Error(apierror.BadRequest(err.Error(), errCodeInvalidQuery))
+			return
+		}
+	}
+
+	to := time.Now().UTC()
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.Error(apierror.BadRequest("to should be in RFC3339 format", errCodeInvalidQuery))
+			return
+		}
+		to = t
+	}
+	from := to.Add(-24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.Error(apierror.BadRequest("from should be in RFC3339 format", errCodeInvalidQuery))
+			return
+		}
+		from = t
+	}
+	if from.After(to) || to.Sub(from) > maxPriceHistoryRange {
+		c.Error(apierror.BadRequest("from should be before to and the range should not exceed 31 days", errCodeInvalidQuery))
+		return
+	}
+	if middleware.JWTSubject(c) == "" && to.Sub(from) > maxPublicPriceHistoryRange {
+		c.Error(apierror.BadRequest("the range should not exceed 24 hours without a token", errCodeInvalidQuery))
+		return
+	}
+	if v := c.Query("step"); v != "" {
+		step, err := time.ParseDuration(v)
+		if err != nil || step <= 0 {
+			c.Error(apierror.BadRequest("step should be a positive duration", errCodeInvalidQuery))
+			return
+		}
+		q.Step = step
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	points, err := a.history.Series(ctx, from, to, q)
+	if err != nil {
+		log.Err(err).Msg("Failed to load price history")
+		c.Error(apierror.Internal(err.Error(), errCodeNoHistory))
+		return
+	}
+
+	c.JSON(http.StatusOK, points)
+}
+
+// PriceAt returns the prices valid at the given time
+// @Summary Prices at a point in time
+// @Description Prices of a country which were valid at the given time. The payload is hashed and, when a signing key is configured, the response is signed with the X-Price-Signature header so it can be verified later.
+// @Param time query string true "Point in time in RFC3339"
+// @Param country query string false "Country code, defaults are returned when empty"
+// @Product json
+// @Success 200 {object} SignedResponse
+// @Router /prices/at [get]
+// @Tags prices
+func (a *APIByService) PriceAt(c *gin.Context) {
+	tm, err := time.Parse(time.RFC3339, c.Query("time"))
+	if err != nil {
+		c.Error(apierror.BadRequest("time should be in RFC3339 format", errCodeInvalidQuery))
+		return
+	}
+	if tm.After(time.Now()) {
+		c.Error(apierror.BadRequest("time should not be in the future", errCodeInvalidQuery))
+		return
+	}
+
+	country := c.Query("country")
+	if country != "" {
+		if err := pricingbyservice.ISO3166CountryCode(country).Validate(); err != nil {
+			c.Error(apierror.BadRequest(err.Error(), errCodeInvalidQuery))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	current, previous, err := a.history.At(ctx, tm)
+	if err != nil {
+		log.Err(err).Msg("Failed to load price history")
+		c.Error(apierror.Internal(err.Error(), errCodeNoHistory))
+		return
+	}
+	if current == nil {
+		c.Error(apierror.NotFound("no prices retained for the given time"))
+		return
+	}
+
+	res, err := newSignedResponse(pricingbyservice.NewPriceAt(tm, country, *current, previous))
+	if err != nil {
+		log.Err(err).Msg("Failed to marshal prices")
+		c.Error(apierror.Internal(err.Error(), errCodeMarshalJson))
+		return
+	}
+	blob, err := json.Marshal(res)
+	if err != nil {
+		log.Err(err).Msg("Failed to marshal prices")
+		c.Error(apierror.Internal(err.Error(), errCodeMarshalJson))
+		return
+	}
+
+	a.sign(c, blob)
 	c.Data(http.StatusOK, gin.MIMEJSON, blob)
 }
 
@@ -98,8 +492,14 @@ func (a *APIByService) UpdateConfig(c *gin.Context) {
 		c.Error(apierror.BadRequest(err.Error(), errCodeParsingJson))
 		return
 	}
+	if !a.currencyTracked(c, cfg) {
+		return
+	}
 
-	err := a.cfger.Update(cfg)
+	err := a.cfger.Update(cfg, pricingbyservice.ConfigChange{
+		Author: middleware.JWTSubject(c),
+		Source: pricingbyservice.ConfigSourceAPI,
+	})
 	if err != nil {
 		log.Err(err).Msg("Failed to update config")
 		c.Error(apierror.BadRequest(err.Error(), errCodeUpdateConfig))
@@ -109,6 +509,376 @@ func (a *APIByService) UpdateConfig(c *gin.Context) {
 	c.Data(http.StatusAccepted, gin.MIMEJSON, nil)
 }
 
+// PreviewConfig previews the prices a pricing config would produce
+// @Summary Preview price config
+// @Description Generates the prices of a candidate config without storing it and lists how they differ from the current prices.
+// @Description The MYST rates default to the ones of the latest prices. Demand boost is only applied when demand indexes are given.
+// @Param config body pricingbyservice.PreviewRequest true "Candidate config"
+// @Product json
+// @Success 200 {object} pricingbyservice.PricePreview
+// @Router /prices/config/preview [post]
+// @Tags prices
+func (a *APIByService) PreviewConfig(c *gin.Context) {
+	var req pricingbyservice.PreviewRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.Error(apierror.BadRequest(err.Error(), errCodeParsingJson))
+		return
+	}
+	if err := req.Config.Validate(); err != nil {
+		c.Error(apierror.BadRequest(err.Error(), errCodeUpdateConfig))
+		return
+	}
+	if req.MystUSD < 0 {
+		c.Error(apierror.BadRequest("myst_usd should be positive", errCodeInvalidQuery))
+		return
+	}
+
+	if _, ok := req.Rates()[req.Config.Currency()]; !ok {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+		current, _, err := a.history.At(ctx, time.Now())
+		if err != nil {
+			log.Err(err).Msg("Failed to load price history")
+			c.Error(apierror.Internal(err.Error(), errCodeNoHistory))
+			return
+		}
+		if current == nil {
+			c.Error(apierror.BadRequest("no recent MYST rates, myst_rates is required", errCodeNoHistory))
+			return
+		}
+		rates := make(map[string]float64, len(current.MystRates)+len(req.MystRates))
+		for currency, rate := range current.MystRates {
+			rates[currency] = rate
+		}
+		for currency, rate := range req.MystRates {
+			rates[currency] = rate
+		}
+		if req.MystUSD == 0 && current.MystUSD > 0 {
+			req.MystUSD = current.MystUSD
+		}
+		req.MystRates = rates
+	}
+
+	preview, err := pricingbyservice.PreviewPrices(req, a.pricer.GetPrices())
+	if err != nil {
+		c.Error(apierror.BadRequest(err.Error(), errCodeNoHistory))
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// Campaigns lists the pricing campaigns
+// @Summary Price campaigns
+// @Description Pricing campaigns of the price config. Ended campaigns are kept until removed.
+// @Param active query boolean false "Only return the campaigns running now"
+// @Product json
+// @Success 200 {array} pricingbyservice.Campaign
+// @Router /prices/campaigns [get]
+// @Tags prices
+func (a *APIByService) Campaigns(c *gin.Context) {
+	cfg, err := a.cfger.Get()
+	if err != nil {
+		c.Error(apierror.Internal(err.Error(), errCodeNoConfig))
+		return
+	}
+
+	activeOnly, _ := strconv.ParseBool(c.Query("active"))
+	now := time.Now()
+	res := make([]pricingbyservice.Campaign, 0, len(cfg.Campaigns))
+	for _, campaign := range cfg.Campaigns {
+		if activeOnly && !campaign.Active(now) {
+			continue
+		}
+		res = append(res, campaign)
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// UpsertCampaign creates or replaces a pricing campaign
+// @Summary Create or replace price campaign
+// @Description Stores the campaign in the price config. Prices pick it up on the next price update.
+// @Param id path string true "Campaign id"
+// @Param campaign body pricingbyservice.Campaign true "Campaign"
+// @Success 202
+// @Router /prices/campaigns/{id} [put]
+// @Tags prices
+func (a *APIByService) UpsertCampaign(c *gin.Context) {
+	var campaign pricingbyservice.Campaign
+	if err := c.BindJSON(&campaign); err != nil {
+		c.Error(apierror.BadRequest(err.Error(), errCodeParsingJson))
+		return
+	}
+	campaign.ID = c.Param("id")
+
+	err := a.campaigns.UpsertCampaign(campaign, pricingbyservice.ConfigChange{
+		Author: middleware.JWTSubject(c),
+		Source: pricingbyservice.ConfigSourceAPI,
+	})
+	if err != nil {
+		log.Err(err).Msg("Failed to store campaign")
+		c.Error(apierror.BadRequest(err.Error(), errCodeUpdateConfig))
+		return
+	}
+
+	c.Data(http.StatusAccepted, gin.MIMEJSON, nil)
+}
+
+// RemoveCampaign removes a pricing campaign
+// @Summary Remove price campaign
+// @Description Removes the campaign from the price config. Prices pick it up on the next price update.
+// @Param id path string true "Campaign id"
+// @Success 202
+// @Router /prices/campaigns/{id} [delete]
+// @Tags prices
+func (a *APIByService) RemoveCampaign(c *gin.Context) {
+	err := a.campaigns.RemoveCampaign(c.Param("id"), pricingbyservice.ConfigChange{
+		Author: middleware.JWTSubject(c),
+		Source: pricingbyservice.ConfigSourceAPI,
+	})
+	if errors.Is(err, pricingbyservice.ErrCampaignNotFound) {
+		c.Error(apierror.NotFound(err.Error()))
+		return
+	}
+	if err != nil {
+		log.Err(err).Msg("Failed to remove campaign")
+		c.Error(apierror.BadRequest(err.Error(), errCodeUpdateConfig))
+		return
+	}
+
+	c.Data(http.StatusAccepted, gin.MIMEJSON, nil)
+}
+
+// ConfigVersions lists the versions of the pricing config
+// @Summary Price config versions
+// @Description Latest versions of the price config, newest first, without the full config
+// @Param limit query integer false "Number of versions to return, 500 at most"
+// @Product json
+// @Success 200 {array} pricingbyservice.ConfigVersion
+// @Router /prices/config/versions [get]
+// @Tags prices
+func (a *APIByService) ConfigVersions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	versions, err := a.versions.Versions(limit)
+	if err != nil {
+		log.Err(err).Msg("Failed to list config versions")
+		c.Error(apierror.Internal(err.Error(), errCodeConfigVersions))
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// ConfigVersion returns a version of the pricing config
+// @Summary Price config version
+// @Description Price config version with its full config
+// @Param version path integer true "Config version"
+// @Product json
+// @Success 200 {object} pricingbyservice.ConfigVersion
+// @Router /prices/config/versions/{version} [get]
+// @Tags prices
+func (a *APIByService) ConfigVersion(c *gin.Context) {
+	version, ok := a.versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	v, ok := a.configVersion(c, version)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// ConfigDiff returns the difference between two versions of the pricing config
+// @Summary Price config diff
+// @Description Values changed between two versions of the price config
+// @Param from query integer true "Config version to diff from"
+// @Param to query integer true "Config version to diff to"
+// @Product json
+// @Success 200 {array} pricingbyservice.ConfigDiff
+// @Router /prices/config/diff [get]
+// @Tags prices
+func (a *APIByService) ConfigDiff(c *gin.Context) {
+	fromVersion, ok := a.versionParam(c, c.Query("from"))
+	if !ok {
+		return
+	}
+	toVersion, ok := a.versionParam(c, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	from, ok := a.configVersion(c, fromVersion)
+	if !ok {
+		return
+	}
+	to, ok := a.configVersion(c, toVersion)
+	if !ok {
+		return
+	}
+	if from.Config == nil || to.Config == nil {
+		c.Error(apierror.Internal("config version has no config", errCodeConfigVersions))
+		return
+	}
+
+	diff, err := pricingbyservice.DiffConfigs(*from.Config, *to.Config)
+	if err != nil {
+		c.Error(apierror.Internal(err.Error(), errCodeConfigVersions))
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RollbackConfig rolls the pricing config back to a version
+// @Summary Roll back price config
+// @Description Sets the config of the given version as the current one. The rollback is recorded as a new version.
+// @Param version path integer true "Config version"
+// @Success 202
+// @Router /prices/config/versions/{version}/rollback [post]
+// @Tags prices
+func (a *APIByService) RollbackConfig(c *gin.Context) {
+	version, ok := a.versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+	v, ok := a.configVersion(c, version)
+	if !ok {
+		return
+	}
+	if v.Config != nil && !a.currencyTracked(c, *v.Config) {
+		return
+	}
+
+	err := a.versions.Rollback(version, middleware.JWTSubject(c))
+	if errors.Is(err, pricingbyservice.ErrConfigVersionNotFound) {
+		c.Error(apierror.NotFound(err.Error()))
+		return
+	}
+	if err != nil {
+		log.Err(err).Msg("Failed to roll back config")
+		c.Error(apierror.BadRequest(err.Error(), errCodeUpdateConfig))
+		return
+	}
+
+	c.Data(http.StatusAccepted, gin.MIMEJSON, nil)
+}
+
+// currencyTracked checks the market of the sidecar tracks the base currency of
+// the config, judging by the MYST rates of the latest price snapshot. Prices
+// would stop updating without a rate of the base currency.
+func (a *APIByService) currencyTracked(c *gin.Context, cfg pricingbyservice.Config) bool {
+	currency := cfg.Currency()
+	if currency == pricingbyservice.DefaultBaseCurrency {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	current, _, err := a.history.At(ctx, time.Now())
+	if err != nil {
+		log.Err(err).Msg("Failed to load price history")
+		c.Error(apierror.Internal(err.Error(), errCodeNoHistory))
+		return false
+	}
+	if current == nil || current.MystRates[currency] <= 0 {
+		c.Error(apierror.BadRequest(fmt.Sprintf("no MYST/%s rate, the base currency should be tracked by the MARKET_CURRENCIES of the sidecar", currency), errCodeUpdateConfig))
+		return false
+	}
+	return true
+}
+
+func (a *APIByService) versionParam(c *gin.Context, value string) (int64, bool) {
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version <= 0 {
+		c.Error(apierror.BadRequest("version should be a positive integer", errCodeInvalidQuery))
+		return 0, false
+	}
+	return version, true
+}
+
+func (a *APIByService) configVersion(c *gin.Context, version int64) (pricingbyservice.ConfigVersion, bool) {
+	v, err := a.versions.Version(version)
+	if errors.Is(err, pricingbyservice.ErrConfigVersionNotFound) {
+		c.Error(apierror.NotFound(fmt.Sprintf("config version %d not found", version)))
+		return v, false
+	}
+	if err != nil {
+		log.Err(err).Msg("Failed to get config version")
+		c.Error(apierror.Internal(err.Error(), errCodeConfigVersions))
+		return v, false
+	}
+	return v, true
+}
+
+// DemandIndexes is the state of the demand boost.
+type DemandIndexes struct {
+	Current *pricingbyservice.DemandIndexSnapshot  `json:"current"`
+	History []pricingbyservice.DemandIndexSnapshot `json:"history"`
+	Boost   *pricingbyservice.DemandBoost          `json:"boost"`
+}
+
+// DemandIndexes returns the demand indexes and the demand boost applied
+// @Summary Demand indexes
+// @Description Last fetched demand indexes, their history and the demand boost multipliers applied per country and service by the last price update.
+// @Param from query string false "Start of the history in RFC3339, defaults to 7 days before to"
+// @Param to query string false "End of the history in RFC3339, defaults to now"
+// @Product json
+// @Success 200 {object} DemandIndexes
+// @Router /prices/demand-indexes [get]
+// @Tags prices
+func (a *APIByService) DemandIndexes(c *gin.Context) {
+	to := time.Now().UTC()
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.Error(apierror.BadRequest("to should be in RFC3339 format", errCodeInvalidQuery))
+			return
+		}
+		to = t
+	}
+	from := to.Add(-7 * 24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.Error(apierror.BadRequest("from should be in RFC3339 format", errCodeInvalidQuery))
+			return
+		}
+		from = t
+	}
+	if from.After(to) || to.Sub(from) > maxPriceHistoryRange {
+		c.Error(apierror.BadRequest("from should be before to and the range should not exceed 31 days", errCodeInvalidQuery))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	current, err := a.demand.Latest(ctx)
+	if err != nil {
+		log.Err(err).Msg("Failed to load demand indexes")
+		c.Error(apierror.Internal(err.Error(), errCodeNoHistory))
+		return
+	}
+	history, err := a.demand.Range(ctx, from, to)
+	if err != nil {
+		log.Err(err).Msg("Failed to load demand index history")
+		c.Error(apierror.Internal(err.Error(), errCodeNoHistory))
+		return
+	}
+	boost, err := a.demand.Boost(ctx)
+	if err != nil {
+		log.Err(err).Msg("Failed to load demand boost")
+		c.Error(apierror.Internal(err.Error(), errCodeNoHistory))
+		return
+	}
+
+	c.JSON(http.StatusOK, DemandIndexes{Current: current, History: history, Boost: boost})
+}
+
 // Status godoc.
 // @Summary Status
 // @Description Status
@@ -125,10 +895,10 @@ func (a *APIByService) Status(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
 	defer cancel()
 
-	err := a.redis.Ping(ctx).Err()
+	err := a.store.Ping(ctx)
 	if err != nil {
 		sr.CacheOK = false
-		log.Err(err).Msg("could not reach redis")
+		log.Err(err).Msg("could not reach store")
 		c.Error(apierror.Internal(err.Error(), errRedisPingFailed))
 		return
 	}
@@ -156,10 +926,32 @@ type PingResponse struct {
 	Message string `json:"message"`
 }
 
+// optionalJWTAuthorized authorizes the token of the requests which have one.
+func (a *APIByService) optionalJWTAuthorized(c *gin.Context) {
+	if c.GetHeader("Authorization") == "" {
+		c.Next()
+		return
+	}
+	a.ac.JWTAuthorized()(c)
+}
+
 func (a *APIByService) RegisterRoutes(r gin.IRoutes) {
 	r.GET("/prices/config", a.ac.JWTAuthorized(), a.GetConfig)
 	r.POST("/prices/config", a.ac.JWTAuthorized(), a.UpdateConfig)
+	r.POST("/prices/config/preview", a.ac.JWTAuthorized(), a.PreviewConfig)
+	r.GET("/prices/config/versions", a.ac.JWTAuthorized(), a.ConfigVersions)
+	r.GET("/prices/config/versions/:version", a.ac.JWTAuthorized(), a.ConfigVersion)
+	r.POST("/prices/config/versions/:version/rollback", a.ac.JWTAuthorized(), a.RollbackConfig)
+	r.GET("/prices/config/diff", a.ac.JWTAuthorized(), a.ConfigDiff)
+	r.GET("/prices/campaigns", a.ac.JWTAuthorized(), a.Campaigns)
+	r.PUT("/prices/campaigns/:id", a.ac.JWTAuthorized(), a.UpsertCampaign)
+	r.DELETE("/prices/campaigns/:id", a.ac.JWTAuthorized(), a.RemoveCampaign)
 	r.GET("/prices", a.LatestPrices)
+	r.GET("/prices/stream", a.PriceStream)
+	r.GET("/prices/public-key", a.PublicKey)
+	r.GET("/prices/history", a.optionalJWTAuthorized, a.PriceHistory)
+	r.GET("/prices/at", a.PriceAt)
+	r.GET("/prices/demand-indexes", a.DemandIndexes)
 	r.GET("/ping", a.Ping)
 	r.GET("/status", a.Status)
 }
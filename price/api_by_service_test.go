@@ -1,16 +1,25 @@
 package price
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"math"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/mysteriumnetwork/discovery/middleware"
+	"github.com/mysteriumnetwork/discovery/price/pricesig"
 	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	"github.com/mysteriumnetwork/discovery/price/store"
 )
 
 type staticLatestPricer struct {
@@ -29,8 +38,8 @@ func TestLatestPricesReturnsErrorWhenJSONMarshalFails(t *testing.T) {
 			prices: pricingbyservice.LatestPrices{
 				Defaults: &pricingbyservice.PriceHistory{
 					Current: &pricingbyservice.PriceByType{
-						Residential: &pricingbyservice.PriceByServiceType{
-							Wireguard: pricingbyservice.Price{
+						Residential: pricingbyservice.PriceByServiceType{
+							pricingbyservice.ServiceTypeWireguard: pricingbyservice.Price{
 								PricePerHourHumanReadable: math.NaN(),
 							},
 						},
@@ -56,3 +65,435 @@ func TestLatestPricesReturnsErrorWhenJSONMarshalFails(t *testing.T) {
 		t.Fatalf("response body = %q, want error code %q", resp.Body.String(), errCodeMarshalJson)
 	}
 }
+
+func TestLatestPricesFiltersAndReturnsNotModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	prices := func(perGiB int64) *pricingbyservice.PriceHistory {
+		byType := &pricingbyservice.PriceByType{
+			Residential: pricingbyservice.PriceByServiceType{pricingbyservice.ServiceTypeWireguard: pricingbyservice.Price{PricePerGiB: big.NewInt(perGiB)}},
+			Other:       pricingbyservice.PriceByServiceType{pricingbyservice.ServiceTypeWireguard: pricingbyservice.Price{PricePerGiB: big.NewInt(perGiB)}},
+		}
+		return &pricingbyservice.PriceHistory{Current: byType, Previous: byType}
+	}
+	lp := pricingbyservice.LatestPrices{
+		Defaults:   prices(10),
+		PerCountry: map[string]*pricingbyservice.PriceHistory{"DE": prices(20), "US": prices(10)},
+	}
+	api := &APIByService{pricer: staticLatestPricer{prices: lp.WithCurrentTime()}}
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler)
+	router.GET("/api/v4/prices", api.LatestPrices)
+
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v4/prices"+query, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := get("?countries=DE,US&node_types=residential&compact=true", "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.Code, http.StatusOK, resp.Body.String())
+	}
+	var res pricingbyservice.LatestPrices
+	if err := json.Unmarshal(resp.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.PerCountry) != 1 || res.PerCountry["DE"] == nil {
+		t.Fatalf("countries = %v, want DE only", res.PerCountry)
+	}
+	if res.PerCountry["DE"].Current.Other != nil {
+		t.Fatal("other node prices should be filtered out")
+	}
+
+	// the server time changes on every request but the etag does not
+	api.pricer = staticLatestPricer{prices: lp.WithCurrentTime()}
+	etag := resp.Header().Get("ETag")
+	if resp := get("?countries=DE,US&node_types=residential&compact=true", "W/"+etag); resp.Code != http.StatusNotModified || resp.Body.Len() != 0 {
+		t.Fatalf("status = %d, want %d", resp.Code, http.StatusNotModified)
+	}
+	if resp := get("", etag); resp.Code != http.StatusOK || resp.Header().Get("ETag") == etag {
+		t.Fatalf("unfiltered prices should have another etag")
+	}
+
+	if resp := get("?node_types=business", ""); resp.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", resp.Code, http.StatusBadRequest)
+	}
+}
+
+func TestLatestPricesAreSigned(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validUntil := time.Now().Add(5 * time.Minute)
+	api := &APIByService{
+		pricer: staticLatestPricer{prices: pricingbyservice.LatestPrices{CurrentValidUntil: validUntil}},
+		signer: pricesig.NewSigner(key),
+	}
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler)
+	router.GET("/api/v4/prices", api.LatestPrices)
+	router.GET("/api/v4/prices/public-key", api.PublicKey)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v4/prices/public-key", nil))
+	var pk pricesig.PublicKey
+	if err := json.Unmarshal(resp.Body.Bytes(), &pk); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := pricesig.ParsePublicKey(pk.PublicKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v4/prices", nil))
+	if resp.Header().Get(pricesig.KeyIDHeader) != pk.KeyID {
+		t.Fatalf("key id = %q, want %q", resp.Header().Get(pricesig.KeyIDHeader), pk.KeyID)
+	}
+	verified, err := pricesig.NewVerifier(pub, 0).VerifyLatestPrices(resp.Body.Bytes(), resp.Header().Get(pricesig.SignatureHeader))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var lp pricingbyservice.LatestPrices
+	if err := json.Unmarshal(verified, &lp); err != nil {
+		t.Fatal(err)
+	}
+	if !lp.CurrentValidUntil.Equal(validUntil) {
+		t.Fatalf("valid until %s, want %s", lp.CurrentValidUntil, validUntil)
+	}
+
+	api.signer = nil
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v4/prices/public-key", nil))
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d without a signing key", resp.Code, http.StatusNotFound)
+	}
+}
+
+type channelPriceStream struct {
+	updates chan pricingbyservice.LatestPrices
+}
+
+func (s channelPriceStream) Listen() (<-chan pricingbyservice.LatestPrices, func()) {
+	return s.updates, func() {}
+}
+
+func TestPriceStreamSendsPricesOnConnectAndOnUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validUntil := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	updates := make(chan pricingbyservice.LatestPrices, 1)
+	updates <- pricingbyservice.LatestPrices{CurrentValidUntil: validUntil.Add(5 * time.Minute)}
+	// the stream ends once the updates are closed
+	close(updates)
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &APIByService{
+		pricer: staticLatestPricer{prices: pricingbyservice.LatestPrices{CurrentValidUntil: validUntil}},
+		stream: channelPriceStream{updates: updates},
+		signer: pricesig.NewSigner(key),
+	}
+	verifier := pricesig.NewVerifier(pub, 0)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler)
+	router.GET("/api/v4/prices/stream", api.PriceStream)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v4/prices/stream", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.Code, http.StatusOK)
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q, want text/event-stream", ct)
+	}
+
+	events := strings.Split(strings.TrimSpace(resp.Body.String()), "\n\n")
+	if len(events) != 2 {
+		t.Fatalf("events = %q, want the prices on connect and on update", events)
+	}
+	for i, want := range []time.Time{validUntil, validUntil.Add(5 * time.Minute)} {
+		lines := strings.SplitN(events[i], "\n", 2)
+		if lines[0] != "event: prices" || !strings.HasPrefix(lines[1], "data: ") {
+			t.Fatalf("unexpected event %q", events[i])
+		}
+		prices, err := verifier.VerifyPriceEvent([]byte(strings.TrimPrefix(lines[1], "data: ")))
+		if err != nil {
+			t.Fatalf("event %d not verified: %v", i, err)
+		}
+		var lp pricingbyservice.LatestPrices
+		if err := json.Unmarshal(prices, &lp); err != nil {
+			t.Fatal(err)
+		}
+		if !lp.CurrentValidUntil.Equal(want) {
+			t.Fatalf("event %d valid until %s, want %s", i, lp.CurrentValidUntil, want)
+		}
+	}
+}
+
+type staticPriceHistory struct {
+	current, previous *pricingbyservice.PriceSnapshot
+}
+
+func (s staticPriceHistory) Series(ctx context.Context, from, to time.Time, q pricingbyservice.PriceHistoryQuery) ([]pricingbyservice.PriceHistoryPoint, error) {
+	return []pricingbyservice.PriceHistoryPoint{}, nil
+}
+
+func (s staticPriceHistory) At(ctx context.Context, tm time.Time) (*pricingbyservice.PriceSnapshot, *pricingbyservice.PriceSnapshot, error) {
+	return s.current, s.previous, nil
+}
+
+func TestPriceAtReturnsSignedPrices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	price := func(perGiB int64) *pricingbyservice.PriceByType {
+		return &pricingbyservice.PriceByType{
+			Residential: pricingbyservice.PriceByServiceType{pricingbyservice.ServiceTypeWireguard: pricingbyservice.Price{PricePerGiB: big.NewInt(perGiB)}},
+			Other:       pricingbyservice.PriceByServiceType{pricingbyservice.ServiceTypeWireguard: pricingbyservice.Price{PricePerGiB: big.NewInt(perGiB)}},
+		}
+	}
+	validFrom := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &APIByService{
+		history: staticPriceHistory{
+			current: &pricingbyservice.PriceSnapshot{
+				Time:       validFrom,
+				Defaults:   price(10),
+				PerCountry: map[string]*pricingbyservice.PriceByType{"DE": price(20)},
+			},
+			previous: &pricingbyservice.PriceSnapshot{
+				Time:     validFrom.Add(-5 * time.Minute),
+				Defaults: price(10),
+			},
+		},
+		signer: pricesig.NewSigner(key),
+	}
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler)
+	router.GET("/api/v4/prices/at", api.PriceAt)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v4/prices/at?country=DE&time=2024-01-01T00:07:00Z", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.Code, http.StatusOK, resp.Body.String())
+	}
+
+	var res SignedResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256(res.Payload)
+	if res.Hash != hex.EncodeToString(hash[:]) {
+		t.Fatalf("hash = %v does not match the payload", res.Hash)
+	}
+	if err := pricesig.NewVerifier(pub, 0).Verify(resp.Body.Bytes(), resp.Header().Get(pricesig.SignatureHeader)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var priceAt pricingbyservice.PriceAt
+	if err := json.Unmarshal(res.Payload, &priceAt); err != nil {
+		t.Fatal(err)
+	}
+	if !priceAt.ValidFrom.Equal(validFrom) {
+		t.Fatalf("valid from = %v, want %v", priceAt.ValidFrom, validFrom)
+	}
+	if got := priceAt.Prices.Current.Residential[pricingbyservice.ServiceTypeWireguard].PricePerGiB.Int64(); got != 20 {
+		t.Fatalf("current price = %v, want 20", got)
+	}
+	if got := priceAt.Prices.Previous.Residential[pricingbyservice.ServiceTypeWireguard].PricePerGiB.Int64(); got != 10 {
+		t.Fatalf("previous price = %v, want 10", got)
+	}
+}
+
+func TestPriceAtReturnsNotFoundWithoutSnapshots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	api := &APIByService{history: staticPriceHistory{}}
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler)
+	router.GET("/api/v4/prices/at", api.PriceAt)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v4/prices/at?time=2024-01-01T00:07:00Z", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", resp.Code, http.StatusNotFound)
+	}
+}
+
+type staticDemandIndexes struct {
+	latest *pricingbyservice.DemandIndexSnapshot
+	boost  *pricingbyservice.DemandBoost
+}
+
+func (s staticDemandIndexes) Latest(ctx context.Context) (*pricingbyservice.DemandIndexSnapshot, error) {
+	return s.latest, nil
+}
+
+func (s staticDemandIndexes) Range(ctx context.Context, from, to time.Time) ([]pricingbyservice.DemandIndexSnapshot, error) {
+	if s.latest == nil || s.latest.Time.Before(from) || s.latest.Time.After(to) {
+		return nil, nil
+	}
+	return []pricingbyservice.DemandIndexSnapshot{*s.latest}, nil
+}
+
+func (s staticDemandIndexes) Boost(ctx context.Context) (*pricingbyservice.DemandBoost, error) {
+	return s.boost, nil
+}
+
+func TestDemandIndexesReturnsCurrentHistoryAndBoost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fetchedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	api := &APIByService{
+		demand: staticDemandIndexes{
+			latest: &pricingbyservice.DemandIndexSnapshot{Time: fetchedAt, DemandIndexes: map[pricingbyservice.ISO3166CountryCode]float64{"US": 0.3}},
+			boost: &pricingbyservice.DemandBoost{
+				Time:        fetchedAt,
+				Multipliers: map[pricingbyservice.ISO3166CountryCode]map[pricingbyservice.ServiceType]float64{"US": {pricingbyservice.ServiceTypeWireguard: 1.2}},
+			},
+		},
+	}
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler)
+	router.GET("/api/v4/prices/demand-indexes", api.DemandIndexes)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v4/prices/demand-indexes", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.Code, http.StatusOK, resp.Body.String())
+	}
+
+	var res DemandIndexes
+	if err := json.Unmarshal(resp.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Current == nil || res.Current.DemandIndexes["US"] != 0.3 {
+		t.Fatalf("current = %#v, want the US demand index", res.Current)
+	}
+	if len(res.History) != 1 {
+		t.Fatalf("history = %#v, want the fetched demand indexes", res.History)
+	}
+	if res.Boost == nil || res.Boost.Multipliers["US"][pricingbyservice.ServiceTypeWireguard] != 1.2 {
+		t.Fatalf("boost = %#v, want the US wireguard multiplier", res.Boost)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v4/prices/demand-indexes?from=bad", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", resp.Code, http.StatusBadRequest)
+	}
+}
+
+type subjectAuth string
+
+func (a subjectAuth) JWTAuthorized() func(*gin.Context) {
+	return func(c *gin.Context) {
+		c.Set(middleware.JWTSubjectKey, string(a))
+		c.Next()
+	}
+}
+
+func TestPriceHistoryCapsRangeWithoutToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	api := &APIByService{history: staticPriceHistory{}, ac: subjectAuth("admin")}
+	router := gin.New()
+	router.Use(middleware.ErrorHandler)
+	router.GET("/api/v4/prices/history", api.optionalJWTAuthorized, api.PriceHistory)
+
+	const week = "/api/v4/prices/history?from=2024-01-01T00:00:00Z&to=2024-01-08T00:00:00Z"
+	tests := []struct {
+		name  string
+		url   string
+		token bool
+		want  int
+	}{
+		{"day without token", "/api/v4/prices/history?from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z", false, http.StatusOK},
+		{"week without token", week, false, http.StatusBadRequest},
+		{"week with token", week, true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		if tt.token {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != tt.want {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, resp.Code, tt.want, resp.Body.String())
+		}
+	}
+}
+
+func TestUpdateConfigRejectsUntrackedBaseCurrency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	price := pricingbyservice.PriceUSD{PricePerHour: 1, PricePerGiB: 2}
+	prices := pricingbyservice.PriceByServiceTypeUSD{}
+	for _, serviceType := range (pricingbyservice.Config{}).RegisteredServiceTypes() {
+		prices[serviceType] = price
+	}
+	cfg := pricingbyservice.Config{
+		BaseCurrency: "EUR",
+		BasePrices:   pricingbyservice.PriceByTypeUSD{Residential: prices, Other: prices},
+	}
+	body, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		rates map[string]float64
+		want  int
+	}{
+		{"untracked", map[string]float64{"USD": 0.2}, http.StatusBadRequest},
+		{"tracked", map[string]float64{"USD": 0.2, "EUR": 0.18}, http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		api := &APIByService{
+			cfger:   pricingbyservice.NewConfigProviderDB(store.NewMemory()),
+			history: staticPriceHistory{current: &pricingbyservice.PriceSnapshot{MystRates: tt.rates}},
+		}
+		router := gin.New()
+		router.Use(middleware.ErrorHandler)
+		router.POST("/api/v4/prices/config", api.UpdateConfig)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v4/prices/config", strings.NewReader(string(body)))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != tt.want {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, resp.Code, tt.want, resp.Body.String())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package pricesig signs the price lists served by the pricer and verifies them
+// on the side of the nodes and consumers.
+//
+// The pricer signs the exact bytes of the /prices and /prices/at response bodies
+// with ed25519 and sends the base64 encoded signature in the SignatureHeader. The
+// public key is served by /prices/public-key and should be pinned by the clients
+// rather than fetched along with the prices.
+//
+// The /prices/stream events carry a PriceEvent with the signature of its prices
+// instead, as server-sent events have no headers of their own.
+//
+// The package only depends on the standard library so the clients can import it
+// without the dependencies of the pricer.
+package pricesig
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// SignatureHeader holds the signature of the response body.
+	SignatureHeader = "X-Price-Signature"
+	// KeyIDHeader holds the id of the key the response body is signed with.
+	KeyIDHeader = "X-Price-Key-Id"
+	// Algorithm is the signature algorithm.
+	Algorithm = "ed25519"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid price signature")
+	ErrExpired          = errors.New("prices expired")
+)
+
+// KeyID identifies a public key, e.g. to tell which one to verify with while keys are rotated.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParsePrivateKey parses a base64 encoded ed25519 seed of 32 bytes or private key of 64 bytes.
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	blob, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("private key should be base64 encoded: %w", err)
+	}
+	switch len(blob) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(blob), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(blob), nil
+	default:
+		return nil, fmt.Errorf("private key should be %d or %d bytes long, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(blob))
+	}
+}
+
+// ParsePublicKey parses a base64 encoded ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	blob, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("public key should be base64 encoded: %w", err)
+	}
+	if len(blob) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key should be %d bytes long, got %d", ed25519.PublicKeySize, len(blob))
+	}
+	return ed25519.PublicKey(blob), nil
+}
+
+// PublicKey is the public key as served by the pricer.
+type PublicKey struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+}
+
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// Sign returns the base64 encoded signature of the body.
+func (s *Signer) Sign(body []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, body))
+}
+
+func (s *Signer) PublicKey() PublicKey {
+	pub := s.key.Public().(ed25519.PublicKey)
+	return PublicKey{
+		Algorithm: Algorithm,
+		KeyID:     KeyID(pub),
+		PublicKey: base64.StdEncoding.EncodeToString(pub),
+	}
+}
+
+// PriceEvent is the data of a /prices/stream event. The signature covers the exact
+// bytes of the prices, the same as the body of a /prices response. The signature and
+// key id are empty when the pricer does not sign the prices.
+type PriceEvent struct {
+	Prices    json.RawMessage `json:"prices"`
+	Signature string          `json:"signature,omitempty"`
+	KeyID     string          `json:"key_id,omitempty"`
+}
+
+// Verifier verifies the signed price lists of the pricer.
+type Verifier struct {
+	key ed25519.PublicKey
+	// leeway tolerates clock skew and prices reloaded late by the pricer.
+	leeway time.Duration
+	now    func() time.Time
+}
+
+func NewVerifier(key ed25519.PublicKey, leeway time.Duration) *Verifier {
+	return &Verifier{
+		key:    key,
+		leeway: leeway,
+		now:    time.Now,
+	}
+}
+
+// Verify checks the base64 encoded signature of the body.
+func (v *Verifier) Verify(body []byte, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(v.key, body, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyLatestPrices checks the signature of a /prices response body and returns
+// the verified body to be decoded by the caller. Prices past their validity window
+// are rejected, so old signed prices can't be replayed.
+func (v *Verifier) VerifyLatestPrices(body []byte, signature string) ([]byte, error) {
+	if err := v.Verify(body, signature); err != nil {
+		return nil, err
+	}
+
+	var lp struct {
+		CurrentValidUntil time.Time `json:"current_valid_until"`
+	}
+	if err := json.Unmarshal(body, &lp); err != nil {
+		return nil, fmt.Errorf("could not decode prices: %w", err)
+	}
+	if v.now().After(lp.CurrentValidUntil.Add(v.leeway)) {
+		return nil, fmt.Errorf("%w at %s", ErrExpired, lp.CurrentValidUntil.UTC().Format(time.RFC3339))
+	}
+	return body, nil
+}
+
+// VerifyPriceEvent checks the signature of the prices of a /prices/stream event and
+// returns the verified prices to be decoded by the caller, the same as VerifyLatestPrices.
+func (v *Verifier) VerifyPriceEvent(data []byte) ([]byte, error) {
+	var event PriceEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("could not decode price event: %w", err)
+	}
+	if event.KeyID != "" && event.KeyID != KeyID(v.key) {
+		return nil, fmt.Errorf("%w: signed with key %s", ErrInvalidSignature, event.KeyID)
+	}
+	return v.VerifyLatestPrices(event.Prices, event.Signature)
+}
@@ -0,0 +1,124 @@
+package pricesig
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyLatestPrices(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	key, err := ParsePrivateKey(base64.StdEncoding.EncodeToString(seed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	signer := NewSigner(key)
+	pub, err := ParsePublicKey(signer.PublicKey().PublicKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	validUntil := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	body, err := json.Marshal(map[string]interface{}{"current_valid_until": validUntil, "defaults": map[string]interface{}{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := signer.Sign(body)
+
+	verifier := NewVerifier(pub, time.Minute)
+	verifier.now = func() time.Time { return validUntil.Add(30 * time.Second) }
+	verified, err := verifier.VerifyLatestPrices(body, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(verified) != string(body) {
+		t.Fatalf("verified body %s, want %s", verified, body)
+	}
+
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)-2] = ' '
+	if _, err := verifier.VerifyLatestPrices(tampered, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if _, err := verifier.VerifyLatestPrices(body, "not base64"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	verifier.now = func() time.Time { return validUntil.Add(2 * time.Minute) }
+	if _, err := verifier.VerifyLatestPrices(body, signature); !errors.Is(err, ErrExpired) {
+		t.Fatalf("error = %v, want %v", err, ErrExpired)
+	}
+}
+
+func TestVerifyPriceEvent(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewSigner(key)
+	pub := key.Public().(ed25519.PublicKey)
+
+	validUntil := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	prices, err := json.Marshal(map[string]interface{}{"current_valid_until": validUntil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(PriceEvent{Prices: prices, Signature: signer.Sign(prices), KeyID: signer.PublicKey().KeyID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := NewVerifier(pub, time.Minute)
+	verifier.now = func() time.Time { return validUntil }
+	verified, err := verifier.VerifyPriceEvent(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(verified) != string(prices) {
+		t.Fatalf("verified prices %s, want %s", verified, prices)
+	}
+
+	unsigned, err := json.Marshal(PriceEvent{Prices: prices})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.VerifyPriceEvent(unsigned); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewVerifier(otherPub, time.Minute)
+	other.now = verifier.now
+	if _, err := other.VerifyPriceEvent(data); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePrivateKey(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !parsed.Equal(key) {
+		t.Fatal("parsed private key differs")
+	}
+
+	for _, invalid := range []string{"", "not base64", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParsePrivateKey(invalid); err == nil {
+			t.Fatalf("expected an error for private key %q", invalid)
+		}
+		if _, err := ParsePublicKey(invalid); err == nil {
+			t.Fatalf("expected an error for public key %q", invalid)
+		}
+	}
+}
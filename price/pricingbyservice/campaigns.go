@@ -0,0 +1,166 @@
+package pricingbyservice
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+// Campaign is a temporary price change. While active, the multiplier is applied
+// to the prices of the selected countries, service and node types on top of
+// the country modifiers and demand boost. Empty selectors select everything and
+// campaigns without countries also apply to the default prices.
+type Campaign struct {
+	ID           string               `json:"id"`
+	Description  string               `json:"description,omitempty"`
+	Start        time.Time            `json:"start"`
+	End          time.Time            `json:"end"`
+	Countries    []ISO3166CountryCode `json:"countries,omitempty"`
+	ServiceTypes []ServiceType        `json:"service_types,omitempty"`
+	NodeTypes    []string             `json:"node_types,omitempty"`
+	Multiplier   float64              `json:"multiplier"`
+}
+
+func (c Campaign) Validate(serviceTypes ServiceTypes) error {
+	if c.ID == "" {
+		return errors.New("campaign id should not be empty")
+	}
+	if !c.End.After(c.Start) {
+		return errors.New("campaign should end after it starts")
+	}
+	if c.Multiplier <= 0 {
+		return errors.New("campaign multiplier should be higher than 0")
+	}
+	for _, country := range c.Countries {
+		if err := country.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, serviceType := range c.ServiceTypes {
+		if err := serviceTypes.validateRegistered(serviceType); err != nil {
+			return err
+		}
+	}
+	for _, nodeType := range c.NodeTypes {
+		if nodeType != NodeTypeResidential && nodeType != NodeTypeOther {
+			return fmt.Errorf("%v is an invalid node type", nodeType)
+		}
+	}
+	return nil
+}
+
+// Active tells if the campaign is running at the given time. The end is exclusive.
+func (c Campaign) Active(tm time.Time) bool {
+	return !tm.Before(c.Start) && tm.Before(c.End)
+}
+
+func (c Campaign) appliesTo(country ISO3166CountryCode, serviceType ServiceType, nodeType string) bool {
+	if len(c.Countries) > 0 {
+		found := false
+		for _, v := range c.Countries {
+			found = found || v == country
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(c.ServiceTypes) > 0 {
+		found := false
+		for _, v := range c.ServiceTypes {
+			found = found || v == serviceType
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(c.NodeTypes) > 0 {
+		found := false
+		for _, v := range c.NodeTypes {
+			found = found || v == nodeType
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// campaignModifier multiplies the multipliers of the campaigns active at tm which
+// apply to the country and service type. An empty country stands for the defaults.
+func campaignModifier(campaigns []Campaign, tm time.Time, country ISO3166CountryCode, serviceType ServiceType) Modifier {
+	mod := Modifier{Residential: 1, Other: 1}
+	for _, c := range campaigns {
+		if !c.Active(tm) {
+			continue
+		}
+		if country == "" && len(c.Countries) > 0 {
+			continue
+		}
+		if c.appliesTo(country, serviceType, NodeTypeResidential) {
+			mod.Residential *= c.Multiplier
+		}
+		if c.appliesTo(country, serviceType, NodeTypeOther) {
+			mod.Other *= c.Multiplier
+		}
+	}
+	return mod
+}
+
+func validateCampaigns(campaigns []Campaign, serviceTypes ServiceTypes) error {
+	ids := make(map[string]struct{}, len(campaigns))
+	for _, c := range campaigns {
+		if err := c.Validate(serviceTypes); err != nil {
+			return fmt.Errorf("campaign %q invalid: %w", c.ID, err)
+		}
+		if _, ok := ids[c.ID]; ok {
+			return fmt.Errorf("campaign %q is duplicated", c.ID)
+		}
+		ids[c.ID] = struct{}{}
+	}
+	return nil
+}
+
+// UpsertCampaign adds the campaign to the config or replaces the one with the same id.
+func (cpd *ConfigProviderDB) UpsertCampaign(campaign Campaign, change ConfigChange) error {
+	cpd.lock.Lock()
+	defer cpd.lock.Unlock()
+
+	return cpd.update(func(cfg Config) (Config, bool, error) {
+		campaigns := make([]Campaign, 0, len(cfg.Campaigns)+1)
+		replaced := false
+		for _, c := range cfg.Campaigns {
+			if c.ID == campaign.ID {
+				c = campaign
+				replaced = true
+			}
+			campaigns = append(campaigns, c)
+		}
+		if !replaced {
+			campaigns = append(campaigns, campaign)
+		}
+		cfg.Campaigns = campaigns
+		return cfg, true, nil
+	}, change, 0)
+}
+
+// RemoveCampaign removes the campaign with the given id from the config.
+func (cpd *ConfigProviderDB) RemoveCampaign(id string, change ConfigChange) error {
+	cpd.lock.Lock()
+	defer cpd.lock.Unlock()
+
+	return cpd.update(func(cfg Config) (Config, bool, error) {
+		campaigns := make([]Campaign, 0, len(cfg.Campaigns))
+		for _, c := range cfg.Campaigns {
+			if c.ID != id {
+				campaigns = append(campaigns, c)
+			}
+		}
+		if len(campaigns) == len(cfg.Campaigns) {
+			return cfg, false, ErrCampaignNotFound
+		}
+		cfg.Campaigns = campaigns
+		return cfg, true, nil
+	}, change, 0)
+}
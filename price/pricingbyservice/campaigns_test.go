@@ -0,0 +1,109 @@
+package pricingbyservice
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCampaignModifier(t *testing.T) {
+	start := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	campaigns := []Campaign{
+		{
+			ID:           "br-weekend",
+			Start:        start,
+			End:          start.Add(72 * time.Hour),
+			Countries:    []ISO3166CountryCode{"BR"},
+			ServiceTypes: []ServiceType{ServiceTypeScraping},
+			NodeTypes:    []string{NodeTypeResidential},
+			Multiplier:   0.7,
+		},
+		{
+			ID:         "global",
+			Start:      start,
+			End:        start.Add(24 * time.Hour),
+			Multiplier: 0.5,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		tm          time.Time
+		country     ISO3166CountryCode
+		serviceType ServiceType
+		want        Modifier
+	}{
+		{"both campaigns", start.Add(time.Hour), "BR", ServiceTypeScraping, Modifier{Residential: 0.35, Other: 0.5}},
+		{"global only", start.Add(time.Hour), "BR", ServiceTypeWireguard, Modifier{Residential: 0.5, Other: 0.5}},
+		{"defaults skip country campaigns", start.Add(time.Hour), "", ServiceTypeScraping, Modifier{Residential: 0.5, Other: 0.5}},
+		{"global ended", start.Add(48 * time.Hour), "BR", ServiceTypeScraping, Modifier{Residential: 0.7, Other: 1}},
+		{"not started", start.Add(-time.Second), "BR", ServiceTypeScraping, Modifier{Residential: 1, Other: 1}},
+		{"end is exclusive", start.Add(72 * time.Hour), "BR", ServiceTypeScraping, Modifier{Residential: 1, Other: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := campaignModifier(campaigns, tt.tm, tt.country, tt.serviceType)
+			if math.Abs(got.Residential-tt.want.Residential) > 1e-9 || math.Abs(got.Other-tt.want.Other) > 1e-9 {
+				t.Fatalf("modifier = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateNewPerCountryAppliesCampaignsOnTopOfModifiers(t *testing.T) {
+	start := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	cfg := testPreviewConfig()
+	cfg.Campaigns = []Campaign{{
+		ID:         "us-promo",
+		Start:      start,
+		End:        start.Add(time.Hour),
+		Countries:  []ISO3166CountryCode{"US"},
+		Multiplier: 0.5,
+	}}
+
+	p := &PriceUpdater{now: func() time.Time { return start }}
+	got := p.generateNewPerCountry(1, cfg)["US"].Current
+
+	if v := got.Residential[ServiceTypeWireguard].PricePerGiBHumanReadable; v != 2 {
+		t.Fatalf("US residential price = %v, want modifier 2 and campaign 0.5 applied", v)
+	}
+	if v := got.Other[ServiceTypeWireguard].PricePerGiBHumanReadable; v != 1 {
+		t.Fatalf("US other price = %v, want campaign 0.5 applied", v)
+	}
+
+	p.now = func() time.Time { return start.Add(time.Hour) }
+	got = p.generateNewPerCountry(1, cfg)["US"].Current
+	if v := got.Other[ServiceTypeWireguard].PricePerGiBHumanReadable; v != 2 {
+		t.Fatalf("US other price after the campaign = %v, want 2", v)
+	}
+}
+
+func TestConfigValidateRejectsInvalidCampaigns(t *testing.T) {
+	start := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	valid := Campaign{ID: "a", Start: start, End: start.Add(time.Hour), Multiplier: 1.2}
+
+	tests := []struct {
+		name      string
+		campaigns []Campaign
+	}{
+		{"duplicated id", []Campaign{valid, valid}},
+		{"ends before start", []Campaign{{ID: "a", Start: start, End: start, Multiplier: 1}}},
+		{"non positive multiplier", []Campaign{{ID: "a", Start: start, End: start.Add(time.Hour)}}},
+		{"invalid node type", []Campaign{{ID: "a", Start: start, End: start.Add(time.Hour), Multiplier: 1, NodeTypes: []string{"mobile"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testPreviewConfig()
+			cfg.Campaigns = tt.campaigns
+			if err := cfg.Validate(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	cfg := testPreviewConfig()
+	cfg.Campaigns = []Campaign{valid}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -5,28 +5,40 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
 	"sync"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
 )
 
 const PricingConfigRedisKey = "DISCOVERY_PRICE_BASE_CONFIG_BY_SERVICE"
 
 type ConfigProvider interface {
 	Get() (Config, error)
-	Update(Config) error
+	Update(Config, ConfigChange) error
+	// UpdateCountryModifiers sets the country modifiers of the demand boost multipliers
+	// onto the latest config, keeping the rest of it as is.
+	UpdateCountryModifiers(map[ISO3166CountryCode]float64, ConfigChange) error
 }
 
+// maxConfigUpdateAttempts caps the retries of a config update conflicting with
+// the writes of another instance.
+const maxConfigUpdateAttempts = 3
+
 type ConfigProviderDB struct {
-	db   redis.UniversalClient
+	db   store.Store
 	lock sync.Mutex
 }
 
-func NewConfigProviderDB(redis redis.UniversalClient) *ConfigProviderDB {
+func NewConfigProviderDB(db store.Store) *ConfigProviderDB {
 	return &ConfigProviderDB{
-		db: redis,
+		db: db,
 	}
 }
 
@@ -40,37 +52,113 @@ func (cpd *ConfigProviderDB) Get() (Config, error) {
 	return cfg, nil
 }
 
-func (cpd *ConfigProviderDB) Update(in Config) error {
+// Update stores the config and records the change as a new config version.
+// Changes which leave the config as is are not versioned.
+func (cpd *ConfigProviderDB) Update(in Config, change ConfigChange) error {
+	if err := in.Validate(); err != nil {
+		return err
+	}
+
 	cpd.lock.Lock()
 	defer cpd.lock.Unlock()
 
-	err := in.Validate()
-	if err != nil {
+	return cpd.update(func(Config) (Config, bool, error) { return in, true, nil }, change, 0)
+}
+
+func (cpd *ConfigProviderDB) UpdateCountryModifiers(multipliers map[ISO3166CountryCode]float64, change ConfigChange) error {
+	cpd.lock.Lock()
+	defer cpd.lock.Unlock()
+
+	return cpd.update(func(latest Config) (Config, bool, error) {
+		changed := updateCountryModifiers(&latest, multipliers)
+		return latest, changed, nil
+	}, change, 0)
+}
+
+// update applies the change to the latest config, which is left as is unless the
+// change reports it changed. The config is written only if no other version was
+// recorded meanwhile, otherwise the change is applied again to the new latest config.
+func (cpd *ConfigProviderDB) update(change func(latest Config) (Config, bool, error), meta ConfigChange, rollbackOf int64) error {
+	var err error
+	for attempt := 0; attempt < maxConfigUpdateAttempts; attempt++ {
+		err = cpd.tryUpdate(change, meta, rollbackOf)
+		if !errors.Is(err, store.ErrConflict) {
+			return err
+		}
+		log.Warn().Msg("config changed while updating it, retrying")
+	}
+	return err
+}
+
+func (cpd *ConfigProviderDB) tryUpdate(change func(latest Config) (Config, bool, error), meta ConfigChange, rollbackOf int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	seq, err := cpd.db.Get(ctx, PricingConfigVersionSeqRedisKey)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
 		return err
 	}
+	var latestVersion int64
+	if seq != "" {
+		latestVersion, err = strconv.ParseInt(seq, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid config version sequence %q: %w", seq, err)
+		}
+	}
 
+	old, err := cpd.fetchConfig()
+	if err != nil {
+		return err
+	}
+	in, changed, err := change(old)
+	if err != nil || !changed {
+		return err
+	}
+	if err := in.Validate(); err != nil {
+		return err
+	}
 	cfgJSON, err := json.Marshal(in)
 	if err != nil {
 		return err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
+	diff, err := DiffConfigs(old, in)
+	if err != nil {
+		return err
+	}
 
-	err = cpd.db.Set(ctx, PricingConfigRedisKey, string(cfgJSON), 0).Err()
+	b := &store.Batch{}
+	b.IfEqual(PricingConfigVersionSeqRedisKey, seq)
+	b.Set(PricingConfigRedisKey, string(cfgJSON))
+	if len(diff) == 0 {
+		return cpd.db.Apply(ctx, b)
+	}
+
+	version := latestVersion + 1
+	versionJSON, err := json.Marshal(ConfigVersion{
+		Version:    version,
+		Author:     meta.Author,
+		Source:     meta.Source,
+		CreatedAt:  time.Now().UTC(),
+		RollbackOf: rollbackOf,
+		Diff:       diff,
+		Config:     &in,
+	})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	b.Set(PricingConfigVersionSeqRedisKey, strconv.FormatInt(version, 10))
+	b.LPush(PricingConfigVersionsRedisKey, string(versionJSON), maxConfigVersions)
+	return cpd.db.Apply(ctx, b)
 }
 
 func (cpd *ConfigProviderDB) fetchConfig() (Config, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
-	val, err := cpd.db.Get(ctx, PricingConfigRedisKey).Result()
+	val, err := cpd.db.Get(ctx, PricingConfigRedisKey)
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			err = cpd.db.Set(ctx, PricingConfigRedisKey, defaultPriceConfig, 0).Err()
+		if errors.Is(err, store.ErrNotFound) {
+			err = cpd.db.Set(ctx, PricingConfigRedisKey, defaultPriceConfig)
 			if err != nil {
 				return Config{}, err
 			}
@@ -85,13 +173,59 @@ func (cpd *ConfigProviderDB) fetchConfig() (Config, error) {
 }
 
 type Config struct {
+	// ServiceTypes lists the priced service types. DefaultServiceTypes are priced when empty.
+	ServiceTypes ServiceTypes `json:"service_types,omitempty"`
+	// BaseCurrency is the fiat currency of the base prices and the absolute price overrides,
+	// DefaultBaseCurrency when empty.
+	BaseCurrency string `json:"base_currency,omitempty"`
+	// FiatCurrencies are the currencies the prices are published with fiat equivalents in.
+	FiatCurrencies   []string                        `json:"fiat_currencies,omitempty"`
 	BasePrices       PriceByTypeUSD                  `json:"base_prices"`
 	CountryModifiers map[ISO3166CountryCode]Modifier `json:"country_modifiers"`
 	DemandBoost      *DemandBoostConfig              `json:"demand_boost,omitempty"`
+	Campaigns        []Campaign                      `json:"campaigns,omitempty"`
+	Guardrails       *Guardrails                     `json:"guardrails,omitempty"`
+}
+
+// RegisteredServiceTypes returns the service types priced by the config.
+func (c Config) RegisteredServiceTypes() ServiceTypes {
+	if len(c.ServiceTypes) == 0 {
+		return DefaultServiceTypes
+	}
+	return c.ServiceTypes
+}
+
+// Currency returns the fiat currency the base prices are set in.
+func (c Config) Currency() string {
+	if c.BaseCurrency == "" {
+		return DefaultBaseCurrency
+	}
+	return c.BaseCurrency
+}
+
+func (c Config) maxPriceChange() float64 {
+	if c.Guardrails == nil {
+		return 0
+	}
+	return c.Guardrails.MaxPriceChange
 }
 
 func (c Config) Validate() error {
-	err := c.BasePrices.Validate()
+	if err := c.ServiceTypes.Validate(); err != nil {
+		return fmt.Errorf("service types invalid: %w", err)
+	}
+	serviceTypes := c.RegisteredServiceTypes()
+
+	if err := validateFiatCurrency(c.Currency()); err != nil {
+		return fmt.Errorf("base currency invalid: %w", err)
+	}
+	for _, currency := range c.FiatCurrencies {
+		if err := validateFiatCurrency(currency); err != nil {
+			return fmt.Errorf("fiat currencies invalid: %w", err)
+		}
+	}
+
+	err := c.BasePrices.Validate(serviceTypes)
 	if err != nil {
 		return fmt.Errorf("base price invalid: %w", err)
 	}
@@ -102,18 +236,28 @@ func (c Config) Validate() error {
 			return err
 		}
 
-		err = v.Validate()
+		err = v.Validate(serviceTypes)
 		if err != nil {
 			return fmt.Errorf("country %v contains invalid pricing: %w", k, err)
 		}
 	}
 
 	if c.DemandBoost != nil {
-		if err := c.DemandBoost.Validate(); err != nil {
+		if err := c.DemandBoost.Validate(serviceTypes); err != nil {
 			return fmt.Errorf("demand boost invalid: %w", err)
 		}
 	}
 
+	if err := validateCampaigns(c.Campaigns, serviceTypes); err != nil {
+		return err
+	}
+
+	if c.Guardrails != nil {
+		if err := c.Guardrails.Validate(); err != nil {
+			return fmt.Errorf("guardrails invalid: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -121,12 +265,12 @@ type DemandBoostConfig struct {
 	Countries map[ISO3166CountryCode]DemandBoostCountryCfg `json:"countries"`
 }
 
-func (d DemandBoostConfig) Validate() error {
+func (d DemandBoostConfig) Validate(serviceTypes ServiceTypes) error {
 	for country, cfg := range d.Countries {
 		if err := country.Validate(); err != nil {
 			return err
 		}
-		if err := cfg.Validate(); err != nil {
+		if err := cfg.Validate(serviceTypes); err != nil {
 			return fmt.Errorf("country %v contains invalid demand boost: %w", country, err)
 		}
 	}
@@ -134,21 +278,33 @@ func (d DemandBoostConfig) Validate() error {
 	return nil
 }
 
+// DemandBoostCountryCfg sets how the prices of a country are boosted on low demand.
+// With a curve, the bonus follows the demand/supply ratio of the country while
+// the supply is known. Otherwise, or without supply, the bonus grows as the
+// demand index falls below the target one.
 type DemandBoostCountryCfg struct {
 	TargetDemandIndex float64       `json:"target_demand_index"`
 	MaxBonus          float64       `json:"max_bonus"`
 	ServiceTypes      []ServiceType `json:"service_types,omitempty"`
+	Curve             *DemandCurve  `json:"curve,omitempty"`
 }
 
-func (d DemandBoostCountryCfg) Validate() error {
-	if d.TargetDemandIndex <= 0 {
+func (d DemandBoostCountryCfg) Validate(serviceTypes ServiceTypes) error {
+	if d.Curve != nil {
+		if err := d.Curve.Validate(); err != nil {
+			return err
+		}
+		if d.TargetDemandIndex < 0 {
+			return errors.New("target demand index should be non negative")
+		}
+	} else if d.TargetDemandIndex <= 0 {
 		return errors.New("target demand index should be higher than 0")
 	}
 	if d.MaxBonus < 0 {
 		return errors.New("max bonus should be non negative")
 	}
 	for _, serviceType := range d.ServiceTypes {
-		if err := serviceType.Validate(); err != nil {
+		if err := serviceTypes.validateRegistered(serviceType); err != nil {
 			return err
 		}
 	}
@@ -166,16 +322,8 @@ const (
 	ServiceTypeMonitoring   ServiceType = "monitoring"
 )
 
-var validServiceTypes = map[ServiceType]struct{}{
-	ServiceTypeWireguard:    {},
-	ServiceTypeScraping:     {},
-	ServiceTypeQUICScraping: {},
-	ServiceTypeDataTransfer: {},
-	ServiceTypeDVPN:         {},
-	ServiceTypeMonitoring:   {},
-}
-
-var allServiceTypes = []ServiceType{
+// DefaultServiceTypes are priced when the config does not register service types.
+var DefaultServiceTypes = ServiceTypes{
 	ServiceTypeWireguard,
 	ServiceTypeScraping,
 	ServiceTypeQUICScraping,
@@ -184,59 +332,92 @@ var allServiceTypes = []ServiceType{
 	ServiceTypeMonitoring,
 }
 
+var serviceTypeFormat = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Validate checks the format of the service type, it does not tell if the
+// service type is priced.
 func (s ServiceType) Validate() error {
-	if _, ok := validServiceTypes[s]; !ok {
+	if !serviceTypeFormat.MatchString(string(s)) {
 		return fmt.Errorf("%v is an invalid service type", s)
 	}
 	return nil
 }
 
+// ServiceTypes is a list of service types.
+type ServiceTypes []ServiceType
+
+func (s ServiceTypes) Validate() error {
+	seen := make(map[ServiceType]struct{}, len(s))
+	for _, serviceType := range s {
+		if err := serviceType.Validate(); err != nil {
+			return err
+		}
+		if _, ok := seen[serviceType]; ok {
+			return fmt.Errorf("service type %v is duplicated", serviceType)
+		}
+		seen[serviceType] = struct{}{}
+	}
+	return nil
+}
+
+func (s ServiceTypes) contains(serviceType ServiceType) bool {
+	for _, v := range s {
+		if v == serviceType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s ServiceTypes) validateRegistered(serviceType ServiceType) error {
+	if !s.contains(serviceType) {
+		return fmt.Errorf("%v is an invalid service type", serviceType)
+	}
+	return nil
+}
+
 type PriceByTypeUSD struct {
-	Residential *PriceByServiceTypeUSD `json:"residential"`
-	Other       *PriceByServiceTypeUSD `json:"other"`
+	Residential PriceByServiceTypeUSD `json:"residential"`
+	Other       PriceByServiceTypeUSD `json:"other"`
 }
 
-func (p PriceByTypeUSD) Validate() error {
+func (p PriceByTypeUSD) Validate(serviceTypes ServiceTypes) error {
 	if p.Residential == nil || p.Other == nil {
 		return errors.New("residential and other pricing should not be nil")
 	}
 
-	err := p.Residential.Validate()
+	err := p.Residential.Validate(serviceTypes)
 	if err != nil {
 		return err
 	}
 
-	return p.Other.Validate()
+	return p.Other.Validate(serviceTypes)
 }
 
-type PriceByServiceTypeUSD struct {
-	Wireguard    PriceUSD `json:"wireguard"`
-	Scraping     PriceUSD `json:"scraping"`
-	QUICScraping PriceUSD `json:"quic_scraping"`
-	DataTransfer PriceUSD `json:"data_transfer"`
-	DVPN         PriceUSD `json:"dvpn"`
-	Monitoring   PriceUSD `json:"monitoring"`
-}
+// PriceByServiceTypeUSD holds the prices of each service type.
+type PriceByServiceTypeUSD map[ServiceType]PriceUSD
 
-func (p PriceByServiceTypeUSD) Validate() error {
-	if err := p.Wireguard.Validate(); err != nil {
-		return err
-	}
-	if err := p.Scraping.Validate(); err != nil {
-		return err
-	}
-	if err := p.QUICScraping.Validate(); err != nil {
-		return err
-	}
-	if err := p.DVPN.Validate(); err != nil {
-		return err
+// Validate checks there is a valid price for each of the service types and no others.
+func (p PriceByServiceTypeUSD) Validate(serviceTypes ServiceTypes) error {
+	for _, serviceType := range serviceTypes {
+		price, ok := p[serviceType]
+		if !ok {
+			return fmt.Errorf("%v has no price", serviceType)
+		}
+		if err := price.Validate(); err != nil {
+			return err
+		}
 	}
-	if err := p.Monitoring.Validate(); err != nil {
-		return err
+	for serviceType := range p {
+		if err := serviceTypes.validateRegistered(serviceType); err != nil {
+			return err
+		}
 	}
-	return p.DataTransfer.Validate()
+	return nil
 }
 
+// PriceUSD is a price in the base currency of the config. The JSON fields keep
+// the usd suffix for compatibility with the configs set in USD only.
 type PriceUSD struct {
 	PricePerHour float64 `json:"price_per_hour_usd"`
 	PricePerGiB  float64 `json:"price_per_gib_usd"`
@@ -251,14 +432,104 @@ func (p PriceUSD) Validate() error {
 }
 
 type Modifier struct {
-	Residential float64 `json:"residential"`
-	Other       float64 `json:"other"`
+	Residential float64                         `json:"residential"`
+	Other       float64                         `json:"other"`
+	Services    map[ServiceType]ServiceModifier `json:"services,omitempty"`
 }
 
-func (m Modifier) Validate() error {
+// UnmarshalJSON defaults the node type multipliers missing from the JSON to 1, so
+// a modifier of only some services leaves the prices of the others as they are.
+func (m *Modifier) UnmarshalJSON(data []byte) error {
+	type modifier Modifier
+	res := modifier{Residential: 1, Other: 1}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*m = Modifier(res)
+	return nil
+}
+
+func (m Modifier) Validate(serviceTypes ServiceTypes) error {
 	if m.Residential < 0 || m.Other < 0 {
 		return errors.New("modifiers should be non negative")
 	}
+	for serviceType, sm := range m.Services {
+		if err := serviceTypes.validateRegistered(serviceType); err != nil {
+			return err
+		}
+		if err := sm.Validate(); err != nil {
+			return fmt.Errorf("service %v contains invalid modifier: %w", serviceType, err)
+		}
+	}
+	return nil
+}
+
+func (m Modifier) forNodeType(residential bool) float64 {
+	if residential {
+		return m.Residential
+	}
+	return m.Other
+}
+
+// close reports whether the multipliers of the modifiers are within
+// countryModifierTolerance of the other ones and their service modifiers are equal.
+func (m Modifier) close(other Modifier) bool {
+	return withinTolerance(m.Residential, other.Residential) &&
+		withinTolerance(m.Other, other.Other) &&
+		reflect.DeepEqual(m.Services, other.Services)
+}
+
+func withinTolerance(current, next float64) bool {
+	return math.Abs(next-current) <= countryModifierTolerance*math.Abs(current)
+}
+
+// ServiceModifier changes the prices of a single service in a country.
+type ServiceModifier struct {
+	Residential *PriceOverride `json:"residential,omitempty"`
+	Other       *PriceOverride `json:"other,omitempty"`
+}
+
+func (m ServiceModifier) Validate() error {
+	if err := m.Residential.Validate(); err != nil {
+		return fmt.Errorf("residential: %w", err)
+	}
+	if err := m.Other.Validate(); err != nil {
+		return fmt.Errorf("other: %w", err)
+	}
+	return nil
+}
+
+func (m ServiceModifier) forNodeType(residential bool) *PriceOverride {
+	if residential {
+		return m.Residential
+	}
+	return m.Other
+}
+
+// PriceOverride changes the price of a service. The multiplier applies on top
+// of the country modifier. Absolute prices replace the base price and are not
+// affected by the country modifier, demand boost or the multiplier, only by
+// campaigns. Like the base prices, they are in the base currency of the config
+// and their JSON fields keep the usd suffix for compatibility.
+type PriceOverride struct {
+	Multiplier   *float64 `json:"multiplier,omitempty"`
+	PricePerHour *float64 `json:"price_per_hour_usd,omitempty"`
+	PricePerGiB  *float64 `json:"price_per_gib_usd,omitempty"`
+}
+
+func (o *PriceOverride) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if o.Multiplier != nil && *o.Multiplier < 0 {
+		return errors.New("multiplier should be non negative")
+	}
+	if o.PricePerHour != nil && *o.PricePerHour <= 0 {
+		return errors.New("price per hour should be higher than 0")
+	}
+	if o.PricePerGiB != nil && *o.PricePerGiB <= 0 {
+		return errors.New("price per GiB should be higher than 0")
+	}
 	return nil
 }
 
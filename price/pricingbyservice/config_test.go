@@ -2,6 +2,8 @@ package pricingbyservice
 
 import (
 	_ "embed"
+	"encoding/json"
+	"math/big"
 	"testing"
 )
 
@@ -10,6 +12,7 @@ func TestConfig_Validate(t *testing.T) {
 		PricePerHour: 1,
 		PricePerGiB:  2,
 	}
+	zeroPrice := 0.0
 	type fields struct {
 		BasePrices       PriceByTypeUSD
 		CountryModifiers map[ISO3166CountryCode]Modifier
@@ -23,21 +26,21 @@ func TestConfig_Validate(t *testing.T) {
 			name: "accepts valid config",
 			fields: fields{
 				BasePrices: PriceByTypeUSD{
-					Residential: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
-					},
-					Other: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
+					Residential: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
+					},
+					Other: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
 					},
 				},
 				CountryModifiers: map[ISO3166CountryCode]Modifier{
@@ -53,21 +56,21 @@ func TestConfig_Validate(t *testing.T) {
 			name: "detects invalid country name",
 			fields: fields{
 				BasePrices: PriceByTypeUSD{
-					Residential: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
-					},
-					Other: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
+					Residential: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
+					},
+					Other: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
 					},
 				},
 				CountryModifiers: map[ISO3166CountryCode]Modifier{
@@ -83,21 +86,21 @@ func TestConfig_Validate(t *testing.T) {
 			name: "detects invalid country modifier",
 			fields: fields{
 				BasePrices: PriceByTypeUSD{
-					Residential: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
-					},
-					Other: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
+					Residential: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
+					},
+					Other: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
 					},
 				},
 				CountryModifiers: map[ISO3166CountryCode]Modifier{
@@ -108,28 +111,63 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "detects invalid service price override",
+			fields: fields{
+				BasePrices: PriceByTypeUSD{
+					Residential: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
+					},
+					Other: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
+					},
+				},
+				CountryModifiers: map[ISO3166CountryCode]Modifier{
+					"US": {
+						Residential: 1,
+						Other:       1,
+						Services: map[ServiceType]ServiceModifier{
+							ServiceTypeDataTransfer: {
+								Other: &PriceOverride{PricePerGiB: &zeroPrice},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "detects invalid pricing",
 			fields: fields{
 				BasePrices: PriceByTypeUSD{
-					Residential: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
-					},
-					Other: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: PriceUSD{
+					Residential: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
+					},
+					Other: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: PriceUSD{
 							PricePerHour: -1,
 							PricePerGiB:  2,
 						},
-						DVPN:       mprice,
-						Monitoring: mprice,
+						ServiceTypeDVPN:       mprice,
+						ServiceTypeMonitoring: mprice,
 					},
 				},
 				CountryModifiers: map[ISO3166CountryCode]Modifier{
@@ -145,20 +183,20 @@ func TestConfig_Validate(t *testing.T) {
 			name: "detects unset pricing",
 			fields: fields{
 				BasePrices: PriceByTypeUSD{
-					Residential: &PriceByServiceTypeUSD{
-						Wireguard:    mprice,
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
-					},
-					Other: &PriceByServiceTypeUSD{
-						Scraping:     mprice,
-						QUICScraping: mprice,
-						DataTransfer: mprice,
-						DVPN:         mprice,
-						Monitoring:   mprice,
+					Residential: PriceByServiceTypeUSD{
+						ServiceTypeWireguard:    mprice,
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
+					},
+					Other: PriceByServiceTypeUSD{
+						ServiceTypeScraping:     mprice,
+						ServiceTypeQUICScraping: mprice,
+						ServiceTypeDataTransfer: mprice,
+						ServiceTypeDVPN:         mprice,
+						ServiceTypeMonitoring:   mprice,
 					},
 				},
 				CountryModifiers: map[ISO3166CountryCode]Modifier{
@@ -242,10 +280,86 @@ func TestDemandBoostConfig_Validate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.cfg.Validate()
+			err := tt.cfg.Validate(DefaultServiceTypes)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DemandBoostConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestConfigRegisteredServiceTypes(t *testing.T) {
+	price := PriceUSD{PricePerHour: 1, PricePerGiB: 2}
+	cfg := Config{
+		ServiceTypes: ServiceTypes{ServiceTypeWireguard, "openvpn"},
+		BasePrices: PriceByTypeUSD{
+			Residential: PriceByServiceTypeUSD{ServiceTypeWireguard: price, "openvpn": price},
+			Other:       PriceByServiceTypeUSD{ServiceTypeWireguard: price, "openvpn": price},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ph := (&PriceUpdater{}).generateNewDefaults(1, cfg)
+	if got, ok := ph.Current.Other.ForServiceType("openvpn"); !ok || got.PricePerGiBHumanReadable != 2 {
+		t.Fatalf("openvpn price = %#v, want a price for the registered service type", got)
+	}
+	if _, ok := ph.Current.Other.ForServiceType(ServiceTypeDVPN); ok {
+		t.Fatal("expected no price for a service type which is not registered")
+	}
+
+	delete(cfg.BasePrices.Other, "openvpn")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a registered service type without a base price")
+	}
+
+	cfg.ServiceTypes = nil
+	cfg.BasePrices.Other["openvpn"] = price
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a base price of a service type which is not registered")
+	}
+}
+
+func TestPriceByServiceTypeJSON(t *testing.T) {
+	prices := PriceByType{
+		Residential: PriceByServiceType{ServiceTypeWireguard: {PricePerHour: big.NewInt(1), PricePerGiB: big.NewInt(2)}},
+	}
+
+	blob, err := json.Marshal(prices)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"residential":{"wireguard":{"price_per_hour":1,"price_per_hour_human_readable":0,"price_per_gib":2,"price_per_gib_human_readable":0}},"other":null}`
+	if string(blob) != want {
+		t.Fatalf("json = %s, want %s", blob, want)
+	}
+}
+
+func TestConfigValidatesCurrencies(t *testing.T) {
+	price := PriceUSD{PricePerHour: 1, PricePerGiB: 2}
+	cfg := Config{
+		ServiceTypes: ServiceTypes{ServiceTypeWireguard},
+		BasePrices: PriceByTypeUSD{
+			Residential: PriceByServiceTypeUSD{ServiceTypeWireguard: price},
+			Other:       PriceByServiceTypeUSD{ServiceTypeWireguard: price},
+		},
+		BaseCurrency:   "EUR",
+		FiatCurrencies: []string{"USD", "GBP"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.BaseCurrency = "BTC"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a base currency which is not fiat")
+	}
+
+	cfg.BaseCurrency = ""
+	cfg.FiatCurrencies = []string{"usd"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown fiat currency")
+	}
+}
@@ -0,0 +1,222 @@
+package pricingbyservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+const (
+	PricingConfigVersionsRedisKey   = "DISCOVERY_PRICE_CONFIG_VERSIONS_BY_SERVICE"
+	PricingConfigVersionSeqRedisKey = "DISCOVERY_PRICE_CONFIG_VERSION_SEQ_BY_SERVICE"
+
+	maxConfigVersions = 500
+)
+
+var ErrConfigVersionNotFound = errors.New("config version not found")
+
+// ConfigSource tells what changed the config.
+type ConfigSource string
+
+const (
+	ConfigSourceAPI         ConfigSource = "api"
+	ConfigSourceDemandBoost ConfigSource = "demand_boost"
+	ConfigSourceRollback    ConfigSource = "rollback"
+)
+
+// ConfigChange describes who and what changed the config.
+type ConfigChange struct {
+	Author string
+	Source ConfigSource
+}
+
+// ConfigVersion is a recorded change of the config.
+type ConfigVersion struct {
+	Version    int64        `json:"version"`
+	Author     string       `json:"author,omitempty"`
+	Source     ConfigSource `json:"source"`
+	CreatedAt  time.Time    `json:"created_at"`
+	RollbackOf int64        `json:"rollback_of,omitempty"`
+	Diff       []ConfigDiff `json:"diff"`
+	Config     *Config      `json:"config,omitempty"`
+}
+
+// ConfigDiff is a single changed value of the config. Path is the dot separated
+// path of the value in the JSON form of the config. From is absent when the
+// value was added and To is absent when it was removed.
+type ConfigDiff struct {
+	Path string          `json:"path"`
+	From json.RawMessage `json:"from,omitempty" swaggertype:"object"`
+	To   json.RawMessage `json:"to,omitempty" swaggertype:"object"`
+}
+
+// Versions returns up to limit latest config versions, newest first, without
+// the full config.
+func (cpd *ConfigProviderDB) Versions(limit int) ([]ConfigVersion, error) {
+	if limit <= 0 || limit > maxConfigVersions {
+		limit = maxConfigVersions
+	}
+
+	versions, err := cpd.fetchVersions(int64(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range versions {
+		versions[i].Config = nil
+	}
+	return versions, nil
+}
+
+// Version returns the given config version.
+func (cpd *ConfigProviderDB) Version(version int64) (ConfigVersion, error) {
+	versions, err := cpd.fetchVersions(maxConfigVersions)
+	if err != nil {
+		return ConfigVersion{}, err
+	}
+
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+
+	return ConfigVersion{}, ErrConfigVersionNotFound
+}
+
+// Rollback sets the config of the given version as the current one. The rollback
+// is recorded as a new version.
+func (cpd *ConfigProviderDB) Rollback(version int64, author string) error {
+	cpd.lock.Lock()
+	defer cpd.lock.Unlock()
+
+	v, err := cpd.Version(version)
+	if err != nil {
+		return err
+	}
+	if v.Config == nil {
+		return fmt.Errorf("config version %d has no config", version)
+	}
+
+	return cpd.update(func(Config) (Config, bool, error) { return *v.Config, true, nil }, ConfigChange{Author: author, Source: ConfigSourceRollback}, version)
+}
+
+func (cpd *ConfigProviderDB) fetchVersions(limit int64) ([]ConfigVersion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	members, err := cpd.db.LRange(ctx, PricingConfigVersionsRedisKey, 0, limit-1)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ConfigVersion, 0, len(members))
+	for _, m := range members {
+		var v ConfigVersion
+		if err := json.Unmarshal([]byte(m), &v); err != nil {
+			return nil, fmt.Errorf("malformed config version: %w", err)
+		}
+		res = append(res, v)
+	}
+
+	return res, nil
+}
+
+// DiffConfigs lists the values which differ between the JSON forms of the configs.
+func DiffConfigs(from, to Config) ([]ConfigDiff, error) {
+	fromTree, err := toJSONTree(from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := toJSONTree(to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ConfigDiff, 0)
+	return res, diffJSON("", fromTree, toTree, &res)
+}
+
+func toJSONTree(cfg Config) (interface{}, error) {
+	blob, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree interface{}
+	return tree, json.Unmarshal(blob, &tree)
+}
+
+func diffJSON(path string, from, to interface{}, res *[]ConfigDiff) error {
+	fromObj, fromIsObj := from.(map[string]interface{})
+	toObj, toIsObj := to.(map[string]interface{})
+	if fromIsObj && toIsObj {
+		keys := make(map[string]struct{}, len(fromObj)+len(toObj))
+		for k := range fromObj {
+			keys[k] = struct{}{}
+		}
+		for k := range toObj {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			fromValue, inFrom := fromObj[k]
+			toValue, inTo := toObj[k]
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+
+			switch {
+			case !inFrom:
+				if err := appendDiff(res, childPath, nil, toValue, false, true); err != nil {
+					return err
+				}
+			case !inTo:
+				if err := appendDiff(res, childPath, fromValue, nil, true, false); err != nil {
+					return err
+				}
+			default:
+				if err := diffJSON(childPath, fromValue, toValue, res); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+	return appendDiff(res, path, from, to, true, true)
+}
+
+func appendDiff(res *[]ConfigDiff, path string, from, to interface{}, hasFrom, hasTo bool) error {
+	diff := ConfigDiff{Path: path}
+	if hasFrom {
+		blob, err := json.Marshal(from)
+		if err != nil {
+			return err
+		}
+		diff.From = blob
+	}
+	if hasTo {
+		blob, err := json.Marshal(to)
+		if err != nil {
+			return err
+		}
+		diff.To = blob
+	}
+
+	*res = append(*res, diff)
+	return nil
+}
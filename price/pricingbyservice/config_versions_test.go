@@ -0,0 +1,121 @@
+package pricingbyservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	from := Config{
+		CountryModifiers: map[ISO3166CountryCode]Modifier{
+			"US": {Residential: 1, Other: 1},
+			"DE": {Residential: 2, Other: 2},
+		},
+	}
+	to := Config{
+		CountryModifiers: map[ISO3166CountryCode]Modifier{
+			"US": {Residential: 1.5, Other: 1},
+			"LT": {Residential: 1, Other: 1},
+		},
+	}
+
+	diff, err := DiffConfigs(from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		path, from, to string
+	}{
+		{"country_modifiers.DE", `{"other":2,"residential":2}`, ""},
+		{"country_modifiers.LT", "", `{"other":1,"residential":1}`},
+		{"country_modifiers.US.residential", "1", "1.5"},
+	}
+	if len(diff) != len(want) {
+		t.Fatalf("diff = %#v, want %d changes", diff, len(want))
+	}
+	for i, w := range want {
+		if diff[i].Path != w.path || string(diff[i].From) != w.from || string(diff[i].To) != w.to {
+			t.Fatalf("diff[%d] = {%s %s %s}, want %v", i, diff[i].Path, diff[i].From, diff[i].To, w)
+		}
+	}
+}
+
+func TestDiffConfigsWithoutChanges(t *testing.T) {
+	cfg := Config{CountryModifiers: map[ISO3166CountryCode]Modifier{"US": {Residential: 1, Other: 1}}}
+
+	diff, err := DiffConfigs(cfg, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diff) != 0 {
+		t.Fatalf("diff = %#v, want none", diff)
+	}
+}
+
+// editingStore changes the config through another provider right before the first batch is applied.
+type editingStore struct {
+	*store.Memory
+	edit func()
+}
+
+func (s *editingStore) Apply(ctx context.Context, b *store.Batch) error {
+	if s.edit != nil {
+		edit := s.edit
+		s.edit = nil
+		edit()
+	}
+	return s.Memory.Apply(ctx, b)
+}
+
+func TestUpdateCountryModifiersKeepsConcurrentEdits(t *testing.T) {
+	st := &editingStore{Memory: store.NewMemory()}
+	operator := NewConfigProviderDB(st)
+	booster := NewConfigProviderDB(st)
+	if err := operator.Update(testPreviewConfig(), ConfigChange{Author: "operator"}); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := testPreviewConfig()
+	edited.FiatCurrencies = []string{"EUR"}
+	st.edit = func() {
+		if err := operator.Update(edited, ConfigChange{Author: "operator"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := booster.UpdateCountryModifiers(map[ISO3166CountryCode]float64{"DE": 1.5}, ConfigChange{Source: ConfigSourceDemandBoost}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := operator.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.FiatCurrencies) != 1 || cfg.FiatCurrencies[0] != "EUR" {
+		t.Fatalf("expected the edit of the operator to be kept, got %v", cfg.FiatCurrencies)
+	}
+	if m := cfg.CountryModifiers["DE"]; m.Residential != 1.5 || m.Other != 1.5 {
+		t.Fatalf("expected the boosted DE modifier, got %+v", m)
+	}
+	if _, ok := cfg.CountryModifiers["US"]; ok {
+		t.Fatalf("expected the modifiers to be replaced by the boosted ones, got %v", cfg.CountryModifiers)
+	}
+
+	versions, err := operator.Versions(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	for i, v := range versions {
+		if want := int64(3 - i); v.Version != want {
+			t.Fatalf("versions[%d] = %d, want %d", i, v.Version, want)
+		}
+	}
+	if versions[0].Source != ConfigSourceDemandBoost {
+		t.Fatalf("expected the boost to be the latest version, got %q", versions[0].Source)
+	}
+}
@@ -0,0 +1,128 @@
+package pricingbyservice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+type DemandCurveType string
+
+const (
+	DemandCurveLinear  DemandCurveType = "linear"
+	DemandCurveSigmoid DemandCurveType = "sigmoid"
+	DemandCurveStep    DemandCurveType = "step"
+)
+
+// DemandCurve maps the demand/supply ratio of a country to the share of the max
+// bonus applied to its prices. A ratio of 1 means the country has the same share
+// of the demand as of the supply.
+type DemandCurve struct {
+	Type DemandCurveType `json:"type"`
+	// From and To is the ratio range over which the linear curve goes from no bonus to
+	// the max bonus. To below From boosts the prices as the ratio falls.
+	From float64 `json:"from,omitempty"`
+	To   float64 `json:"to,omitempty"`
+	// Midpoint is the ratio at which the sigmoid curve applies half of the max bonus
+	// and Steepness is how fast the bonus grows around it, negative to grow as the ratio falls.
+	Midpoint  float64 `json:"midpoint,omitempty"`
+	Steepness float64 `json:"steepness,omitempty"`
+	// Steps of the step curve. The bonus of the highest step reached by the ratio applies.
+	Steps []DemandStep `json:"steps,omitempty"`
+}
+
+// DemandStep applies the bonus share from the ratio up.
+type DemandStep struct {
+	Ratio float64 `json:"ratio"`
+	Bonus float64 `json:"bonus"`
+}
+
+func (c DemandCurve) Validate() error {
+	switch c.Type {
+	case DemandCurveLinear:
+		if c.From < 0 || c.To < 0 || c.From == c.To {
+			return errors.New("linear curve should span a range of non negative ratios")
+		}
+	case DemandCurveSigmoid:
+		if c.Midpoint <= 0 {
+			return errors.New("sigmoid curve midpoint should be higher than 0")
+		}
+		if c.Steepness == 0 {
+			return errors.New("sigmoid curve steepness should not be 0")
+		}
+	case DemandCurveStep:
+		if len(c.Steps) == 0 {
+			return errors.New("step curve should have steps")
+		}
+		for _, step := range c.Steps {
+			if step.Ratio < 0 {
+				return errors.New("step ratio should be non negative")
+			}
+			if step.Bonus < 0 || step.Bonus > 1 {
+				return errors.New("step bonus should be between 0 and 1")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown demand curve %q", c.Type)
+	}
+	return nil
+}
+
+// bonusShare returns the share of the max bonus, between 0 and 1, for the demand/supply ratio.
+func (c DemandCurve) bonusShare(ratio float64) float64 {
+	switch c.Type {
+	case DemandCurveLinear:
+		return math.Max(0, math.Min(1, (ratio-c.From)/(c.To-c.From)))
+	case DemandCurveSigmoid:
+		return 1 / (1 + math.Exp(-c.Steepness*(ratio-c.Midpoint)))
+	case DemandCurveStep:
+		steps := make([]DemandStep, len(c.Steps))
+		copy(steps, c.Steps)
+		sort.Slice(steps, func(i, j int) bool { return steps[i].Ratio < steps[j].Ratio })
+
+		share := 0.0
+		for _, step := range steps {
+			if ratio < step.Ratio {
+				break
+			}
+			share = step.Bonus
+		}
+		return share
+	}
+	return 0
+}
+
+// demandSupplyRatio compares the demand index of a country with its share of the supply.
+// Countries with demand and without supply get an infinite ratio.
+func demandSupplyRatio(demandIndex, supplyShare float64) float64 {
+	if demandIndex <= 0 {
+		return 0
+	}
+	if supplyShare <= 0 {
+		return math.Inf(1)
+	}
+	return demandIndex / supplyShare
+}
+
+// supplyShares turns the supply of each country into its share of the total supply.
+// It returns nil when there is no supply at all.
+func supplyShares(supply map[ISO3166CountryCode]float64) map[ISO3166CountryCode]float64 {
+	total := 0.0
+	for _, v := range supply {
+		if v > 0 {
+			total += v
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	shares := make(map[ISO3166CountryCode]float64, len(supply))
+	for country, v := range supply {
+		if v > 0 {
+			shares[country] = v / total
+		}
+	}
+	return shares
+}
@@ -0,0 +1,85 @@
+package pricingbyservice
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDemandCurveBonusShare(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve DemandCurve
+		ratio float64
+		want  float64
+	}{
+		{"linear below range", DemandCurve{Type: DemandCurveLinear, From: 1, To: 3}, 0.5, 0},
+		{"linear within range", DemandCurve{Type: DemandCurveLinear, From: 1, To: 3}, 2, 0.5},
+		{"linear above range", DemandCurve{Type: DemandCurveLinear, From: 1, To: 3}, 5, 1},
+		{"linear falling", DemandCurve{Type: DemandCurveLinear, From: 1, To: 0}, 0.25, 0.75},
+		{"sigmoid midpoint", DemandCurve{Type: DemandCurveSigmoid, Midpoint: 2, Steepness: 4}, 2, 0.5},
+		{"sigmoid no supply", DemandCurve{Type: DemandCurveSigmoid, Midpoint: 2, Steepness: 4}, math.Inf(1), 1},
+		{"step below first", DemandCurve{Type: DemandCurveStep, Steps: []DemandStep{{Ratio: 2, Bonus: 0.5}, {Ratio: 1, Bonus: 0.2}}}, 0.5, 0},
+		{"step highest reached", DemandCurve{Type: DemandCurveStep, Steps: []DemandStep{{Ratio: 2, Bonus: 0.5}, {Ratio: 1, Bonus: 0.2}}}, 1.5, 0.2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.curve.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tt.curve.bonusShare(tt.ratio); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("bonus share = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDemandCurveValidate(t *testing.T) {
+	invalid := []DemandCurve{
+		{Type: "cubic"},
+		{Type: DemandCurveLinear, From: 1, To: 1},
+		{Type: DemandCurveSigmoid, Midpoint: 1},
+		{Type: DemandCurveStep},
+		{Type: DemandCurveStep, Steps: []DemandStep{{Ratio: 1, Bonus: 2}}},
+	}
+	for _, curve := range invalid {
+		if err := curve.Validate(); err == nil {
+			t.Fatalf("expected an error for %#v", curve)
+		}
+	}
+}
+
+func TestDemandBoostMultipliersWithSupply(t *testing.T) {
+	curve := &DemandCurve{Type: DemandCurveLinear, From: 1, To: 2}
+	cfg := Config{
+		DemandBoost: &DemandBoostConfig{
+			Countries: map[ISO3166CountryCode]DemandBoostCountryCfg{
+				"US": {MaxBonus: 0.5, Curve: curve},
+				"DE": {MaxBonus: 0.5, Curve: curve},
+				"PL": {MaxBonus: 0.5, Curve: curve, TargetDemandIndex: 0.1},
+			},
+		},
+	}
+	if err := cfg.DemandBoost.Validate(DefaultServiceTypes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	demandIndexes := map[ISO3166CountryCode]float64{"US": 0.6, "DE": 0.2}
+	supply := map[ISO3166CountryCode]float64{"US": 40, "DE": 60}
+
+	got := DemandBoostMultipliers(cfg, demandIndexes, supply)
+	// US has 0.6 of the demand and 0.4 of the supply, DE 0.2 and 0.6 and PL neither.
+	want := map[ISO3166CountryCode]float64{"US": 1.25, "DE": 1, "PL": 1}
+	for country, wantMultiplier := range want {
+		if math.Abs(got[country]-wantMultiplier) > 1e-9 {
+			t.Fatalf("country %v multiplier = %v, want %v", country, got[country], wantMultiplier)
+		}
+	}
+
+	got = DemandBoostMultipliers(cfg, demandIndexes, nil)
+	// without supply only PL falls back to its target demand index
+	want = map[ISO3166CountryCode]float64{"US": 1, "DE": 1, "PL": 1.5}
+	for country, wantMultiplier := range want {
+		if math.Abs(got[country]-wantMultiplier) > 1e-9 {
+			t.Fatalf("country %v multiplier without supply = %v, want %v", country, got[country], wantMultiplier)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package pricingbyservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+const (
+	DemandIndexHistoryRedisKey = "DISCOVERY_DEMAND_INDEX_HISTORY"
+	DemandBoostRedisKey        = "DISCOVERY_DEMAND_BOOST"
+)
+
+// DemandIndexSnapshot is a demand index map fetched at a point in time.
+type DemandIndexSnapshot struct {
+	Time          time.Time                      `json:"time"`
+	DemandIndexes map[ISO3166CountryCode]float64 `json:"demand_indexes"`
+}
+
+// DemandBoost is the demand boost applied by the last price update. Supply is
+// empty when not known.
+type DemandBoost struct {
+	Time          time.Time                                      `json:"time"`
+	DemandIndexes map[ISO3166CountryCode]float64                 `json:"demand_indexes"`
+	Supply        map[ISO3166CountryCode]float64                 `json:"supply,omitempty"`
+	Multipliers   map[ISO3166CountryCode]map[ServiceType]float64 `json:"multipliers"`
+}
+
+// DemandIndexStorage keeps the fetched demand indexes in a redis sorted set scored
+// by the fetch time and the last applied demand boost under a key of its own.
+type DemandIndexStorage struct {
+	db        store.Store
+	retention time.Duration
+}
+
+// NewDemandIndexStorage creates a demand index storage. Snapshots older than
+// retention are dropped on each store, unless retention is 0.
+func NewDemandIndexStorage(db store.Store, retention time.Duration) *DemandIndexStorage {
+	return &DemandIndexStorage{
+		db:        db,
+		retention: retention,
+	}
+}
+
+func (dis *DemandIndexStorage) Store(ctx context.Context, snapshot DemandIndexSnapshot) error {
+	blob, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	err = dis.db.ZAdd(ctx, DemandIndexHistoryRedisKey, float64(snapshot.Time.Unix()), string(blob))
+	if err != nil {
+		return err
+	}
+
+	if dis.retention > 0 {
+		oldest := snapshot.Time.Add(-dis.retention).Unix()
+		err = dis.db.ZRemBelow(ctx, DemandIndexHistoryRedisKey, float64(oldest))
+		if err != nil {
+			return fmt.Errorf("could not trim demand index history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Latest returns the last fetched demand indexes, nil when there are none.
+func (dis *DemandIndexStorage) Latest(ctx context.Context) (*DemandIndexSnapshot, error) {
+	members, err := dis.db.ZRange(ctx, DemandIndexHistoryRedisKey, math.Inf(-1), math.Inf(1), true, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	var snapshot DemandIndexSnapshot
+	if err := json.Unmarshal([]byte(members[0]), &snapshot); err != nil {
+		return nil, fmt.Errorf("malformed demand index snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Range returns the demand indexes fetched between from and to, inclusive, ordered by time.
+func (dis *DemandIndexStorage) Range(ctx context.Context, from, to time.Time) ([]DemandIndexSnapshot, error) {
+	members, err := dis.db.ZRange(ctx, DemandIndexHistoryRedisKey, float64(from.Unix()), float64(to.Unix()), false, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]DemandIndexSnapshot, 0, len(members))
+	for _, m := range members {
+		var snapshot DemandIndexSnapshot
+		if err := json.Unmarshal([]byte(m), &snapshot); err != nil {
+			log.Warn().Err(err).Msg("skipping malformed demand index snapshot")
+			continue
+		}
+		res = append(res, snapshot)
+	}
+
+	return res, nil
+}
+
+func (dis *DemandIndexStorage) StoreBoost(ctx context.Context, boost DemandBoost) error {
+	blob, err := json.Marshal(boost)
+	if err != nil {
+		return err
+	}
+	return dis.db.Set(ctx, DemandBoostRedisKey, string(blob))
+}
+
+// Boost returns the last applied demand boost, nil when the demand boost was never applied.
+func (dis *DemandIndexStorage) Boost(ctx context.Context) (*DemandBoost, error) {
+	blob, err := dis.db.Get(ctx, DemandBoostRedisKey)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var boost DemandBoost
+	if err := json.Unmarshal([]byte(blob), &boost); err != nil {
+		return nil, fmt.Errorf("malformed demand boost: %w", err)
+	}
+	return &boost, nil
+}
@@ -0,0 +1,126 @@
+package pricingbyservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+// DemandIndexRedisKey is the default key the demand indexes are read from by the StoreDemandIndexProvider.
+const DemandIndexRedisKey = "DISCOVERY_COUNTRY_DEMAND_INDEXES"
+
+// StaticDemandIndexProvider reads the demand indexes from a JSON file mapping
+// country codes to demand indexes, e.g. {"US": 0.4, "DE": 0.2}. The file is
+// read on every call so it can be changed without a restart.
+type StaticDemandIndexProvider struct {
+	path string
+}
+
+func NewStaticDemandIndexProvider(path string) *StaticDemandIndexProvider {
+	return &StaticDemandIndexProvider{path: path}
+}
+
+func (p *StaticDemandIndexProvider) DemandIndexes(_ context.Context) (map[ISO3166CountryCode]float64, error) {
+	blob, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("read demand indexes file: %w", err)
+	}
+	return parseDemandIndexes(blob)
+}
+
+// StoreDemandIndexProvider reads the demand indexes from a key of the store, e.g.
+// of Redis, holding the same JSON as the StaticDemandIndexProvider file.
+type StoreDemandIndexProvider struct {
+	db  store.Store
+	key string
+}
+
+func NewStoreDemandIndexProvider(db store.Store, key string) *StoreDemandIndexProvider {
+	return &StoreDemandIndexProvider{db: db, key: key}
+}
+
+func (p *StoreDemandIndexProvider) DemandIndexes(ctx context.Context) (map[ISO3166CountryCode]float64, error) {
+	blob, err := p.db.Get(ctx, p.key)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("no demand indexes found in %q", p.key)
+		}
+		return nil, fmt.Errorf("read demand indexes from store: %w", err)
+	}
+	return parseDemandIndexes([]byte(blob))
+}
+
+// parseDemandIndexes skips the invalid countries and values just like the Prometheus provider does.
+func parseDemandIndexes(blob []byte) (map[ISO3166CountryCode]float64, error) {
+	var raw map[string]float64
+	if err := json.Unmarshal(blob, &raw); err != nil {
+		return nil, fmt.Errorf("decode demand indexes: %w", err)
+	}
+
+	demandIndexes := make(map[ISO3166CountryCode]float64, len(raw))
+	for k, v := range raw {
+		country := ISO3166CountryCode(k)
+		if country.Validate() != nil || math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			log.Warn().Msgf("skipping invalid demand index %v of %q", v, k)
+			continue
+		}
+		demandIndexes[country] = v
+	}
+	return demandIndexes, nil
+}
+
+// WeightedDemandIndexSource is a demand index provider of the WeightedDemandIndexProvider.
+type WeightedDemandIndexSource struct {
+	Name     string
+	Provider CountryDemandIndexProvider
+	Weight   float64
+}
+
+// WeightedDemandIndexProvider combines the demand indexes of several providers.
+// The demand index of a country is the weighted average of the providers
+// which have it. Failing providers are skipped unless all of them fail.
+type WeightedDemandIndexProvider struct {
+	sources []WeightedDemandIndexSource
+}
+
+func NewWeightedDemandIndexProvider(sources []WeightedDemandIndexSource) *WeightedDemandIndexProvider {
+	return &WeightedDemandIndexProvider{sources: sources}
+}
+
+func (p *WeightedDemandIndexProvider) DemandIndexes(ctx context.Context) (map[ISO3166CountryCode]float64, error) {
+	sums := make(map[ISO3166CountryCode]float64)
+	weights := make(map[ISO3166CountryCode]float64)
+	loaded := 0
+	for _, source := range p.sources {
+		if source.Weight <= 0 {
+			continue
+		}
+
+		demandIndexes, err := source.Provider.DemandIndexes(ctx)
+		if err != nil {
+			log.Err(err).Str("source", source.Name).Msg("could not load demand indexes")
+			continue
+		}
+		loaded++
+		for country, demandIndex := range demandIndexes {
+			sums[country] += demandIndex * source.Weight
+			weights[country] += source.Weight
+		}
+	}
+	if loaded == 0 {
+		return nil, errors.New("could not load demand indexes from any source")
+	}
+
+	demandIndexes := make(map[ISO3166CountryCode]float64, len(sums))
+	for country, sum := range sums {
+		demandIndexes[country] = sum / weights[country]
+	}
+	return demandIndexes, nil
+}
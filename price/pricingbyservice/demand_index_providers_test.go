@@ -0,0 +1,57 @@
+package pricingbyservice
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticDemandIndexProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demand_indexes.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"US": 0.4, "DE": 0.2, "invalid": 0.1, "FR": -1}`), 0o600))
+
+	got, err := NewStaticDemandIndexProvider(path).DemandIndexes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[ISO3166CountryCode]float64{"US": 0.4, "DE": 0.2}, got)
+
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+	_, err = NewStaticDemandIndexProvider(path).DemandIndexes(context.Background())
+	require.Error(t, err)
+}
+
+func TestWeightedDemandIndexProvider(t *testing.T) {
+	provider := NewWeightedDemandIndexProvider([]WeightedDemandIndexSource{
+		{Name: "a", Weight: 3, Provider: &stubCountryDemandIndexProvider{
+			demandIndexes: map[ISO3166CountryCode]float64{"US": 0.2, "DE": 0.4},
+		}},
+		{Name: "b", Weight: 1, Provider: &stubCountryDemandIndexProvider{
+			demandIndexes: map[ISO3166CountryCode]float64{"US": 0.6},
+		}},
+		{Name: "c", Weight: 1, Provider: failingDemandIndexProvider{}},
+	})
+
+	got, err := provider.DemandIndexes(context.Background())
+	require.NoError(t, err)
+	require.InDelta(t, 0.3, got["US"], 1e-9)
+	require.InDelta(t, 0.4, got["DE"], 1e-9)
+}
+
+func TestWeightedDemandIndexProviderFailsWithoutSources(t *testing.T) {
+	provider := NewWeightedDemandIndexProvider([]WeightedDemandIndexSource{
+		{Name: "a", Weight: 1, Provider: failingDemandIndexProvider{}},
+		{Name: "b", Weight: 0, Provider: &stubCountryDemandIndexProvider{}},
+	})
+
+	_, err := provider.DemandIndexes(context.Background())
+	require.Error(t, err)
+}
+
+type failingDemandIndexProvider struct{}
+
+func (failingDemandIndexProvider) DemandIndexes(context.Context) (map[ISO3166CountryCode]float64, error) {
+	return nil, errors.New("unavailable")
+}
@@ -0,0 +1,68 @@
+package pricingbyservice
+
+import (
+	"fmt"
+
+	"github.com/mysteriumnetwork/payments/v3/exchange"
+)
+
+// DefaultBaseCurrency is the currency of the base prices when the config sets none.
+const DefaultBaseCurrency = string(exchange.CurrencyUSD)
+
+// FiatPrice is the equivalent of a MYST price in a fiat currency at the rate
+// the price was generated with.
+type FiatPrice struct {
+	PricePerHour float64 `json:"price_per_hour" swaggertype:"number"`
+	PricePerGiB  float64 `json:"price_per_gib" swaggertype:"number"`
+}
+
+func validateFiatCurrency(currency string) error {
+	if !exchange.Currency(currency).IsFiat() {
+		return fmt.Errorf("%q is not a supported fiat currency", currency)
+	}
+	return nil
+}
+
+// withFiatEquivalents sets the fiat equivalents of the current prices of lp in the
+// given currencies. Currencies without a rate are skipped.
+func withFiatEquivalents(lp *LatestPrices, mystRates map[string]float64, currencies []string) {
+	if len(currencies) == 0 || !lp.isInitialized() {
+		return
+	}
+
+	rates := make(map[string]float64, len(currencies))
+	for _, currency := range currencies {
+		if rate, ok := mystRates[currency]; ok && rate > 0 {
+			rates[currency] = rate
+		}
+	}
+	if len(rates) == 0 {
+		return
+	}
+
+	setFiatPrices(lp.Defaults.Current, rates)
+	for _, ph := range lp.PerCountry {
+		if ph != nil {
+			setFiatPrices(ph.Current, rates)
+		}
+	}
+}
+
+func setFiatPrices(prices *PriceByType, rates map[string]float64) {
+	if prices == nil {
+		return
+	}
+
+	for _, byService := range []PriceByServiceType{prices.Residential, prices.Other} {
+		for serviceType, price := range byService {
+			price.Fiat = make(map[string]FiatPrice, len(rates))
+			for currency, rate := range rates {
+				price.Fiat[currency] = FiatPrice{
+					PricePerHour: price.PricePerHourHumanReadable * rate,
+					PricePerGiB:  price.PricePerGiBHumanReadable * rate,
+				}
+			}
+			byService[serviceType] = price
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package pricingbyservice
+
+// LatestPricesFilter selects a part of the LatestPrices. Empty selectors select everything.
+type LatestPricesFilter struct {
+	Countries    []string
+	NodeTypes    []string
+	ServiceTypes ServiceTypes
+	// Compact keeps only the country prices which differ from the defaults. Missing
+	// node and service types of a country fall back to the defaults, as do missing countries.
+	Compact bool
+}
+
+// Filter returns a copy of the prices selected by the filter. The defaults are always kept.
+func (lp LatestPrices) Filter(f LatestPricesFilter) LatestPrices {
+	res := lp
+	res.Defaults = f.priceHistory(lp.Defaults, nil)
+
+	countries := f.Countries
+	if len(countries) == 0 {
+		countries = make([]string, 0, len(lp.PerCountry))
+		for country := range lp.PerCountry {
+			countries = append(countries, country)
+		}
+	}
+
+	res.PerCountry = make(map[string]*PriceHistory, len(countries))
+	for _, country := range countries {
+		ph, ok := lp.PerCountry[country]
+		if !ok || ph == nil {
+			continue
+		}
+
+		var defaults *PriceHistory
+		if f.Compact {
+			defaults = lp.Defaults
+		}
+		if filtered := f.priceHistory(ph, defaults); filtered != nil {
+			res.PerCountry[country] = filtered
+		}
+	}
+	return res
+}
+
+// priceHistory filters the price history dropping the prices equal to the defaults unless they are nil.
+// It returns nil when nothing is left.
+func (f LatestPricesFilter) priceHistory(ph, defaults *PriceHistory) *PriceHistory {
+	if ph == nil {
+		return nil
+	}
+
+	var currentDefaults, previousDefaults *PriceByType
+	if defaults != nil {
+		currentDefaults, previousDefaults = defaults.Current, defaults.Previous
+	}
+	res := &PriceHistory{
+		Current:  f.priceByType(ph.Current, currentDefaults, defaults != nil),
+		Previous: f.priceByType(ph.Previous, previousDefaults, defaults != nil),
+	}
+	if defaults != nil && res.Current == nil && res.Previous == nil {
+		return nil
+	}
+	return res
+}
+
+func (f LatestPricesFilter) priceByType(p, defaults *PriceByType, compact bool) *PriceByType {
+	if p == nil {
+		return nil
+	}
+
+	res := &PriceByType{}
+	if f.selectsNodeType(NodeTypeResidential) {
+		res.Residential = f.priceByServiceType(p.Residential, defaults.ForNodeType(true), compact)
+	}
+	if f.selectsNodeType(NodeTypeOther) {
+		res.Other = f.priceByServiceType(p.Other, defaults.ForNodeType(false), compact)
+	}
+	if compact && len(res.Residential) == 0 && len(res.Other) == 0 {
+		return nil
+	}
+	return res
+}
+
+func (f LatestPricesFilter) priceByServiceType(p, defaults PriceByServiceType, compact bool) PriceByServiceType {
+	if p == nil {
+		return nil
+	}
+
+	res := make(PriceByServiceType, len(p))
+	for serviceType, price := range p {
+		if !f.selectsServiceType(serviceType) {
+			continue
+		}
+		if def, ok := defaults[serviceType]; compact && ok && price.equal(def) {
+			continue
+		}
+		res[serviceType] = price
+	}
+	if compact && len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+func (f LatestPricesFilter) selectsNodeType(nodeType string) bool {
+	if len(f.NodeTypes) == 0 {
+		return true
+	}
+	for _, nt := range f.NodeTypes {
+		if nt == nodeType {
+			return true
+		}
+	}
+	return false
+}
+
+func (f LatestPricesFilter) selectsServiceType(serviceType ServiceType) bool {
+	return len(f.ServiceTypes) == 0 || f.ServiceTypes.contains(serviceType)
+}
@@ -0,0 +1,62 @@
+package pricingbyservice
+
+import (
+	"math/big"
+	"testing"
+)
+
+func testFilterPrices(perGiB int64) *PriceByType {
+	prices := func() PriceByServiceType {
+		return PriceByServiceType{
+			ServiceTypeWireguard: {PricePerGiB: big.NewInt(perGiB), PricePerGiBHumanReadable: float64(perGiB)},
+			ServiceTypeDVPN:      {PricePerGiB: big.NewInt(perGiB), PricePerGiBHumanReadable: float64(perGiB)},
+		}
+	}
+	return &PriceByType{Residential: prices(), Other: prices()}
+}
+
+func TestLatestPricesFilter(t *testing.T) {
+	lp := LatestPrices{
+		Defaults: &PriceHistory{Current: testFilterPrices(1), Previous: testFilterPrices(1)},
+		PerCountry: map[string]*PriceHistory{
+			"US": {Current: testFilterPrices(2), Previous: testFilterPrices(1)},
+			"DE": {Current: testFilterPrices(1), Previous: testFilterPrices(1)},
+			"LT": {Current: testFilterPrices(3), Previous: testFilterPrices(3)},
+		},
+	}
+
+	filtered := lp.Filter(LatestPricesFilter{
+		Countries:    []string{"US", "DE", "GB"},
+		NodeTypes:    []string{NodeTypeResidential},
+		ServiceTypes: ServiceTypes{ServiceTypeWireguard},
+	})
+	if len(filtered.PerCountry) != 2 || filtered.PerCountry["US"] == nil || filtered.PerCountry["DE"] == nil {
+		t.Fatalf("countries = %v, want US and DE", filtered.PerCountry)
+	}
+	for _, ph := range []*PriceHistory{filtered.Defaults, filtered.PerCountry["US"]} {
+		if ph.Current.Other != nil || len(ph.Current.Residential) != 1 {
+			t.Fatalf("prices = %#v, want residential wireguard only", ph.Current)
+		}
+	}
+	if len(lp.PerCountry["US"].Current.Residential) != 2 || lp.PerCountry["US"].Current.Other == nil {
+		t.Fatal("filter should not change the filtered prices")
+	}
+
+	compact := lp.Filter(LatestPricesFilter{Compact: true})
+	if _, ok := compact.PerCountry["DE"]; ok {
+		t.Fatal("DE has the default prices and should be dropped")
+	}
+	if len(compact.PerCountry) != 2 {
+		t.Fatalf("countries = %v, want US and LT", compact.PerCountry)
+	}
+	us := compact.PerCountry["US"]
+	if us.Previous != nil {
+		t.Fatalf("US previous prices = %#v, want nil as equal to the defaults", us.Previous)
+	}
+	if got := us.Current.Residential[ServiceTypeDVPN].PricePerGiBHumanReadable; got != 2 {
+		t.Fatalf("US dvpn price = %v, want 2", got)
+	}
+	if compact.Defaults.Current.Residential[ServiceTypeDVPN].PricePerGiBHumanReadable != 1 {
+		t.Fatal("defaults should be kept")
+	}
+}
@@ -0,0 +1,191 @@
+package pricingbyservice
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/mysteriumnetwork/payments/v3/units"
+)
+
+const maxSmoothingSamples = 1000
+
+// Guardrails limit how fast the prices can change between price updates.
+type Guardrails struct {
+	MystSmoothing *MystSmoothing `json:"myst_smoothing,omitempty"`
+	// MaxPriceChange is the largest relative change of a single price per update,
+	// e.g. 0.1 allows prices to change by 10%. Disabled when 0.
+	MaxPriceChange float64 `json:"max_price_change,omitempty"`
+}
+
+func (g Guardrails) Validate() error {
+	if g.MaxPriceChange < 0 {
+		return errors.New("max price change should be non negative")
+	}
+	if g.MystSmoothing != nil {
+		if err := g.MystSmoothing.Validate(); err != nil {
+			return fmt.Errorf("myst smoothing invalid: %w", err)
+		}
+	}
+	return nil
+}
+
+type SmoothingMethod string
+
+const (
+	SmoothingEMA    SmoothingMethod = "ema"
+	SmoothingMedian SmoothingMethod = "median"
+)
+
+// MystSmoothing smooths the MYST/USD rate over the last samples taken at price updates.
+type MystSmoothing struct {
+	Method  SmoothingMethod `json:"method"`
+	Samples int             `json:"samples"`
+}
+
+func (s MystSmoothing) Validate() error {
+	if s.Method != SmoothingEMA && s.Method != SmoothingMedian {
+		return fmt.Errorf("%v is an invalid smoothing method", s.Method)
+	}
+	if s.Samples < 1 || s.Samples > maxSmoothingSamples {
+		return fmt.Errorf("samples should be between 1 and %d", maxSmoothingSamples)
+	}
+	return nil
+}
+
+// smooth returns the smoothed value of the samples, ordered from the oldest. EMA
+// uses the smoothing factor of 2/(N+1).
+func (s MystSmoothing) smooth(samples []float64) float64 {
+	if len(samples) > s.Samples {
+		samples = samples[len(samples)-s.Samples:]
+	}
+	if len(samples) == 0 {
+		return 0
+	}
+
+	switch s.Method {
+	case SmoothingMedian:
+		sorted := append([]float64(nil), samples...)
+		sort.Float64s(sorted)
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[mid-1] + sorted[mid]) / 2
+		}
+		return sorted[mid]
+	default:
+		alpha := 2 / float64(s.Samples+1)
+		ema := samples[0]
+		for _, v := range samples[1:] {
+			ema = alpha*v + (1-alpha)*ema
+		}
+		return ema
+	}
+}
+
+// PriceClamp is a price which changed more than allowed and was clamped.
+type PriceClamp struct {
+	Country     string      `json:"country,omitempty"`
+	NodeType    string      `json:"node_type"`
+	ServiceType ServiceType `json:"service_type"`
+	PriceType   string      `json:"price_type"`
+	Direction   string      `json:"direction"`
+}
+
+const (
+	priceTypePerHour = "per_hour"
+	priceTypePerGiB  = "per_gib"
+
+	clampIncrease = "increase"
+	clampDecrease = "decrease"
+)
+
+// clampPriceChanges limits the change of each current price of lp to maxChange
+// relative to its previous price. Prices without previous ones are left as is.
+func clampPriceChanges(lp *LatestPrices, maxChange float64) []PriceClamp {
+	if maxChange <= 0 {
+		return nil
+	}
+
+	var clamps []PriceClamp
+	if lp.Defaults != nil {
+		clamps = append(clamps, clampPriceHistory("", lp.Defaults, maxChange)...)
+	}
+
+	countries := make([]string, 0, len(lp.PerCountry))
+	for country := range lp.PerCountry {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	for _, country := range countries {
+		if ph := lp.PerCountry[country]; ph != nil {
+			clamps = append(clamps, clampPriceHistory(country, ph, maxChange)...)
+		}
+	}
+
+	return clamps
+}
+
+func clampPriceHistory(country string, ph *PriceHistory, maxChange float64) []PriceClamp {
+	if ph.Current == nil || ph.Previous == nil || ph.Current == ph.Previous {
+		return nil
+	}
+
+	var clamps []PriceClamp
+	for _, nodeType := range []string{NodeTypeResidential, NodeTypeOther} {
+		residential := nodeType == NodeTypeResidential
+		current := ph.Current.ForNodeType(residential)
+		previous := ph.Previous.ForNodeType(residential)
+
+		for _, serviceType := range current.ServiceTypes() {
+			prev, ok := previous[serviceType]
+			if !ok {
+				continue
+			}
+
+			price := current[serviceType]
+			clamp := PriceClamp{Country: country, NodeType: nodeType, ServiceType: serviceType}
+			if perHour, direction := clampPrice(prev.PricePerHour, price.PricePerHour, maxChange); direction != "" {
+				price.PricePerHour = perHour
+				price.PricePerHourHumanReadable = units.BigIntWeiToFloatEth(perHour)
+				clamp.PriceType, clamp.Direction = priceTypePerHour, direction
+				clamps = append(clamps, clamp)
+			}
+			if perGiB, direction := clampPrice(prev.PricePerGiB, price.PricePerGiB, maxChange); direction != "" {
+				price.PricePerGiB = perGiB
+				price.PricePerGiBHumanReadable = units.BigIntWeiToFloatEth(perGiB)
+				clamp.PriceType, clamp.Direction = priceTypePerGiB, direction
+				clamps = append(clamps, clamp)
+			}
+			current[serviceType] = price
+		}
+	}
+
+	return clamps
+}
+
+func clampPrice(previous, current *big.Int, maxChange float64) (*big.Int, string) {
+	if previous == nil || current == nil || previous.Sign() <= 0 {
+		return current, ""
+	}
+
+	upper := scaleBigInt(previous, 1+maxChange)
+	if current.Cmp(upper) > 0 {
+		return upper, clampIncrease
+	}
+
+	lower := big.NewInt(0)
+	if maxChange < 1 {
+		lower = scaleBigInt(previous, 1-maxChange)
+	}
+	if current.Cmp(lower) < 0 {
+		return lower, clampDecrease
+	}
+
+	return current, ""
+}
+
+func scaleBigInt(v *big.Int, factor float64) *big.Int {
+	res, _ := new(big.Float).Mul(new(big.Float).SetInt(v), big.NewFloat(factor)).Int(nil)
+	return res
+}
@@ -0,0 +1,76 @@
+package pricingbyservice
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestMystSmoothing(t *testing.T) {
+	samples := []float64{0.1, 0.2, 0.2, 0.3, 1}
+
+	median := MystSmoothing{Method: SmoothingMedian, Samples: 4}
+	if got := median.smooth(samples); got != 0.25 {
+		t.Fatalf("median = %v, want 0.25 of the last 4 samples", got)
+	}
+
+	ema := MystSmoothing{Method: SmoothingEMA, Samples: 3}
+	// alpha is 0.5 over 0.2, 0.3, 1
+	if got := ema.smooth(samples); math.Abs(got-0.625) > 1e-9 {
+		t.Fatalf("ema = %v, want 0.625", got)
+	}
+
+	if got := ema.smooth([]float64{0.4}); got != 0.4 {
+		t.Fatalf("ema of a single sample = %v, want 0.4", got)
+	}
+}
+
+func TestClampPriceChanges(t *testing.T) {
+	price := func(perHour, perGiB int64) Price {
+		return Price{PricePerHour: big.NewInt(perHour), PricePerGiB: big.NewInt(perGiB)}
+	}
+	previous := &PriceByType{
+		Residential: PriceByServiceType{ServiceTypeWireguard: price(100, 1000)},
+		Other:       PriceByServiceType{ServiceTypeWireguard: price(100, 1000)},
+	}
+	lp := LatestPrices{
+		Defaults: &PriceHistory{
+			Previous: previous,
+			Current: &PriceByType{
+				Residential: PriceByServiceType{ServiceTypeWireguard: price(150, 1050), ServiceTypeDVPN: price(1, 1)},
+				Other:       PriceByServiceType{ServiceTypeWireguard: price(50, 1000)},
+			},
+		},
+	}
+
+	clamps := clampPriceChanges(&lp, 0.1)
+
+	residential := lp.Defaults.Current.Residential[ServiceTypeWireguard]
+	if residential.PricePerHour.Int64() != 110 || residential.PricePerGiB.Int64() != 1050 {
+		t.Fatalf("residential price = %v/%v, want 110/1050", residential.PricePerHour, residential.PricePerGiB)
+	}
+	other := lp.Defaults.Current.Other[ServiceTypeWireguard]
+	if other.PricePerHour.Int64() != 90 {
+		t.Fatalf("other price per hour = %v, want 90", other.PricePerHour)
+	}
+	if dvpn := lp.Defaults.Current.Residential[ServiceTypeDVPN]; dvpn.PricePerHour.Int64() != 1 {
+		t.Fatalf("dvpn price = %v, want prices without previous ones left as is", dvpn.PricePerHour)
+	}
+
+	want := []PriceClamp{
+		{NodeType: NodeTypeResidential, ServiceType: ServiceTypeWireguard, PriceType: priceTypePerHour, Direction: clampIncrease},
+		{NodeType: NodeTypeOther, ServiceType: ServiceTypeWireguard, PriceType: priceTypePerHour, Direction: clampDecrease},
+	}
+	if len(clamps) != len(want) {
+		t.Fatalf("clamps = %#v, want %#v", clamps, want)
+	}
+	for i := range want {
+		if clamps[i] != want[i] {
+			t.Fatalf("clamps[%d] = %#v, want %#v", i, clamps[i], want[i])
+		}
+	}
+
+	if clamps := clampPriceChanges(&lp, 0); clamps != nil {
+		t.Fatalf("clamps = %#v, want none when disabled", clamps)
+	}
+}
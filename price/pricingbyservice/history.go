@@ -0,0 +1,343 @@
+package pricingbyservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+const PriceHistoryRedisKey = "DISCOVERY_PRICE_HISTORY_BY_SERVICE"
+
+const (
+	NodeTypeResidential = "residential"
+	NodeTypeOther       = "other"
+)
+
+// PriceSnapshot is a compact form of the generated LatestPrices. Only the countries
+// with prices different from the defaults are kept.
+type PriceSnapshot struct {
+	Time       time.Time               `json:"time"`
+	ValidUntil time.Time               `json:"valid_until"`
+	MystUSD    float64                 `json:"myst_usd"`
+	MystRates  map[string]float64      `json:"myst_rates,omitempty"`
+	Defaults   *PriceByType            `json:"defaults"`
+	PerCountry map[string]*PriceByType `json:"per_country,omitempty"`
+}
+
+// NewPriceSnapshot creates a snapshot of the current prices of lp generated with the MYST rates.
+func NewPriceSnapshot(tm time.Time, mystRates map[string]float64, lp LatestPrices) PriceSnapshot {
+	snapshot := PriceSnapshot{
+		Time:       tm.UTC(),
+		ValidUntil: lp.CurrentValidUntil,
+		MystUSD:    mystRates[DefaultBaseCurrency],
+		MystRates:  mystRates,
+	}
+	if lp.Defaults == nil {
+		return snapshot
+	}
+
+	snapshot.Defaults = lp.Defaults.Current
+	for country, ph := range lp.PerCountry {
+		if ph == nil || ph.Current.equal(snapshot.Defaults) {
+			continue
+		}
+		if snapshot.PerCountry == nil {
+			snapshot.PerCountry = make(map[string]*PriceByType)
+		}
+		snapshot.PerCountry[country] = ph.Current
+	}
+
+	return snapshot
+}
+
+// ForCountry returns the prices of the given country at the time of the snapshot.
+func (s PriceSnapshot) ForCountry(country string) *PriceByType {
+	if p, ok := s.PerCountry[country]; ok {
+		return p
+	}
+	return s.Defaults
+}
+
+// PriceHistoryStorage keeps price snapshots in a redis sorted set scored by
+// the time of the snapshot.
+type PriceHistoryStorage struct {
+	db        store.Store
+	retention time.Duration
+}
+
+// NewPriceHistoryStorage creates a price history storage. Snapshots older than
+// retention are dropped on each store, unless retention is 0.
+func NewPriceHistoryStorage(db store.Store, retention time.Duration) *PriceHistoryStorage {
+	return &PriceHistoryStorage{
+		db:        db,
+		retention: retention,
+	}
+}
+
+func (phs *PriceHistoryStorage) Store(ctx context.Context, snapshot PriceSnapshot) error {
+	blob, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	err = phs.db.ZAdd(ctx, PriceHistoryRedisKey, float64(snapshot.Time.Unix()), string(blob))
+	if err != nil {
+		return err
+	}
+
+	if phs.retention > 0 {
+		oldest := snapshot.Time.Add(-phs.retention).Unix()
+		err = phs.db.ZRemBelow(ctx, PriceHistoryRedisKey, float64(oldest))
+		if err != nil {
+			return fmt.Errorf("could not trim price history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Series returns the price points matching the query taken between from and to.
+// Only the snapshots kept by the downsampling are decoded, and of those only
+// the prices of the queried country.
+func (phs *PriceHistoryStorage) Series(ctx context.Context, from, to time.Time, q PriceHistoryQuery) ([]PriceHistoryPoint, error) {
+	members, err := phs.db.ZRange(ctx, PriceHistoryRedisKey, float64(from.Unix()), float64(to.Unix()), false, 0)
+	if err != nil {
+		return nil, err
+	}
+	if q.Step > 0 {
+		members = downsampleMembers(members, q.Step)
+		q.Step = 0
+	}
+
+	snapshots := make([]PriceSnapshot, 0, len(members))
+	for _, m := range members {
+		snapshot, err := decodeCountrySnapshot(m, q.Country)
+		if err != nil {
+			log.Warn().Err(err).Msg("skipping malformed price snapshot")
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return PriceHistorySeries(snapshots, q), nil
+}
+
+// downsampleMembers keeps the last of the encoded snapshots of each step,
+// decoding only their time.
+func downsampleMembers(members []string, step time.Duration) []string {
+	var (
+		res  []string
+		last time.Time
+	)
+	for _, m := range members {
+		var snapshot struct {
+			Time time.Time `json:"time"`
+		}
+		if err := json.Unmarshal([]byte(m), &snapshot); err != nil {
+			log.Warn().Err(err).Msg("skipping malformed price snapshot")
+			continue
+		}
+		bucket := snapshot.Time.Truncate(step)
+		if len(res) > 0 && last.Equal(bucket) {
+			res[len(res)-1] = m
+			continue
+		}
+		res = append(res, m)
+		last = bucket
+	}
+	return res
+}
+
+// decodeCountrySnapshot decodes the snapshot skipping the prices of the countries other than country.
+func decodeCountrySnapshot(member, country string) (PriceSnapshot, error) {
+	var raw struct {
+		PriceSnapshot
+		PerCountry map[string]json.RawMessage `json:"per_country,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(member), &raw); err != nil {
+		return PriceSnapshot{}, err
+	}
+
+	snapshot := raw.PriceSnapshot
+	snapshot.PerCountry = nil
+	if blob, ok := raw.PerCountry[country]; ok && country != "" {
+		var prices PriceByType
+		if err := json.Unmarshal(blob, &prices); err != nil {
+			return PriceSnapshot{}, err
+		}
+		snapshot.PerCountry = map[string]*PriceByType{country: &prices}
+	}
+	return snapshot, nil
+}
+
+// At returns the snapshot that was valid at the given time, that is the last one
+// taken at or before it, and the snapshot preceding it. Previous is nil when
+// the preceding snapshot is no longer retained and current is nil when there is
+// no snapshot at all.
+func (phs *PriceHistoryStorage) At(ctx context.Context, tm time.Time) (current, previous *PriceSnapshot, err error) {
+	members, err := phs.db.ZRange(ctx, PriceHistoryRedisKey, math.Inf(-1), float64(tm.Unix()), true, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshots := make([]*PriceSnapshot, 0, len(members))
+	for _, m := range members {
+		var snapshot PriceSnapshot
+		if err := json.Unmarshal([]byte(m), &snapshot); err != nil {
+			return nil, nil, fmt.Errorf("malformed price snapshot: %w", err)
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	switch len(snapshots) {
+	case 0:
+		return nil, nil, nil
+	case 1:
+		return snapshots[0], nil, nil
+	default:
+		return snapshots[0], snapshots[1], nil
+	}
+}
+
+// PriceAt holds the prices of a country which were valid at a point in time.
+type PriceAt struct {
+	Time       time.Time          `json:"time"`
+	Country    string             `json:"country,omitempty"`
+	ValidFrom  time.Time          `json:"valid_from"`
+	ValidUntil time.Time          `json:"valid_until"`
+	MystUSD    float64            `json:"myst_usd"`
+	MystRates  map[string]float64 `json:"myst_rates,omitempty"`
+	Prices     *PriceHistory      `json:"prices"`
+}
+
+// NewPriceAt builds the prices of the country valid at tm from the snapshot
+// valid at that time and the one preceding it. Without a preceding snapshot
+// the previous prices are the same as the current ones, just like in LatestPrices.
+func NewPriceAt(tm time.Time, country string, current PriceSnapshot, previous *PriceSnapshot) PriceAt {
+	ph := &PriceHistory{
+		Current:  current.ForCountry(country),
+		Previous: current.ForCountry(country),
+	}
+	if previous != nil {
+		ph.Previous = previous.ForCountry(country)
+	}
+
+	return PriceAt{
+		Time:       tm.UTC(),
+		Country:    country,
+		ValidFrom:  current.Time,
+		ValidUntil: current.ValidUntil,
+		MystUSD:    current.MystUSD,
+		MystRates:  current.MystRates,
+		Prices:     ph,
+	}
+}
+
+// PriceHistoryPoint is the price of a single node and service type at a point in time.
+type PriceHistoryPoint struct {
+	Time        time.Time   `json:"time"`
+	NodeType    string      `json:"node_type"`
+	ServiceType ServiceType `json:"service_type"`
+	Price       Price       `json:"price"`
+}
+
+// PriceHistoryQuery selects the prices returned by PriceHistorySeries. Empty
+// node and service types select all of them.
+type PriceHistoryQuery struct {
+	Country     string
+	NodeType    string
+	ServiceType ServiceType
+	// Step downsamples the series keeping the last snapshot of each step.
+	Step time.Duration
+}
+
+// PriceHistorySeries flattens the snapshots into price points matching the query.
+func PriceHistorySeries(snapshots []PriceSnapshot, q PriceHistoryQuery) []PriceHistoryPoint {
+	if q.Step > 0 {
+		snapshots = downsample(snapshots, q.Step)
+	}
+
+	nodeTypes := []string{NodeTypeResidential, NodeTypeOther}
+	if q.NodeType != "" {
+		nodeTypes = []string{q.NodeType}
+	}
+
+	res := make([]PriceHistoryPoint, 0)
+	for _, s := range snapshots {
+		prices := s.ForCountry(q.Country)
+		for _, nodeType := range nodeTypes {
+			byServiceType := prices.ForNodeType(nodeType == NodeTypeResidential)
+			serviceTypes := byServiceType.ServiceTypes()
+			if q.ServiceType != "" {
+				serviceTypes = []ServiceType{q.ServiceType}
+			}
+			for _, serviceType := range serviceTypes {
+				price, ok := byServiceType.ForServiceType(serviceType)
+				if !ok {
+					continue
+				}
+				res = append(res, PriceHistoryPoint{
+					Time:        s.Time,
+					NodeType:    nodeType,
+					ServiceType: serviceType,
+					Price:       price,
+				})
+			}
+		}
+	}
+
+	return res
+}
+
+func downsample(snapshots []PriceSnapshot, step time.Duration) []PriceSnapshot {
+	var res []PriceSnapshot
+	for _, s := range snapshots {
+		if len(res) > 0 && res[len(res)-1].Time.Truncate(step).Equal(s.Time.Truncate(step)) {
+			res[len(res)-1] = s
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+func (p *PriceByType) equal(other *PriceByType) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	return p.Residential.equal(other.Residential) && p.Other.equal(other.Other)
+}
+
+func (p PriceByServiceType) equal(other PriceByServiceType) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for serviceType, a := range p {
+		b, ok := other[serviceType]
+		if !ok || !a.equal(b) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Price) equal(other Price) bool {
+	return bigIntEqual(p.PricePerHour, other.PricePerHour) &&
+		bigIntEqual(p.PricePerGiB, other.PricePerGiB) &&
+		p.PricePerHourHumanReadable == other.PricePerHourHumanReadable &&
+		p.PricePerGiBHumanReadable == other.PricePerGiBHumanReadable
+}
+
+func bigIntEqual(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
@@ -0,0 +1,120 @@
+package pricingbyservice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+func testPriceByType(perGiB int64) *PriceByType {
+	price := Price{PricePerHour: big.NewInt(1), PricePerGiB: big.NewInt(perGiB)}
+	return &PriceByType{
+		Residential: PriceByServiceType{ServiceTypeWireguard: price, ServiceTypeScraping: price},
+		Other:       PriceByServiceType{ServiceTypeWireguard: price, ServiceTypeScraping: price},
+	}
+}
+
+func TestNewPriceSnapshotKeepsOnlyCountriesDifferentFromDefaults(t *testing.T) {
+	lp := LatestPrices{
+		Defaults: &PriceHistory{Current: testPriceByType(10)},
+		PerCountry: map[string]*PriceHistory{
+			"US": {Current: testPriceByType(10)},
+			"DE": {Current: testPriceByType(20)},
+		},
+	}
+
+	snapshot := NewPriceSnapshot(time.Now(), map[string]float64{"USD": 0.5}, lp)
+
+	if len(snapshot.PerCountry) != 1 || snapshot.PerCountry["DE"] == nil {
+		t.Fatalf("per country = %#v, want only DE", snapshot.PerCountry)
+	}
+	if got := snapshot.ForCountry("US").Other[ServiceTypeWireguard].PricePerGiB; got.Int64() != 10 {
+		t.Fatalf("US price = %v, want defaults", got)
+	}
+}
+
+func TestPriceHistorySeries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var snapshots []PriceSnapshot
+	for i := 0; i < 6; i++ {
+		snapshots = append(snapshots, PriceSnapshot{
+			Time:       start.Add(time.Duration(i) * 20 * time.Minute),
+			Defaults:   testPriceByType(10),
+			PerCountry: map[string]*PriceByType{"DE": testPriceByType(int64(20 + i))},
+		})
+	}
+
+	points := PriceHistorySeries(snapshots, PriceHistoryQuery{
+		Country:     "DE",
+		NodeType:    NodeTypeResidential,
+		ServiceType: ServiceTypeWireguard,
+		Step:        time.Hour,
+	})
+
+	if len(points) != 2 {
+		t.Fatalf("points = %d, want 2", len(points))
+	}
+	if got := points[0].Price.PricePerGiB.Int64(); got != 22 {
+		t.Fatalf("first point price = %v, want last price of the hour 22", got)
+	}
+	if got := points[1].Price.PricePerGiB.Int64(); got != 25 {
+		t.Fatalf("second point price = %v, want 25", got)
+	}
+
+	points = PriceHistorySeries(snapshots[:1], PriceHistoryQuery{Country: "FR"})
+	if len(points) != 2*len(testPriceByType(0).Residential) {
+		t.Fatalf("points = %d, want all node and priced service types", len(points))
+	}
+	if got := points[0].Price.PricePerGiB; got == nil || got.Int64() != 10 {
+		t.Fatalf("FR price = %v, want defaults", got)
+	}
+}
+
+func TestPriceHistoryStorageSeries(t *testing.T) {
+	ctx := context.Background()
+	phs := NewPriceHistoryStorage(store.NewMemory(), 0)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		err := phs.Store(ctx, PriceSnapshot{
+			Time:     start.Add(time.Duration(i) * 20 * time.Minute),
+			Defaults: testPriceByType(10),
+			PerCountry: map[string]*PriceByType{
+				"DE": testPriceByType(int64(20 + i)),
+				"US": testPriceByType(int64(30 + i)),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	points, err := phs.Series(ctx, start, start.Add(2*time.Hour), PriceHistoryQuery{
+		Country:     "DE",
+		NodeType:    NodeTypeOther,
+		ServiceType: ServiceTypeWireguard,
+		Step:        time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("points = %d, want 2", len(points))
+	}
+	if got := points[0].Price.PricePerGiB.Int64(); got != 22 {
+		t.Fatalf("first point price = %v, want last price of the hour 22", got)
+	}
+	if !points[1].Time.Equal(start.Add(100 * time.Minute)) {
+		t.Fatalf("second point time = %v, want the last snapshot of the hour", points[1].Time)
+	}
+
+	points, err = phs.Series(ctx, start, start, PriceHistoryQuery{Country: "FR", NodeType: NodeTypeOther, ServiceType: ServiceTypeWireguard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 1 || points[0].Price.PricePerGiB.Int64() != 10 {
+		t.Fatalf("FR points = %#v, want the defaults", points)
+	}
+}
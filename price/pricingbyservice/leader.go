@@ -0,0 +1,224 @@
+package pricingbyservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/discovery/metrics"
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+// LeaderRedisKey is the lock held by the pricer replica updating the prices.
+const LeaderRedisKey = "DISCOVERY_PRICER_LEADER"
+
+// DefaultLeaderLease is how long the leadership lasts without being renewed.
+const DefaultLeaderLease = 30 * time.Second
+
+// Leader tells whether this replica should update the prices.
+type Leader interface {
+	IsLeader() bool
+	// Elected is notified when this replica takes over the leadership.
+	Elected() <-chan struct{}
+}
+
+// LeaderLock is a lock expiring after its ttl unless renewed by its holder.
+type LeaderLock interface {
+	// Acquire takes the lock unless it is held by someone else.
+	Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// Renew extends the lock if it is still held by id.
+	Renew(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// Release frees the lock if it is still held by id.
+	Release(ctx context.Context, id string) error
+	// Holder returns the id holding the lock, empty when free.
+	Holder(ctx context.Context) (string, error)
+}
+
+// StoreLeaderLock is a LeaderLock of a key of the store set unless it exists, renewed
+// and released only while holding the id so a replica can't touch a lock taken over by another.
+type StoreLeaderLock struct {
+	db  store.Store
+	key string
+}
+
+func NewStoreLeaderLock(db store.Store, key string) *StoreLeaderLock {
+	return &StoreLeaderLock{db: db, key: key}
+}
+
+func (l *StoreLeaderLock) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	ok, err := l.db.SetNX(ctx, l.key, id, ttl)
+	if err != nil || ok {
+		return ok, err
+	}
+	// the lock may still be ours, e.g. after a failed renewal
+	return l.Renew(ctx, id, ttl)
+}
+
+func (l *StoreLeaderLock) Renew(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	return l.db.Expire(ctx, l.key, id, ttl)
+}
+
+func (l *StoreLeaderLock) Release(ctx context.Context, id string) error {
+	_, err := l.db.Delete(ctx, l.key, id)
+	return err
+}
+
+func (l *StoreLeaderLock) Holder(ctx context.Context) (string, error) {
+	holder, err := l.db.Get(ctx, l.key)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", nil
+	}
+	return holder, err
+}
+
+// LeaderStatus is the leadership as seen by a replica.
+type LeaderStatus struct {
+	ID     string `json:"id"`
+	Leader bool   `json:"leader"`
+	Holder string `json:"holder"`
+}
+
+// LeaderElection campaigns for the LeaderLock every third of the lease. The leader
+// renews the lock and steps down as soon as it fails to, while the others stand by
+// and take over once the lock expires.
+type LeaderElection struct {
+	lock  LeaderLock
+	id    string
+	lease time.Duration
+	now   func() time.Time
+
+	mu sync.Mutex
+	// leaseUntil is when the leadership expires unless renewed, zero when not the leader.
+	leaseUntil time.Time
+	elected    chan struct{}
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func NewLeaderElection(lock LeaderLock, id string, lease time.Duration) *LeaderElection {
+	return &LeaderElection{
+		lock:    lock,
+		id:      id,
+		lease:   lease,
+		now:     time.Now,
+		elected: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start campaigns right away, so the leadership is known on return, and keeps on campaigning in the background.
+func (e *LeaderElection) Start() {
+	e.campaign()
+	go e.run()
+}
+
+func (e *LeaderElection) run() {
+	defer close(e.done)
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-time.After(e.lease / 3):
+			e.campaign()
+		}
+	}
+}
+
+func (e *LeaderElection) campaign() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.lease/3)
+	defer cancel()
+
+	wasLeader := e.IsLeader()
+	start := e.now()
+
+	var ok bool
+	var err error
+	if wasLeader {
+		ok, err = e.lock.Renew(ctx, e.id, e.lease)
+	} else {
+		ok, err = e.lock.Acquire(ctx, e.id, e.lease)
+	}
+	if err != nil {
+		log.Err(err).Str("id", e.id).Msg("leader election failed")
+	}
+	leader := ok && err == nil
+
+	e.mu.Lock()
+	if leader {
+		// the lease started before the request reached the lock
+		e.leaseUntil = start.Add(e.lease)
+	} else {
+		e.leaseUntil = time.Time{}
+	}
+	e.mu.Unlock()
+
+	switch {
+	case leader && !wasLeader:
+		log.Info().Str("id", e.id).Msg("elected as the pricer leader")
+		select {
+		case e.elected <- struct{}{}:
+		default:
+		}
+	case !leader && wasLeader:
+		log.Warn().Str("id", e.id).Msg("lost the pricer leadership")
+	}
+	e.submitMetrics(leader)
+}
+
+func (e *LeaderElection) submitMetrics(leader bool) {
+	if leader {
+		metrics.PricerLeader.Set(1)
+	} else {
+		metrics.PricerLeader.Set(0)
+	}
+}
+
+// IsLeader is false once the lease expires even if the renewal is still pending.
+func (e *LeaderElection) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return !e.leaseUntil.IsZero() && e.now().Before(e.leaseUntil)
+}
+
+func (e *LeaderElection) Elected() <-chan struct{} {
+	return e.elected
+}
+
+func (e *LeaderElection) Status(ctx context.Context) (LeaderStatus, error) {
+	holder, err := e.lock.Holder(ctx)
+	return LeaderStatus{
+		ID:     e.id,
+		Leader: e.IsLeader(),
+		Holder: holder,
+	}, err
+}
+
+// Stop stops campaigning and releases the lock so another replica can take over right away.
+func (e *LeaderElection) Stop() {
+	e.once.Do(func() {
+		close(e.stop)
+		<-e.done
+
+		wasLeader := e.IsLeader()
+		e.mu.Lock()
+		e.leaseUntil = time.Time{}
+		e.mu.Unlock()
+		e.submitMetrics(false)
+		if !wasLeader {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), e.lease/3)
+		defer cancel()
+		if err := e.lock.Release(ctx, e.id); err != nil {
+			log.Err(err).Str("id", e.id).Msg("could not release the pricer leadership")
+		}
+	})
+}
@@ -0,0 +1,247 @@
+package pricingbyservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+// memoryLeaderLock is a LeaderLock expiring on the clock of the test.
+type memoryLeaderLock struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	holder  string
+	expires time.Time
+	err     error
+}
+
+func (l *memoryLeaderLock) Acquire(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return false, l.err
+	}
+	if l.holder != "" && l.holder != id && l.now().Before(l.expires) {
+		return false, nil
+	}
+	l.holder, l.expires = id, l.now().Add(ttl)
+	return true, nil
+}
+
+func (l *memoryLeaderLock) Renew(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return false, l.err
+	}
+	if l.holder != id || !l.now().Before(l.expires) {
+		return false, nil
+	}
+	l.expires = l.now().Add(ttl)
+	return true, nil
+}
+
+func (l *memoryLeaderLock) Release(_ context.Context, id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder == id {
+		l.holder = ""
+	}
+	return nil
+}
+
+func (l *memoryLeaderLock) Holder(_ context.Context) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.now().Before(l.expires) {
+		return "", nil
+	}
+	return l.holder, nil
+}
+
+func newTestElection(lock *memoryLeaderLock, id string, now func() time.Time) *LeaderElection {
+	e := NewLeaderElection(lock, id, time.Minute)
+	e.now = now
+	return e
+}
+
+func isElected(e *LeaderElection) bool {
+	select {
+	case <-e.Elected():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestLeaderElectionTakeOverOnLeaseExpiry(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := func() time.Time { return tm }
+	lock := &memoryLeaderLock{now: now}
+	first := newTestElection(lock, "first", now)
+	second := newTestElection(lock, "second", now)
+
+	first.campaign()
+	second.campaign()
+	if !first.IsLeader() || !isElected(first) {
+		t.Fatal("first replica should be elected")
+	}
+	if second.IsLeader() || isElected(second) {
+		t.Fatal("second replica should stand by")
+	}
+
+	// the leader renews its lease
+	tm = tm.Add(40 * time.Second)
+	first.campaign()
+	tm = tm.Add(40 * time.Second)
+	second.campaign()
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatal("first replica should keep the leadership after renewal")
+	}
+
+	// the leader stops renewing and the lease expires
+	tm = tm.Add(time.Minute)
+	if first.IsLeader() {
+		t.Fatal("leadership should expire with the lease")
+	}
+	second.campaign()
+	if !second.IsLeader() || !isElected(second) {
+		t.Fatal("second replica should take over")
+	}
+	first.campaign()
+	if first.IsLeader() || isElected(first) {
+		t.Fatal("first replica should stand by after losing the lease")
+	}
+
+	status, err := second.Status(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != (LeaderStatus{ID: "second", Leader: true, Holder: "second"}) {
+		t.Fatalf("status = %#v", status)
+	}
+}
+
+func TestLeaderElectionStepsDownOnRenewalError(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := func() time.Time { return tm }
+	lock := &memoryLeaderLock{now: now}
+	e := newTestElection(lock, "first", now)
+
+	e.campaign()
+	if !e.IsLeader() {
+		t.Fatal("replica should be elected")
+	}
+
+	lock.err = errors.New("redis down")
+	e.campaign()
+	if e.IsLeader() {
+		t.Fatal("replica should step down when the renewal fails")
+	}
+
+	// the lock is still ours once redis is back
+	lock.err = nil
+	isElected(e)
+	e.campaign()
+	if !e.IsLeader() || !isElected(e) {
+		t.Fatal("replica should be elected again")
+	}
+}
+
+func TestLeaderElectionStopReleasesLock(t *testing.T) {
+	lock := &memoryLeaderLock{now: time.Now}
+	first := NewLeaderElection(lock, "first", time.Minute)
+	second := NewLeaderElection(lock, "second", time.Minute)
+
+	first.Start()
+	second.Start()
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatal("first replica should be elected")
+	}
+
+	first.Stop()
+	defer second.Stop()
+	if first.IsLeader() {
+		t.Fatal("stopped replica should not lead")
+	}
+	second.campaign()
+	if !second.IsLeader() {
+		t.Fatal("second replica should take over the released lock")
+	}
+}
+
+// staticLeader is a Leader elected before the pricer starts.
+type staticLeader struct {
+	elected chan struct{}
+}
+
+func (l staticLeader) IsLeader() bool {
+	return true
+}
+
+func (l staticLeader) Elected() <-chan struct{} {
+	return l.elected
+}
+
+// countingDemandIndexes counts the price updates by the demand indexes they load.
+type countingDemandIndexes struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *countingDemandIndexes) DemandIndexes(context.Context) (map[ISO3166CountryCode]float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return nil, nil
+}
+
+func (c *countingDemandIndexes) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func TestPricerUpdatesOnceWhenElectedBeforeStart(t *testing.T) {
+	leader := staticLeader{elected: make(chan struct{}, 1)}
+	leader.elected <- struct{}{}
+	updates := &countingDemandIndexes{}
+	db := store.NewMemory()
+
+	p, err := NewPricer(
+		&simulationConfig{cfg: testPreviewConfig()},
+		staticMystRates{"USD": 0.2},
+		updates,
+		time.Hour,
+		Bound{Min: 0, Max: 1},
+		db,
+		NewPriceHistoryStorage(db, 0),
+		WithLeader(leader),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	if got := updates.count(); got != 1 {
+		t.Fatalf("prices updated %d times on start, want once", got)
+	}
+
+	leader.elected <- struct{}{}
+	deadline := time.Now().Add(time.Second)
+	for updates.count() != 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := updates.count(); got != 2 {
+		t.Fatalf("prices updated %d times, want an update on a later election", got)
+	}
+}
@@ -2,25 +2,66 @@ package pricingbyservice
 
 import (
 	"errors"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/mysteriumnetwork/payments/v3/exchange"
 	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/discovery/metrics"
+)
+
+type MarketAggregation string
+
+// minOutlierSources is how many sources should respond to tell an outlier among
+// them. With two, both deviate equally from their midpoint.
+const minOutlierSources = 3
+
+const (
+	MarketAggregationMedian          MarketAggregation = "median"
+	MarketAggregationWeightedAverage MarketAggregation = "weighted_average"
 )
 
+// MarketSource is a named external price API. Weight is used by the weighted
+// average aggregation, 1 when not set.
+type MarketSource struct {
+	Name   string
+	API    ExternalPriceAPI
+	Weight float64
+}
+
+// MarketConfig configures how the prices of the sources are aggregated.
+type MarketConfig struct {
+	Aggregation MarketAggregation
+	// MaxDeviation drops the prices deviating from the median of all sources by more
+	// than the given ratio, e.g. 0.1 for 10%. Disabled when 0. With fewer than 3
+	// responding sources or when all of them deviate, the price of the source closest
+	// to the last good one is taken and the disagreement is reported.
+	MaxDeviation float64
+	// MaxStaleness is the age of the last good price after which it is reported
+	// as stale. Disabled when 0.
+	MaxStaleness time.Duration
+	// Currencies are the fiat currencies tracked in addition to USD.
+	Currencies []exchange.Currency
+}
+
 type Market struct {
 	lock           sync.Mutex
 	stopOnce       sync.Once
 	stop           chan (struct{})
-	apis           []ExternalPriceAPI
-	latestPrice    float64
+	sources        []MarketSource
+	cfg            MarketConfig
+	latestRates    map[exchange.Currency]float64
+	latestUpdate   time.Time
 	updateInterval time.Duration
 }
 
-func NewMarket(apis []ExternalPriceAPI, updateInterval time.Duration) *Market {
+func NewMarket(sources []MarketSource, updateInterval time.Duration, cfg MarketConfig) *Market {
 	return &Market{
-		apis:           apis,
+		sources:        sources,
+		cfg:            cfg,
 		stop:           make(chan struct{}),
 		updateInterval: updateInterval,
 	}
@@ -30,55 +71,233 @@ type ExternalPriceAPI interface {
 	GetRateCacheWithFallback(coins []exchange.Coin, vsCurrencies []exchange.Currency) (exchange.PriceResponse, error)
 }
 
+// MystUSD returns the last good MYST/USD price.
 func (m *Market) MystUSD() float64 {
-	return m.getPrice()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.latestRates[exchange.CurrencyUSD]
+}
+
+// MystRates returns the last good MYST prices by fiat currency code.
+func (m *Market) MystRates() map[string]float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	res := make(map[string]float64, len(m.latestRates))
+	for currency, rate := range m.latestRates {
+		res[string(currency)] = rate
+	}
+	return res
 }
 
 func (m *Market) Start() error {
-	if len(m.apis) == 0 {
+	if len(m.sources) == 0 {
 		return errors.New("no price api providers provided")
 	}
 
-	pr, err := m.fetchPricing()
+	rates, err := m.fetchPricing()
 	if err != nil {
 		return err
 	}
-	m.setPrice(pr)
+	m.setRates(rates)
 
 	go m.periodicUpdate()
 	return nil
 }
 
-func (m *Market) getPrice() float64 {
+// setRates stores the rates, keeping the last good ones of the currencies missing in them.
+func (m *Market) setRates(rates map[exchange.Currency]float64) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	return m.latestPrice
+
+	latest := make(map[exchange.Currency]float64, len(rates))
+	for currency, rate := range m.latestRates {
+		latest[currency] = rate
+	}
+	for currency, rate := range rates {
+		latest[currency] = rate
+	}
+	m.latestRates = latest
+	m.latestUpdate = time.Now()
 }
 
-func (m *Market) setPrice(in float64) {
-	m.lock.Lock()
-	defer m.lock.Unlock()
-	m.latestPrice = in
+func (m *Market) currencies() []exchange.Currency {
+	res := []exchange.Currency{exchange.CurrencyUSD}
+	for _, currency := range m.cfg.Currencies {
+		if currency != exchange.CurrencyUSD {
+			res = append(res, currency)
+		}
+	}
+	return res
+}
+
+type sourcePrice struct {
+	name   string
+	price  float64
+	weight float64
 }
 
-func (m *Market) fetchPricing() (float64, error) {
-	for _, v := range m.apis {
-		resp, err := v.GetRateCacheWithFallback([]exchange.Coin{exchange.CoinMYST}, []exchange.Currency{exchange.CurrencyUSD})
+// fetchPricing queries all the sources concurrently and aggregates their prices
+// for each currency. It fails only when there is no USD price.
+func (m *Market) fetchPricing() (map[exchange.Currency]float64, error) {
+	currencies := m.currencies()
+	results := make([]exchange.Rates, len(m.sources))
+	var wg sync.WaitGroup
+	for i, source := range m.sources {
+		wg.Add(1)
+		go func(i int, source MarketSource) {
+			defer wg.Done()
+			rates, err := fetchSourceRates(source, currencies)
+			if err != nil {
+				log.Error().Err(err).Str("source", source.Name).Msg("could not load pricing info")
+				metrics.MarketSourceErrors.WithLabelValues(source.Name).Inc()
+				return
+			}
+			results[i] = rates
+		}(i, source)
+	}
+	wg.Wait()
+
+	res := make(map[exchange.Currency]float64, len(currencies))
+	for _, currency := range currencies {
+		prices := make([]sourcePrice, 0, len(results))
+		for i, rates := range results {
+			rate, ok := rates.GetRate(currency)
+			if !ok || rate <= 0 {
+				continue
+			}
+			weight := m.sources[i].Weight
+			if weight <= 0 {
+				weight = 1
+			}
+			prices = append(prices, sourcePrice{name: m.sources[i].Name, price: rate, weight: weight})
+		}
+
+		rate, err := m.aggregate(currency, prices, m.lastRate(currency))
 		if err != nil {
-			log.Error().Err(err).Msg("could not load pricing info")
+			if currency == exchange.CurrencyUSD {
+				return nil, err
+			}
+			log.Warn().Err(err).Msgf("could not load MYST/%s price, keeping the last good one", currency)
 			continue
 		}
+		res[currency] = rate
+	}
 
-		price, ok := resp.GetRateInUSD(exchange.CoinMYST)
-		if !ok {
-			log.Warn().Msg("no price info for MYST found in response")
+	return res, nil
+}
+
+func fetchSourceRates(source MarketSource, currencies []exchange.Currency) (exchange.Rates, error) {
+	resp, err := source.API.GetRateCacheWithFallback([]exchange.Coin{exchange.CoinMYST}, currencies)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, ok := resp.GetRates(exchange.CoinMYST)
+	if !ok {
+		return nil, errors.New("no price info for MYST found in response")
+	}
+	return rates, nil
+}
+
+func (m *Market) lastRate(currency exchange.Currency) float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.latestRates[currency]
+}
+
+// aggregate drops the outliers and aggregates the remaining prices. When too few
+// sources agree to tell the outliers, the price closest to the last good one is
+// taken, or all the prices are aggregated without one.
+func (m *Market) aggregate(currency exchange.Currency, prices []sourcePrice, last float64) (float64, error) {
+	if len(prices) == 0 {
+		return 0, errors.New("could not load price info")
+	}
+
+	all := make([]float64, len(prices))
+	for i, p := range prices {
+		all[i] = p.price
+	}
+	median := medianOf(all)
+
+	accepted := make([]sourcePrice, 0, len(prices))
+	for _, p := range prices {
+		deviation := math.Abs(p.price-median) / median
+		metrics.MarketSourcePrice.WithLabelValues(p.name, string(currency)).Set(p.price)
+		metrics.MarketSourceDeviation.WithLabelValues(p.name, string(currency)).Set(deviation)
+
+		if m.cfg.MaxDeviation > 0 && deviation > m.cfg.MaxDeviation && len(prices) >= minOutlierSources {
+			log.Warn().Msgf("dropping MYST/%s price %.6f of %s deviating %.2f%% from the median %.6f", currency, p.price, p.name, deviation*100, median)
+			metrics.MarketSourceOutliers.WithLabelValues(p.name, string(currency)).Inc()
 			continue
 		}
+		accepted = append(accepted, p)
+	}
 
-		return price, nil
+	disagree := len(accepted) == 0
+	if m.cfg.MaxDeviation > 0 && len(prices) < minOutlierSources {
+		for _, p := range prices {
+			disagree = disagree || math.Abs(p.price-median)/median > m.cfg.MaxDeviation
+		}
+	}
+	if disagree {
+		metrics.MarketSourceDisagreements.WithLabelValues(string(currency)).Inc()
+		if last > 0 {
+			closest := prices[0]
+			for _, p := range prices[1:] {
+				if math.Abs(p.price-last) < math.Abs(closest.price-last) {
+					closest = p
+				}
+			}
+			log.Warn().Msgf("%d MYST/%s prices deviate from the median %.6f, too few agree to drop any, taking %.6f of %s closest to the last good one", len(prices), currency, median, closest.price, closest.name)
+			return closest.price, nil
+		}
+		log.Warn().Msgf("%d MYST/%s prices deviate from the median %.6f, too few agree to drop any", len(prices), currency, median)
+	}
+	if len(accepted) == 0 {
+		accepted = prices
 	}
 
-	return 0, errors.New("could not load price info")
+	if m.cfg.Aggregation == MarketAggregationWeightedAverage {
+		var sum, weights float64
+		for _, p := range accepted {
+			sum += p.price * p.weight
+			weights += p.weight
+		}
+		return sum / weights, nil
+	}
+
+	values := make([]float64, len(accepted))
+	for i, p := range accepted {
+		values[i] = p.price
+	}
+	return medianOf(values), nil
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// checkStaleness reports the age of the last good price and whether it is stale.
+func (m *Market) checkStaleness() {
+	m.lock.Lock()
+	age := time.Since(m.latestUpdate)
+	m.lock.Unlock()
+
+	metrics.MarketPriceAge.Set(age.Seconds())
+	stale := m.cfg.MaxStaleness > 0 && age > m.cfg.MaxStaleness
+	if stale {
+		log.Warn().Msgf("MYST price is stale, using the last good price from %s ago", age.Round(time.Second))
+		metrics.MarketPriceStale.Set(1)
+	} else {
+		metrics.MarketPriceStale.Set(0)
+	}
 }
 
 func (m *Market) periodicUpdate() {
@@ -87,10 +306,13 @@ func (m *Market) periodicUpdate() {
 		case <-m.stop:
 			return
 		case <-time.After(m.updateInterval):
-			res, err := m.fetchPricing()
+			rates, err := m.fetchPricing()
 			if err == nil {
-				m.setPrice(res)
+				m.setRates(rates)
+			} else {
+				log.Err(err).Msg("failed to update MYST price, keeping the last good one")
 			}
+			m.checkStaleness()
 		}
 	}
 }
@@ -0,0 +1,143 @@
+package pricingbyservice
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/v3/exchange"
+)
+
+type staticPriceAPI struct {
+	price float64
+	eur   float64
+	err   error
+}
+
+func (s staticPriceAPI) GetRateCacheWithFallback(coins []exchange.Coin, vsCurrencies []exchange.Currency) (exchange.PriceResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	rates := exchange.Rates{exchange.CurrencyUSD: s.price}
+	if s.eur > 0 {
+		rates[exchange.CurrencyEUR] = s.eur
+	}
+	return exchange.PriceResponse{exchange.CoinMYST: rates}, nil
+}
+
+func TestMarketFetchPricing(t *testing.T) {
+	sources := []MarketSource{
+		{Name: "a", API: staticPriceAPI{price: 0.20}},
+		{Name: "b", API: staticPriceAPI{price: 0.22}, Weight: 3},
+		{Name: "c", API: staticPriceAPI{price: 2.0}},
+		{Name: "d", API: staticPriceAPI{err: errors.New("unavailable")}},
+	}
+
+	tests := []struct {
+		name string
+		cfg  MarketConfig
+		want float64
+	}{
+		{"median of all", MarketConfig{Aggregation: MarketAggregationMedian}, 0.22},
+		{"median without outliers", MarketConfig{Aggregation: MarketAggregationMedian, MaxDeviation: 0.5}, 0.21},
+		{"weighted average without outliers", MarketConfig{Aggregation: MarketAggregationWeightedAverage, MaxDeviation: 0.5}, 0.215},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMarket(sources, time.Minute, tt.cfg).fetchPricing()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got[exchange.CurrencyUSD]-tt.want) > 1e-9 {
+				t.Fatalf("price = %v, want %v", got[exchange.CurrencyUSD], tt.want)
+			}
+		})
+	}
+}
+
+func TestMarketFetchPricingFailsWithoutPrices(t *testing.T) {
+	m := NewMarket([]MarketSource{{Name: "a", API: staticPriceAPI{err: errors.New("unavailable")}}}, time.Minute, MarketConfig{})
+	if _, err := m.fetchPricing(); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestMarketTakesClosestPriceWithoutEnoughSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []MarketSource
+		last    float64
+		want    float64
+	}{
+		{
+			name: "two sources",
+			sources: []MarketSource{
+				{Name: "coingecko", API: staticPriceAPI{price: 1.0}},
+				{Name: "coinranking", API: staticPriceAPI{price: 0.21}},
+			},
+			last: 0.2,
+			want: 0.21,
+		},
+		{
+			name: "all deviating",
+			sources: []MarketSource{
+				{Name: "a", API: staticPriceAPI{price: 0.1}},
+				{Name: "b", API: staticPriceAPI{price: 0.1}},
+				{Name: "c", API: staticPriceAPI{price: 0.3}},
+				{Name: "d", API: staticPriceAPI{price: 0.3}},
+			},
+			last: 0.28,
+			want: 0.3,
+		},
+		{
+			name: "without a last good price",
+			sources: []MarketSource{
+				{Name: "coingecko", API: staticPriceAPI{price: 0.1}},
+				{Name: "coinranking", API: staticPriceAPI{price: 0.3}},
+			},
+			want: 0.2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMarket(tt.sources, time.Minute, MarketConfig{MaxDeviation: 0.1})
+			if tt.last > 0 {
+				m.setRates(map[exchange.Currency]float64{exchange.CurrencyUSD: tt.last})
+			}
+			got, err := m.fetchPricing()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got[exchange.CurrencyUSD]-tt.want) > 1e-9 {
+				t.Fatalf("price = %v, want %v", got[exchange.CurrencyUSD], tt.want)
+			}
+		})
+	}
+}
+
+func TestMarketTracksCurrencies(t *testing.T) {
+	m := NewMarket([]MarketSource{
+		{Name: "a", API: staticPriceAPI{price: 0.2, eur: 0.18}},
+		{Name: "b", API: staticPriceAPI{price: 0.22}},
+	}, time.Minute, MarketConfig{Currencies: []exchange.Currency{exchange.CurrencyEUR, exchange.CurrencyGBP}})
+
+	rates, err := m.fetchPricing()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.setRates(rates)
+
+	got := m.MystRates()
+	if math.Abs(got["USD"]-0.21) > 1e-9 || got["EUR"] != 0.18 {
+		t.Fatalf("rates = %v, want USD 0.21 and EUR 0.18", got)
+	}
+	if _, ok := got["GBP"]; ok {
+		t.Fatalf("rates = %v, want no GBP rate without a source", got)
+	}
+
+	m.setRates(map[exchange.Currency]float64{exchange.CurrencyUSD: 0.3})
+	if got := m.MystRates(); got["EUR"] != 0.18 || m.MystUSD() != 0.3 {
+		t.Fatalf("rates = %v, want the last good EUR rate kept", got)
+	}
+}
@@ -0,0 +1,161 @@
+package pricingbyservice
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PreviewRequest is a candidate config to preview the prices of. Without demand
+// indexes the demand boost is not applied and the country modifiers of the config
+// are used as they are. At sets the time the campaigns are previewed at, now
+// when empty. MystUSD takes precedence over the USD rate of MystRates. Supply is
+// the number of providers of each country used by the demand boost curves.
+type PreviewRequest struct {
+	Config        Config                         `json:"config"`
+	MystUSD       float64                        `json:"myst_usd,omitempty"`
+	MystRates     map[string]float64             `json:"myst_rates,omitempty"`
+	DemandIndexes map[ISO3166CountryCode]float64 `json:"demand_indexes,omitempty"`
+	Supply        map[ISO3166CountryCode]float64 `json:"supply,omitempty"`
+	At            time.Time                      `json:"at,omitempty"`
+}
+
+// PricePreview holds the prices a config would produce and how they differ from
+// the current ones. Clamped lists the prices limited by the guardrails of the config.
+type PricePreview struct {
+	MystUSD   float64            `json:"myst_usd"`
+	MystRates map[string]float64 `json:"myst_rates,omitempty"`
+	Prices    LatestPrices       `json:"prices"`
+	Changes   []PriceChange      `json:"changes"`
+	Clamped   []PriceClamp       `json:"clamped,omitempty"`
+}
+
+// Rates returns the MYST rates of the request by fiat currency.
+func (r PreviewRequest) Rates() map[string]float64 {
+	rates := make(map[string]float64, len(r.MystRates)+1)
+	for currency, rate := range r.MystRates {
+		rates[currency] = rate
+	}
+	if r.MystUSD > 0 {
+		rates[DefaultBaseCurrency] = r.MystUSD
+	}
+	return rates
+}
+
+// PriceChange is a single price which differs between the current and the previewed
+// prices. Country is empty for the defaults. The changes are relative, e.g. 0.1
+// for a price 10% higher than the current one.
+type PriceChange struct {
+	Country       string      `json:"country,omitempty"`
+	NodeType      string      `json:"node_type"`
+	ServiceType   ServiceType `json:"service_type"`
+	Current       Price       `json:"current"`
+	Preview       Price       `json:"preview"`
+	PerHourChange float64     `json:"per_hour_change"`
+	PerGiBChange  float64     `json:"per_gib_change"`
+}
+
+// PreviewPrices generates the prices for the request the same way the pricer does,
+// using current as the prices being replaced. It fails without a rate in the base
+// currency of the config.
+func PreviewPrices(req PreviewRequest, current LatestPrices) (PricePreview, error) {
+	cfg := req.Config
+	rates := req.Rates()
+	mystRate, ok := rates[cfg.Currency()]
+	if !ok || mystRate <= 0 {
+		return PricePreview{}, fmt.Errorf("no MYST/%s rate to preview the prices with", cfg.Currency())
+	}
+
+	var serviceMultipliers map[ISO3166CountryCode]map[ServiceType]float64
+	if req.DemandIndexes != nil {
+		if multipliers := DemandBoostMultipliers(cfg, req.DemandIndexes, req.Supply); multipliers != nil {
+			updateCountryModifiers(&cfg, multipliers)
+		}
+		serviceMultipliers = DemandBoostServiceMultipliers(cfg, req.DemandIndexes, req.Supply)
+	}
+
+	p := &PriceUpdater{
+		lp:            current,
+		priceLifetime: DefaultPriceLifetime,
+	}
+	if !req.At.IsZero() {
+		p.now = func() time.Time { return req.At }
+	}
+	lp := p.generateNewLatestPrice(mystRate, cfg, serviceMultipliers)
+	var clamped []PriceClamp
+	if current.isInitialized() {
+		clamped = clampPriceChanges(&lp, cfg.maxPriceChange())
+	}
+	withFiatEquivalents(&lp, rates, cfg.FiatCurrencies)
+
+	return PricePreview{
+		MystUSD:   rates[DefaultBaseCurrency],
+		MystRates: rates,
+		Prices:    lp,
+		Changes:   PriceChanges(current, lp),
+		Clamped:   clamped,
+	}, nil
+}
+
+// PriceChanges lists the current prices of from which differ in to, the defaults
+// first and then by country.
+func PriceChanges(from, to LatestPrices) []PriceChange {
+	res := make([]PriceChange, 0)
+	if to.Defaults != nil {
+		res = appendPriceChanges(res, "", currentPrices(from.Defaults), to.Defaults.Current)
+	}
+
+	countries := make([]string, 0, len(to.PerCountry))
+	for country := range to.PerCountry {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+
+	for _, country := range countries {
+		ph := to.PerCountry[country]
+		if ph == nil {
+			continue
+		}
+		res = appendPriceChanges(res, country, currentPrices(from.ForCountry(country)), ph.Current)
+	}
+
+	return res
+}
+
+func currentPrices(ph *PriceHistory) *PriceByType {
+	if ph == nil {
+		return nil
+	}
+	return ph.Current
+}
+
+func appendPriceChanges(res []PriceChange, country string, from, to *PriceByType) []PriceChange {
+	for _, nodeType := range []string{NodeTypeResidential, NodeTypeOther} {
+		residential := nodeType == NodeTypeResidential
+		for _, serviceType := range to.ForNodeType(residential).ServiceTypes() {
+			current, _ := from.ForNodeType(residential).ForServiceType(serviceType)
+			preview, ok := to.ForNodeType(residential).ForServiceType(serviceType)
+			if !ok || current.equal(preview) {
+				continue
+			}
+
+			res = append(res, PriceChange{
+				Country:       country,
+				NodeType:      nodeType,
+				ServiceType:   serviceType,
+				Current:       current,
+				Preview:       preview,
+				PerHourChange: relativeChange(current.PricePerHourHumanReadable, preview.PricePerHourHumanReadable),
+				PerGiBChange:  relativeChange(current.PricePerGiBHumanReadable, preview.PricePerGiBHumanReadable),
+			})
+		}
+	}
+	return res
+}
+
+func relativeChange(from, to float64) float64 {
+	if from == 0 {
+		return 0
+	}
+	return (to - from) / from
+}
@@ -0,0 +1,117 @@
+package pricingbyservice
+
+import (
+	"math"
+	"testing"
+)
+
+func testPreviewConfig() Config {
+	price := PriceUSD{PricePerHour: 1, PricePerGiB: 2}
+	prices := PriceByServiceTypeUSD{
+		ServiceTypeWireguard:    price,
+		ServiceTypeScraping:     price,
+		ServiceTypeQUICScraping: price,
+		ServiceTypeDataTransfer: price,
+		ServiceTypeDVPN:         price,
+		ServiceTypeMonitoring:   price,
+	}
+	return Config{
+		BasePrices: PriceByTypeUSD{Residential: prices, Other: prices},
+		CountryModifiers: map[ISO3166CountryCode]Modifier{
+			"US": {Residential: 2, Other: 1},
+		},
+	}
+}
+
+func TestPreviewPrices(t *testing.T) {
+	cfg := testPreviewConfig()
+	initial, err := PreviewPrices(PreviewRequest{Config: cfg, MystUSD: 1}, LatestPrices{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current := initial.Prices
+
+	cfg.CountryModifiers["US"] = Modifier{Residential: 1, Other: 1}
+	preview, err := PreviewPrices(PreviewRequest{Config: cfg, MystUSD: 1}, current)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := preview.Prices.ForCountry("US").Current.Residential[ServiceTypeWireguard].PricePerGiBHumanReadable; got != 2 {
+		t.Fatalf("US residential wireguard price = %v, want 2", got)
+	}
+	if got := preview.Prices.ForCountry("US").Previous.Residential[ServiceTypeWireguard].PricePerGiBHumanReadable; got != 4 {
+		t.Fatalf("US residential wireguard previous price = %v, want 4", got)
+	}
+
+	if len(preview.Changes) != len(DefaultServiceTypes) {
+		t.Fatalf("changes = %#v, want only US residential prices", preview.Changes)
+	}
+	for _, change := range preview.Changes {
+		if change.Country != "US" || change.NodeType != NodeTypeResidential {
+			t.Fatalf("unexpected change %#v", change)
+		}
+		if change.PerGiBChange != -0.5 || change.PerHourChange != -0.5 {
+			t.Fatalf("change of %s = %v/%v, want -0.5", change.ServiceType, change.PerGiBChange, change.PerHourChange)
+		}
+	}
+}
+
+func TestPreviewPricesAppliesDemandBoostOnlyWithDemandIndexes(t *testing.T) {
+	cfg := testPreviewConfig()
+	cfg.DemandBoost = &DemandBoostConfig{
+		Countries: map[ISO3166CountryCode]DemandBoostCountryCfg{
+			"DE": {TargetDemandIndex: 0.1, MaxBonus: 0.5},
+		},
+	}
+
+	withoutIndexes, err := PreviewPrices(PreviewRequest{Config: cfg, MystUSD: 1}, LatestPrices{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := withoutIndexes.Prices.ForCountry("DE").Current.Other[ServiceTypeDVPN].PricePerGiBHumanReadable; got != 2 {
+		t.Fatalf("DE price without demand indexes = %v, want 2", got)
+	}
+
+	withIndexes, err := PreviewPrices(PreviewRequest{
+		Config:        cfg,
+		MystUSD:       1,
+		DemandIndexes: map[ISO3166CountryCode]float64{"DE": 0.05},
+	}, LatestPrices{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := withIndexes.Prices.ForCountry("DE").Current.Other[ServiceTypeDVPN].PricePerGiBHumanReadable; math.Abs(got-2.5) > 1e-9 {
+		t.Fatalf("DE price with demand indexes = %v, want 2.5", got)
+	}
+}
+
+func TestPreviewPricesInBaseCurrency(t *testing.T) {
+	cfg := testPreviewConfig()
+	cfg.BaseCurrency = "EUR"
+	cfg.FiatCurrencies = []string{"USD", "GBP"}
+
+	if _, err := PreviewPrices(PreviewRequest{Config: cfg, MystUSD: 1}, LatestPrices{}); err == nil {
+		t.Fatal("expected an error without a MYST/EUR rate")
+	}
+
+	preview, err := PreviewPrices(PreviewRequest{
+		Config:    cfg,
+		MystUSD:   0.5,
+		MystRates: map[string]float64{"EUR": 0.25},
+	}, LatestPrices{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	price := preview.Prices.Defaults.Current.Other[ServiceTypeWireguard]
+	if price.PricePerGiBHumanReadable != 8 {
+		t.Fatalf("price = %v, want 8 MYST for 2 EUR at 0.25 EUR/MYST", price.PricePerGiBHumanReadable)
+	}
+	if got := price.Fiat["USD"]; got.PricePerGiB != 4 || got.PricePerHour != 2 {
+		t.Fatalf("USD equivalent = %#v, want 2/h and 4/GiB", got)
+	}
+	if _, ok := price.Fiat["GBP"]; ok {
+		t.Fatal("expected no equivalent in a currency without a rate")
+	}
+}
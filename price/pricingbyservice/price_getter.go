@@ -9,36 +9,111 @@ import (
 	"sync"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
 )
 
 type PriceGetter struct {
-	db    redis.UniversalClient
+	db    store.Store
 	lp    LatestPrices
 	mutex sync.Mutex
+
+	listeners map[chan LatestPrices]struct{}
+	updates   store.Subscription
 }
 
-func NewPriceGetter(db redis.UniversalClient) (*PriceGetter, error) {
+func NewPriceGetter(db store.Store) (*PriceGetter, error) {
 	loaded, err := loadPricing(db)
 	if err != nil {
 		return nil, fmt.Errorf("could not laod initial price %w", err)
 	}
 
 	return &PriceGetter{
-		db: db,
-		lp: loaded,
+		db:        db,
+		lp:        loaded,
+		listeners: make(map[chan LatestPrices]struct{}),
 	}, nil
 }
 
-func loadPricing(db redis.UniversalClient) (LatestPrices, error) {
+// Start subscribes to the price updates published by the PriceUpdater to reload
+// the prices right away instead of once the current ones expire.
+func (pg *PriceGetter) Start() {
+	pg.updates = pg.db.Subscribe(context.Background(), PriceUpdateChannel)
+	go pg.reloadOnUpdates(pg.updates.Channel())
+}
+
+func (pg *PriceGetter) reloadOnUpdates(updates <-chan string) {
+	for range updates {
+		loaded, err := loadPricing(pg.db)
+		if err != nil {
+			log.Err(err).Msg("could not reload pricing on update")
+			continue
+		}
+		log.Info().Msg("pricing reloaded on update")
+
+		pg.mutex.Lock()
+		pg.setPrices(loaded)
+		pg.mutex.Unlock()
+	}
+}
+
+func (pg *PriceGetter) Stop() {
+	if pg.updates == nil {
+		return
+	}
+	if err := pg.updates.Close(); err != nil {
+		log.Err(err).Msg("could not unsubscribe from price updates")
+	}
+}
+
+// Listen notifies of the prices each time they change until the returned func is called.
+// Listeners falling behind only get the latest prices.
+func (pg *PriceGetter) Listen() (<-chan LatestPrices, func()) {
+	pg.mutex.Lock()
+	defer pg.mutex.Unlock()
+
+	ch := make(chan LatestPrices, 1)
+	pg.listeners[ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			pg.mutex.Lock()
+			defer pg.mutex.Unlock()
+
+			delete(pg.listeners, ch)
+			close(ch)
+		})
+	}
+}
+
+// setPrices replaces the prices and notifies the listeners if they changed.
+// It should be called holding the mutex.
+func (pg *PriceGetter) setPrices(lp LatestPrices) {
+	changed := !lp.CurrentValidUntil.Equal(pg.lp.CurrentValidUntil)
+	pg.lp = lp
+	if !changed {
+		return
+	}
+
+	for ch := range pg.listeners {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- lp.WithCurrentTime()
+	}
+}
+
+func loadPricing(db store.Store) (LatestPrices, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
-	redisRes, err := db.Get(ctx, PriceRedisKey).Result()
+	stored, err := db.Get(ctx, PriceRedisKey)
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			log.Info().Msg("no pricing found in redis, will use defaults")
+		if errors.Is(err, store.ErrNotFound) {
+			log.Info().Msg("no pricing found in store, will use defaults")
 			cp := defaultPrices
 			now := time.Now().UTC()
 			cp.CurrentValidUntil = now.Add(time.Second * 1)
@@ -49,7 +124,7 @@ func loadPricing(db redis.UniversalClient) (LatestPrices, error) {
 	}
 
 	var res LatestPrices
-	return res, json.Unmarshal([]byte(redisRes), &res)
+	return res, json.Unmarshal([]byte(stored), &res)
 }
 
 func (pg *PriceGetter) GetPrices() LatestPrices {
@@ -63,7 +138,7 @@ func (pg *PriceGetter) GetPrices() LatestPrices {
 			return pg.lp.WithCurrentTime()
 		}
 		log.Info().Msg("pricing loaded from db")
-		pg.lp = loaded
+		pg.setPrices(loaded)
 	}
 
 	return pg.lp.WithCurrentTime()
@@ -72,76 +147,76 @@ func (pg *PriceGetter) GetPrices() LatestPrices {
 var defaultPrices = LatestPrices{
 	Defaults: &PriceHistory{
 		Current: &PriceByType{
-			Residential: &PriceByServiceType{
-				Wireguard: Price{
+			Residential: PriceByServiceType{
+				ServiceTypeWireguard: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				Scraping: Price{
+				ServiceTypeScraping: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				QUICScraping: Price{
+				ServiceTypeQUICScraping: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				DataTransfer: Price{
+				ServiceTypeDataTransfer: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				DVPN: Price{
+				ServiceTypeDVPN: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				Monitoring: Price{
+				ServiceTypeMonitoring: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
 			},
-			Other: &PriceByServiceType{
-				Wireguard: Price{
+			Other: PriceByServiceType{
+				ServiceTypeWireguard: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				Scraping: Price{
+				ServiceTypeScraping: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				QUICScraping: Price{
+				ServiceTypeQUICScraping: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				DataTransfer: Price{
+				ServiceTypeDataTransfer: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				DVPN: Price{
+				ServiceTypeDVPN: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				Monitoring: Price{
+				ServiceTypeMonitoring: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
@@ -150,76 +225,76 @@ var defaultPrices = LatestPrices{
 			},
 		},
 		Previous: &PriceByType{
-			Residential: &PriceByServiceType{
-				Wireguard: Price{
+			Residential: PriceByServiceType{
+				ServiceTypeWireguard: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				Scraping: Price{
+				ServiceTypeScraping: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				QUICScraping: Price{
+				ServiceTypeQUICScraping: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				DataTransfer: Price{
+				ServiceTypeDataTransfer: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				DVPN: Price{
+				ServiceTypeDVPN: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				Monitoring: Price{
+				ServiceTypeMonitoring: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
 			},
-			Other: &PriceByServiceType{
-				Wireguard: Price{
+			Other: PriceByServiceType{
+				ServiceTypeWireguard: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				Scraping: Price{
+				ServiceTypeScraping: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				QUICScraping: Price{
+				ServiceTypeQUICScraping: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				DataTransfer: Price{
+				ServiceTypeDataTransfer: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				DVPN: Price{
+				ServiceTypeDVPN: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
 					PricePerGiBHumanReadable:  0.15,
 				},
-				Monitoring: Price{
+				ServiceTypeMonitoring: Price{
 					PricePerHour:              big.NewInt(900000000000000),
 					PricePerHourHumanReadable: 0.0009,
 					PricePerGiB:               big.NewInt(150000000000000000),
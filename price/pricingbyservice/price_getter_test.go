@@ -0,0 +1,86 @@
+package pricingbyservice
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+func TestPriceGetterNotifiesListenersOfChangedPrices(t *testing.T) {
+	validUntil := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	pg := &PriceGetter{
+		lp:        LatestPrices{CurrentValidUntil: validUntil},
+		listeners: make(map[chan LatestPrices]struct{}),
+	}
+	updates, stop := pg.Listen()
+
+	pg.setPrices(LatestPrices{CurrentValidUntil: validUntil})
+	select {
+	case lp := <-updates:
+		t.Fatalf("unexpected update %#v of unchanged prices", lp)
+	default:
+	}
+
+	// a listener falling behind only gets the latest prices
+	pg.setPrices(LatestPrices{CurrentValidUntil: validUntil.Add(5 * time.Minute)})
+	pg.setPrices(LatestPrices{CurrentValidUntil: validUntil.Add(10 * time.Minute)})
+	select {
+	case lp := <-updates:
+		if !lp.CurrentValidUntil.Equal(validUntil.Add(10 * time.Minute)) {
+			t.Fatalf("update valid until %s, want the latest prices", lp.CurrentValidUntil)
+		}
+		if lp.CurrentServerTime.IsZero() {
+			t.Fatal("update should have the current server time")
+		}
+	default:
+		t.Fatal("expected an update of changed prices")
+	}
+
+	stop()
+	stop()
+	if _, ok := <-updates; ok {
+		t.Fatal("updates should be closed once stopped")
+	}
+	pg.setPrices(LatestPrices{CurrentValidUntil: validUntil.Add(15 * time.Minute)})
+}
+
+func TestPriceGetterReloadsPublishedPrices(t *testing.T) {
+	ctx := context.Background()
+	db := store.NewMemory()
+	validUntil := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+	setPrices := func(lp LatestPrices) {
+		blob, err := json.Marshal(lp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(ctx, PriceRedisKey, string(blob)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setPrices(LatestPrices{CurrentValidUntil: validUntil})
+	pg, err := NewPriceGetter(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pg.Start()
+	defer pg.Stop()
+	updates, stop := pg.Listen()
+	defer stop()
+
+	setPrices(LatestPrices{CurrentValidUntil: validUntil.Add(5 * time.Minute)})
+	if err := db.Publish(ctx, PriceUpdateChannel, "{}"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case lp := <-updates:
+		if !lp.CurrentValidUntil.Equal(validUntil.Add(5 * time.Minute)) {
+			t.Fatalf("update valid until %s, want the published prices", lp.CurrentValidUntil)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the published prices to be reloaded")
+	}
+}
@@ -3,51 +3,107 @@ package pricingbyservice
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/fln/pprotect"
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/discovery/metrics"
+	"github.com/mysteriumnetwork/discovery/price/store"
 	"github.com/mysteriumnetwork/payments/v3/units"
 )
 
 const PriceRedisKey = "DISCOVERY_CURRENT_PRICE_BY_SERVICE"
 
+// PriceUpdateChannel is the redis channel a PriceUpdateEvent is published to on each price update.
+const PriceUpdateChannel = "DISCOVERY_PRICE_UPDATES"
+
+// PriceUpdateEvent tells the prices stored in PriceRedisKey were updated.
+type PriceUpdateEvent struct {
+	Time              time.Time `json:"time"`
+	CurrentValidUntil time.Time `json:"current_valid_until"`
+}
+
+// DefaultPriceLifetime is how long the generated prices are valid for.
+const DefaultPriceLifetime = time.Minute * 5
+
 type Bound struct {
 	Min, Max float64
 }
 
 type FiatPriceAPI interface {
-	MystUSD() float64
+	MystRates() map[string]float64
 }
 
 type PriceUpdater struct {
 	priceAPI      FiatPriceAPI
 	demandIndexes CountryDemandIndexProvider
+	supply        CountrySupplyProvider
+	boosts        DemandBoostStorage
 	priceLifetime time.Duration
 	mystBound     Bound
-	db            redis.UniversalClient
+	db            store.Store
+	history       *PriceHistoryStorage
+	leader        Leader
+	now           func() time.Time
 
 	lock        sync.Mutex
 	lp          LatestPrices
 	cfgProvider ConfigProvider
+	mystSamples []float64
+	// samplesCurrency is the currency of the MYST rate samples.
+	samplesCurrency string
+	// leading is set once this replica updated the prices as the leader.
+	leading bool
 
 	stop chan struct{}
 	once sync.Once
 }
 
+// PricerOption configures an optional dependency of the PriceUpdater.
+type PricerOption func(*PriceUpdater)
+
+// WithSupplyProvider makes the demand boost curves use the supply of each country.
+func WithSupplyProvider(supply CountrySupplyProvider) PricerOption {
+	return func(p *PriceUpdater) {
+		p.supply = supply
+	}
+}
+
+// DemandBoostStorage keeps the demand boost applied by the last price update.
+type DemandBoostStorage interface {
+	StoreBoost(ctx context.Context, boost DemandBoost) error
+}
+
+// WithDemandBoostStorage stores the demand boost applied by each price update.
+func WithDemandBoostStorage(boosts DemandBoostStorage) PricerOption {
+	return func(p *PriceUpdater) {
+		p.boosts = boosts
+	}
+}
+
+// WithLeader makes the PriceUpdater update the prices only while leading. Replicas
+// standing by keep up with the prices of the leader to rotate them on take over.
+func WithLeader(leader Leader) PricerOption {
+	return func(p *PriceUpdater) {
+		p.leader = leader
+	}
+}
+
 func NewPricer(
 	cfgProvider ConfigProvider,
 	priceAPI FiatPriceAPI,
 	demandIndexes CountryDemandIndexProvider,
 	priceLifetime time.Duration,
 	sensibleMystBound Bound,
-	db redis.UniversalClient,
+	db store.Store,
+	history *PriceHistoryStorage,
+	opts ...PricerOption,
 ) (*PriceUpdater, error) {
 	pricer := &PriceUpdater{
 		cfgProvider:   cfgProvider,
@@ -57,12 +113,24 @@ func NewPricer(
 		mystBound:     sensibleMystBound,
 		stop:          make(chan struct{}),
 		db:            db,
+		history:       history,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(pricer)
 	}
 
-	go pricer.schedulePriceUpdate(priceLifetime)
 	if err := pricer.threadSafePriceUpdate(); err != nil {
 		return nil, err
 	}
+	if pricer.leading {
+		// the first update already covers the election preceding it
+		select {
+		case <-pricer.elected():
+		default:
+		}
+	}
+	go pricer.schedulePriceUpdate(priceLifetime)
 	return pricer, nil
 }
 
@@ -74,92 +142,276 @@ func (p *PriceUpdater) schedulePriceUpdate(priceLifetime time.Duration) {
 			log.Info().Msg("price update by service stopped")
 			return
 		case <-time.After(priceLifetime):
-			pprotect.CallLoop(func() {
-				err := p.threadSafePriceUpdate()
-				if err != nil {
-					log.Err(err).Msg("failed to update prices by service")
-				}
-			}, time.Second, func(val interface{}, stack []byte) {
-				log.Warn().Msg("panic on scheduled price update by service: " + fmt.Sprint(val))
-			})
+			p.protectedPriceUpdate()
+		case <-p.elected():
+			// update right away on take over instead of waiting for the schedule
+			p.protectedPriceUpdate()
+		}
+	}
+}
+
+func (p *PriceUpdater) protectedPriceUpdate() {
+	pprotect.CallLoop(func() {
+		err := p.threadSafePriceUpdate()
+		if err != nil {
+			log.Err(err).Msg("failed to update prices by service")
 		}
+	}, time.Second, func(val interface{}, stack []byte) {
+		log.Warn().Msg("panic on scheduled price update by service: " + fmt.Sprint(val))
+	})
+}
+
+// elected returns nil, blocking forever, without leader election.
+func (p *PriceUpdater) elected() <-chan struct{} {
+	if p.leader == nil {
+		return nil
 	}
+	return p.leader.Elected()
 }
 
 func (p *PriceUpdater) threadSafePriceUpdate() error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	return p.updatePrices()
-}
+	if p.leader == nil {
+		return p.updatePrices()
+	}
 
-func (p *PriceUpdater) updatePrices() error {
-	mystUSD, err := p.fetchMystPrice()
-	if err != nil {
+	if !p.leader.IsLeader() {
+		p.leading = false
+		return p.loadPrices()
+	}
+	if !p.leading {
+		// the previous leader may have updated the prices since the last load
+		if err := p.loadPrices(); err != nil {
+			return err
+		}
+	}
+	if err := p.updatePrices(); err != nil {
 		return err
 	}
+	p.leading = true
+	return nil
+}
 
-	cfg, err := p.cfgProvider.Get()
+// loadPrices replaces the prices with the ones stored by the leader.
+func (p *PriceUpdater) loadPrices() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	blob, err := p.db.Get(ctx, PriceRedisKey)
 	if err != nil {
-		return err
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not load prices of the leader: %w", err)
+	}
+
+	var lp LatestPrices
+	if err := json.Unmarshal([]byte(blob), &lp); err != nil {
+		return fmt.Errorf("could not decode prices of the leader: %w", err)
 	}
+	p.lp = lp
+	return nil
+}
 
+func (p *PriceUpdater) updatePrices() error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
-	countryDemandIndexes, err := p.demandIndexes.DemandIndexes(ctx)
+
+	generated, err := p.generatePrices(ctx)
 	if err != nil {
 		return err
 	}
-	countryMultipliers := DemandBoostMultipliers(cfg, countryDemandIndexes)
-	countryServiceMultipliers := DemandBoostServiceMultipliers(cfg, countryDemandIndexes)
-	if countryMultipliers != nil && updateCountryModifiers(&cfg, countryMultipliers) {
-		if err := p.cfgProvider.Update(cfg); err != nil {
-			return fmt.Errorf("update country modifiers: %w", err)
-		}
-	}
-
-	p.lp = p.generateNewLatestPrice(mystUSD, cfg, countryServiceMultipliers)
+	p.lp = generated.lp
 
 	marshalled, err := json.Marshal(p.lp)
 	if err != nil {
 		return err
 	}
 
-	err = p.db.Set(ctx, PriceRedisKey, string(marshalled), 0).Err()
+	err = p.db.Set(ctx, PriceRedisKey, string(marshalled))
 	if err != nil {
 		return err
 	}
 
+	if p.history != nil {
+		if err := p.history.Store(ctx, NewPriceSnapshot(p.currentTime(), generated.mystRates, p.lp)); err != nil {
+			log.Err(err).Msg("failed to store price history")
+		}
+	}
+
+	// subscribers fall back to reloading the prices once they expire
+	if err := p.publishUpdate(ctx); err != nil {
+		log.Err(err).Msg("failed to publish price update")
+	}
+
 	p.submitMetrics()
 
 	log.Info().Msgf("price update complete by service")
 	return nil
 }
 
-func DemandBoostMultipliers(cfg Config, demandIndexes map[ISO3166CountryCode]float64) map[ISO3166CountryCode]float64 {
+func (p *PriceUpdater) publishUpdate(ctx context.Context) error {
+	event, err := json.Marshal(PriceUpdateEvent{
+		Time:              p.currentTime(),
+		CurrentValidUntil: p.lp.CurrentValidUntil,
+	})
+	if err != nil {
+		return err
+	}
+	return p.db.Publish(ctx, PriceUpdateChannel, string(event))
+}
+
+// generatedPrices are the prices of a single update along with the inputs they were generated from.
+type generatedPrices struct {
+	lp        LatestPrices
+	mystRates map[string]float64
+	boost     DemandBoost
+	clamps    []PriceClamp
+}
+
+// generatePrices generates the prices replacing the current ones without storing them.
+func (p *PriceUpdater) generatePrices(ctx context.Context) (generatedPrices, error) {
+	mystRates, err := p.fetchMystRates()
+	if err != nil {
+		return generatedPrices{}, err
+	}
+
+	cfg, err := p.cfgProvider.Get()
+	if err != nil {
+		return generatedPrices{}, err
+	}
+	// checked before storing the boost or updating the config so a failing update has no side effects
+	mystRate, ok := mystRates[cfg.Currency()]
+	if !ok || mystRate <= 0 {
+		return generatedPrices{}, fmt.Errorf("no MYST/%s rate available", cfg.Currency())
+	}
+
+	countryDemandIndexes, err := p.demandIndexes.DemandIndexes(ctx)
+	if err != nil {
+		return generatedPrices{}, err
+	}
+	countrySupply := p.countrySupply(ctx)
+	countryMultipliers := DemandBoostMultipliers(cfg, countryDemandIndexes, countrySupply)
+	countryServiceMultipliers := DemandBoostServiceMultipliers(cfg, countryDemandIndexes, countrySupply)
+	boost := DemandBoost{
+		Time:          p.currentTime(),
+		DemandIndexes: countryDemandIndexes,
+		Supply:        countrySupply,
+		Multipliers:   countryServiceMultipliers,
+	}
+	if p.boosts != nil {
+		if err := p.boosts.StoreBoost(ctx, boost); err != nil {
+			log.Err(err).Msg("failed to store demand boost")
+		}
+	}
+	// only the country modifiers are written so the config edited meanwhile is not reverted
+	if countryMultipliers != nil && updateCountryModifiers(&cfg, countryMultipliers) {
+		if err := p.cfgProvider.UpdateCountryModifiers(countryMultipliers, ConfigChange{Source: ConfigSourceDemandBoost}); err != nil {
+			return generatedPrices{}, fmt.Errorf("update country modifiers: %w", err)
+		}
+	}
+
+	mystRate = p.smoothMystPrice(mystRate, cfg)
+	mystRates[cfg.Currency()] = mystRate
+
+	newLP := p.generateNewLatestPrice(mystRate, cfg, countryServiceMultipliers)
+	var clamps []PriceClamp
+	if p.lp.isInitialized() {
+		clamps = clampPriceChanges(&newLP, cfg.maxPriceChange())
+		p.reportClamps(clamps, cfg.maxPriceChange())
+	}
+	withFiatEquivalents(&newLP, mystRates, cfg.FiatCurrencies)
+
+	return generatedPrices{
+		lp:        newLP,
+		mystRates: mystRates,
+		boost:     boost,
+		clamps:    clamps,
+	}, nil
+}
+
+// countrySupply returns the supply of each country, nil when unknown. The demand boost
+// falls back to the target demand indexes without it, so failures are not fatal.
+func (p *PriceUpdater) countrySupply(ctx context.Context) map[ISO3166CountryCode]float64 {
+	if p.supply == nil {
+		return nil
+	}
+
+	supply, err := p.supply.CountrySupply(ctx)
+	if err != nil {
+		log.Err(err).Msg("failed to load country supply, boosting by the target demand indexes")
+		return nil
+	}
+	return supply
+}
+
+// smoothMystPrice records the MYST rate in the base currency and returns the smoothed one when smoothing is configured.
+// The samples start over when the base currency changes.
+func (p *PriceUpdater) smoothMystPrice(mystRate float64, cfg Config) float64 {
+	if p.samplesCurrency != cfg.Currency() {
+		p.mystSamples = nil
+		p.samplesCurrency = cfg.Currency()
+	}
+	p.mystSamples = append(p.mystSamples, mystRate)
+	if len(p.mystSamples) > maxSmoothingSamples {
+		p.mystSamples = p.mystSamples[len(p.mystSamples)-maxSmoothingSamples:]
+	}
+	metrics.MystRate.WithLabelValues("market", cfg.Currency()).Set(mystRate)
+
+	smoothed := mystRate
+	if cfg.Guardrails != nil && cfg.Guardrails.MystSmoothing != nil {
+		smoothed = cfg.Guardrails.MystSmoothing.smooth(p.mystSamples)
+		if smoothed != mystRate {
+			log.Info().Msgf("myst price smoothed with %s from %.6f to %.6f", cfg.Guardrails.MystSmoothing.Method, mystRate, smoothed)
+		}
+	}
+	metrics.MystRate.WithLabelValues("smoothed", cfg.Currency()).Set(smoothed)
+
+	return smoothed
+}
+
+func (p *PriceUpdater) reportClamps(clamps []PriceClamp, maxChange float64) {
+	if len(clamps) == 0 {
+		return
+	}
+
+	for _, c := range clamps {
+		metrics.PriceClamps.WithLabelValues(c.NodeType, string(c.ServiceType), c.PriceType, c.Direction).Inc()
+		log.Debug().Msgf("clamped %s %s %s price of %q on %s", c.NodeType, c.ServiceType, c.PriceType, c.Country, c.Direction)
+	}
+	log.Warn().Msgf("clamped %d prices changing more than %.2f%% in a single update", len(clamps), maxChange*100)
+}
+
+// DemandBoostMultipliers calculates the price multiplier of each demand boosted country.
+// The supply is the number of providers of each country, nil when unknown.
+func DemandBoostMultipliers(cfg Config, demandIndexes, supply map[ISO3166CountryCode]float64) map[ISO3166CountryCode]float64 {
 	if cfg.DemandBoost == nil {
 		return nil
 	}
 
+	shares := supplyShares(supply)
 	multipliers := make(map[ISO3166CountryCode]float64)
 	for country, boostCfg := range cfg.DemandBoost.Countries {
-		multipliers[country] = demandBoostMultiplier(boostCfg, demandIndexes[country])
+		multipliers[country] = demandBoostMultiplier(boostCfg, demandIndexes[country], shares, country)
 	}
 
 	return multipliers
 }
 
-func DemandBoostServiceMultipliers(cfg Config, demandIndexes map[ISO3166CountryCode]float64) map[ISO3166CountryCode]map[ServiceType]float64 {
+func DemandBoostServiceMultipliers(cfg Config, demandIndexes, supply map[ISO3166CountryCode]float64) map[ISO3166CountryCode]map[ServiceType]float64 {
 	if cfg.DemandBoost == nil {
 		return nil
 	}
 
+	shares := supplyShares(supply)
 	multipliers := make(map[ISO3166CountryCode]map[ServiceType]float64)
 	for country, boostCfg := range cfg.DemandBoost.Countries {
-		multiplier := demandBoostMultiplier(boostCfg, demandIndexes[country])
+		multiplier := demandBoostMultiplier(boostCfg, demandIndexes[country], shares, country)
 		serviceTypes := boostCfg.ServiceTypes
 		if len(serviceTypes) == 0 {
-			serviceTypes = allServiceTypes
+			serviceTypes = cfg.RegisteredServiceTypes()
 		}
 
 		multipliers[country] = make(map[ServiceType]float64, len(serviceTypes))
@@ -171,7 +423,15 @@ func DemandBoostServiceMultipliers(cfg Config, demandIndexes map[ISO3166CountryC
 	return multipliers
 }
 
-func demandBoostMultiplier(boostCfg DemandBoostCountryCfg, currentDemandIndex float64) float64 {
+func demandBoostMultiplier(boostCfg DemandBoostCountryCfg, currentDemandIndex float64, supplyShares map[ISO3166CountryCode]float64, country ISO3166CountryCode) float64 {
+	if boostCfg.Curve != nil && supplyShares != nil {
+		ratio := demandSupplyRatio(currentDemandIndex, supplyShares[country])
+		return 1 + boostCfg.Curve.bonusShare(ratio)*boostCfg.MaxBonus
+	}
+	if boostCfg.TargetDemandIndex <= 0 {
+		return 1
+	}
+
 	gapRatio := (boostCfg.TargetDemandIndex - currentDemandIndex) / boostCfg.TargetDemandIndex
 	if gapRatio < 0 {
 		gapRatio = 0
@@ -182,19 +442,33 @@ func demandBoostMultiplier(boostCfg DemandBoostCountryCfg, currentDemandIndex fl
 	return 1 + (gapRatio * boostCfg.MaxBonus)
 }
 
+// countryModifierTolerance is the relative change of the demand boost multipliers
+// below which the country modifiers are not updated, so the small changes of the
+// supply do not record a config version on every price update.
+const countryModifierTolerance = 0.01
+
+// updateCountryModifiers sets the country modifiers to the multipliers, unless they
+// are all within countryModifierTolerance of the current ones.
 func updateCountryModifiers(cfg *Config, multipliers map[ISO3166CountryCode]float64) bool {
 	modifiers := make(map[ISO3166CountryCode]Modifier, len(multipliers))
 	for country, multiplier := range multipliers {
 		modifiers[country] = Modifier{
 			Residential: multiplier,
 			Other:       multiplier,
+			Services:    cfg.CountryModifiers[country].Services,
+		}
+	}
+	// service modifiers are not managed by the demand boost, keep them as they are
+	for country, modifier := range cfg.CountryModifiers {
+		if _, ok := modifiers[country]; !ok && len(modifier.Services) > 0 {
+			modifiers[country] = Modifier{Residential: 1, Other: 1, Services: modifier.Services}
 		}
 	}
 
 	if len(cfg.CountryModifiers) == len(modifiers) {
 		equal := true
 		for country, modifier := range modifiers {
-			if cfg.CountryModifiers[country] != modifier {
+			if !cfg.CountryModifiers[country].close(modifier) {
 				equal = false
 				break
 			}
@@ -217,48 +491,31 @@ func (p *PriceUpdater) submitMetrics() {
 }
 
 func (p *PriceUpdater) submitPriceMetric(country string, price *PriceByType) {
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "wireguard", "per_gib").Set(price.Other.Wireguard.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "wireguard", "per_hour").Set(price.Other.Wireguard.PricePerHourHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "wireguard", "per_gib").Set(price.Residential.Wireguard.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "wireguard", "per_hour").Set(price.Residential.Wireguard.PricePerHourHumanReadable)
-
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "scraping", "per_gib").Set(price.Other.Scraping.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "scraping", "per_hour").Set(price.Other.Scraping.PricePerHourHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "scraping", "per_gib").Set(price.Residential.Scraping.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "scraping", "per_hour").Set(price.Residential.Scraping.PricePerHourHumanReadable)
-
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "quic_scraping", "per_gib").Set(price.Other.QUICScraping.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "quic_scraping", "per_hour").Set(price.Other.QUICScraping.PricePerHourHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "quic_scraping", "per_gib").Set(price.Residential.QUICScraping.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "quic_scraping", "per_hour").Set(price.Residential.QUICScraping.PricePerHourHumanReadable)
-
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "data_transfer", "per_gib").Set(price.Other.DataTransfer.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "data_transfer", "per_hour").Set(price.Other.DataTransfer.PricePerHourHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "data_transfer", "per_gib").Set(price.Residential.DataTransfer.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "data_transfer", "per_hour").Set(price.Residential.DataTransfer.PricePerHourHumanReadable)
-
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "dvpn", "per_gib").Set(price.Other.DVPN.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "dvpn", "per_hour").Set(price.Other.DVPN.PricePerHourHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "dvpn", "per_gib").Set(price.Residential.DVPN.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "dvpn", "per_hour").Set(price.Residential.DVPN.PricePerHourHumanReadable)
-
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "monitoring", "per_gib").Set(price.Other.Monitoring.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "other", "monitoring", "per_hour").Set(price.Other.Monitoring.PricePerHourHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "monitoring", "per_gib").Set(price.Residential.Monitoring.PricePerGiBHumanReadable)
-	metrics.CurrentPriceByCountry.WithLabelValues(country, "residential", "monitoring", "per_hour").Set(price.Residential.Monitoring.PricePerHourHumanReadable)
-
+	for _, nodeType := range []string{NodeTypeOther, NodeTypeResidential} {
+		for serviceType, servicePrice := range price.ForNodeType(nodeType == NodeTypeResidential) {
+			metrics.CurrentPriceByCountry.WithLabelValues(country, nodeType, string(serviceType), "per_gib").Set(servicePrice.PricePerGiBHumanReadable)
+			metrics.CurrentPriceByCountry.WithLabelValues(country, nodeType, string(serviceType), "per_hour").Set(servicePrice.PricePerHourHumanReadable)
+		}
+	}
 }
 
 func (p *PriceUpdater) Stop() {
 	p.once.Do(func() { close(p.stop) })
 }
 
-func (p *PriceUpdater) generateNewLatestPrice(mystUSD float64, cfg Config, multipliers map[ISO3166CountryCode]map[ServiceType]float64) LatestPrices {
-	tm := time.Now().UTC()
+func (p *PriceUpdater) currentTime() time.Time {
+	if p.now == nil {
+		return time.Now().UTC()
+	}
+	return p.now().UTC()
+}
+
+func (p *PriceUpdater) generateNewLatestPrice(mystRate float64, cfg Config, multipliers map[ISO3166CountryCode]map[ServiceType]float64) LatestPrices {
+	tm := p.currentTime()
 
 	newLP := LatestPrices{
-		Defaults:          p.generateNewDefaults(mystUSD, cfg),
-		PerCountry:        p.generateNewPerCountryWithOptionalServiceMultipliers(mystUSD, cfg, multipliers),
+		Defaults:          p.generateNewDefaults(mystRate, cfg),
+		PerCountry:        p.generateNewPerCountryWithOptionalServiceMultipliers(mystRate, cfg, multipliers),
 		CurrentValidUntil: tm.Add(p.priceLifetime),
 	}
 
@@ -272,85 +529,16 @@ func (p *PriceUpdater) generateNewLatestPrice(mystUSD float64, cfg Config, multi
 	return newLP
 }
 
-func (p *PriceUpdater) generateNewDefaults(mystUSD float64, cfg Config) *PriceHistory {
+func (p *PriceUpdater) generateNewDefaults(mystRate float64, cfg Config) *PriceHistory {
+	tm := p.currentTime()
+	noModifier := func(ISO3166CountryCode, ServiceType) Modifier {
+		return Modifier{Residential: 1, Other: 1}
+	}
+
 	ph := &PriceHistory{
 		Current: &PriceByType{
-			Residential: &PriceByServiceType{
-				Wireguard: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Wireguard.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Wireguard.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Wireguard.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Wireguard.PricePerGiB, 1),
-				},
-				Scraping: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Scraping.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Scraping.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Scraping.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Scraping.PricePerGiB, 1),
-				},
-				QUICScraping: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.QUICScraping.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.QUICScraping.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.QUICScraping.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.QUICScraping.PricePerGiB, 1),
-				},
-				DataTransfer: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.DataTransfer.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.DataTransfer.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.DataTransfer.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.DataTransfer.PricePerGiB, 1),
-				},
-				DVPN: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.DVPN.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.DVPN.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.DVPN.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.DVPN.PricePerGiB, 1),
-				},
-				Monitoring: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Monitoring.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Monitoring.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Monitoring.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Monitoring.PricePerGiB, 1),
-				},
-			},
-			Other: &PriceByServiceType{
-				Wireguard: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Wireguard.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Wireguard.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Wireguard.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Wireguard.PricePerGiB, 1),
-				},
-				Scraping: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Scraping.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Scraping.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Scraping.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Scraping.PricePerGiB, 1),
-				},
-				QUICScraping: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.QUICScraping.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.QUICScraping.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.QUICScraping.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.QUICScraping.PricePerGiB, 1),
-				},
-				DataTransfer: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.DataTransfer.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.DataTransfer.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.DataTransfer.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.DataTransfer.PricePerGiB, 1),
-				},
-				DVPN: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.DVPN.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.DVPN.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.DVPN.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.DVPN.PricePerGiB, 1),
-				},
-				Monitoring: Price{
-					PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Monitoring.PricePerHour, 1),
-					PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Monitoring.PricePerHour, 1),
-					PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Monitoring.PricePerGiB, 1),
-					PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Monitoring.PricePerGiB, 1),
-				},
-			},
+			Residential: generateCountryPrices(mystRate, cfg, "", true, noModifier, tm),
+			Other:       generateCountryPrices(mystRate, cfg, "", false, noModifier, tm),
 		},
 	}
 	if !p.lp.isInitialized() {
@@ -361,8 +549,8 @@ func (p *PriceUpdater) generateNewDefaults(mystUSD float64, cfg Config) *PriceHi
 	return ph
 }
 
-func (p *PriceUpdater) generateNewPerCountry(mystUSD float64, cfg Config) map[string]*PriceHistory {
-	return p.generateNewPerCountryWithModifier(mystUSD, cfg, func(country ISO3166CountryCode, _ ServiceType) Modifier {
+func (p *PriceUpdater) generateNewPerCountry(mystRate float64, cfg Config) map[string]*PriceHistory {
+	return p.generateNewPerCountryWithModifier(mystRate, cfg, func(country ISO3166CountryCode, _ ServiceType) Modifier {
 		modifier, ok := cfg.CountryModifiers[country]
 		if !ok {
 			return Modifier{Residential: 1, Other: 1}
@@ -371,15 +559,15 @@ func (p *PriceUpdater) generateNewPerCountry(mystUSD float64, cfg Config) map[st
 	})
 }
 
-func (p *PriceUpdater) generateNewPerCountryWithOptionalServiceMultipliers(mystUSD float64, cfg Config, multipliers map[ISO3166CountryCode]map[ServiceType]float64) map[string]*PriceHistory {
+func (p *PriceUpdater) generateNewPerCountryWithOptionalServiceMultipliers(mystRate float64, cfg Config, multipliers map[ISO3166CountryCode]map[ServiceType]float64) map[string]*PriceHistory {
 	if multipliers == nil {
-		return p.generateNewPerCountry(mystUSD, cfg)
+		return p.generateNewPerCountry(mystRate, cfg)
 	}
-	return p.generateNewPerCountryWithServiceMultipliers(mystUSD, cfg, multipliers)
+	return p.generateNewPerCountryWithServiceMultipliers(mystRate, cfg, multipliers)
 }
 
-func (p *PriceUpdater) generateNewPerCountryWithMultipliers(mystUSD float64, cfg Config, multipliers map[ISO3166CountryCode]float64) map[string]*PriceHistory {
-	return p.generateNewPerCountryWithModifier(mystUSD, cfg, func(country ISO3166CountryCode, _ ServiceType) Modifier {
+func (p *PriceUpdater) generateNewPerCountryWithMultipliers(mystRate float64, cfg Config, multipliers map[ISO3166CountryCode]float64) map[string]*PriceHistory {
+	return p.generateNewPerCountryWithModifier(mystRate, cfg, func(country ISO3166CountryCode, _ ServiceType) Modifier {
 		multiplier, ok := multipliers[country]
 		if !ok {
 			multiplier = 1
@@ -388,8 +576,8 @@ func (p *PriceUpdater) generateNewPerCountryWithMultipliers(mystUSD float64, cfg
 	})
 }
 
-func (p *PriceUpdater) generateNewPerCountryWithServiceMultipliers(mystUSD float64, cfg Config, multipliers map[ISO3166CountryCode]map[ServiceType]float64) map[string]*PriceHistory {
-	return p.generateNewPerCountryWithModifier(mystUSD, cfg, func(country ISO3166CountryCode, serviceType ServiceType) Modifier {
+func (p *PriceUpdater) generateNewPerCountryWithServiceMultipliers(mystRate float64, cfg Config, multipliers map[ISO3166CountryCode]map[ServiceType]float64) map[string]*PriceHistory {
+	return p.generateNewPerCountryWithModifier(mystRate, cfg, func(country ISO3166CountryCode, serviceType ServiceType) Modifier {
 		multiplier := float64(1)
 		if countryMultipliers, ok := multipliers[country]; ok {
 			if serviceMultiplier, ok := countryMultipliers[serviceType]; ok {
@@ -400,94 +588,15 @@ func (p *PriceUpdater) generateNewPerCountryWithServiceMultipliers(mystUSD float
 	})
 }
 
-func (p *PriceUpdater) generateNewPerCountryWithModifier(mystUSD float64, cfg Config, modifierFor func(ISO3166CountryCode, ServiceType) Modifier) map[string]*PriceHistory {
+func (p *PriceUpdater) generateNewPerCountryWithModifier(mystRate float64, cfg Config, modifierFor func(ISO3166CountryCode, ServiceType) Modifier) map[string]*PriceHistory {
+	tm := p.currentTime()
+
 	countries := make(map[string]*PriceHistory)
 	for countryCode := range CountryCodeToName {
-		wireguardMod := modifierFor(countryCode, ServiceTypeWireguard)
-		scrapingMod := modifierFor(countryCode, ServiceTypeScraping)
-		quicScrapingMod := modifierFor(countryCode, ServiceTypeQUICScraping)
-		dataTransferMod := modifierFor(countryCode, ServiceTypeDataTransfer)
-		dvpnMod := modifierFor(countryCode, ServiceTypeDVPN)
-		monitoringMod := modifierFor(countryCode, ServiceTypeMonitoring)
-
 		ph := &PriceHistory{
 			Current: &PriceByType{
-				Residential: &PriceByServiceType{
-					Wireguard: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Wireguard.PricePerHour, wireguardMod.Residential),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Wireguard.PricePerHour, wireguardMod.Residential),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Wireguard.PricePerGiB, wireguardMod.Residential),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Wireguard.PricePerGiB, wireguardMod.Residential),
-					},
-					Scraping: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Scraping.PricePerHour, scrapingMod.Residential),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Scraping.PricePerHour, scrapingMod.Residential),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Scraping.PricePerGiB, scrapingMod.Residential),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Scraping.PricePerGiB, scrapingMod.Residential),
-					},
-					QUICScraping: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.QUICScraping.PricePerHour, quicScrapingMod.Residential),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.QUICScraping.PricePerHour, quicScrapingMod.Residential),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.QUICScraping.PricePerGiB, quicScrapingMod.Residential),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.QUICScraping.PricePerGiB, quicScrapingMod.Residential),
-					},
-					DataTransfer: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.DataTransfer.PricePerHour, dataTransferMod.Residential),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.DataTransfer.PricePerHour, dataTransferMod.Residential),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.DataTransfer.PricePerGiB, dataTransferMod.Residential),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.DataTransfer.PricePerGiB, dataTransferMod.Residential),
-					},
-					DVPN: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.DVPN.PricePerHour, dvpnMod.Residential),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.DVPN.PricePerHour, dvpnMod.Residential),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.DVPN.PricePerGiB, dvpnMod.Residential),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.DVPN.PricePerGiB, dvpnMod.Residential),
-					},
-					Monitoring: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Monitoring.PricePerHour, monitoringMod.Residential),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Monitoring.PricePerHour, monitoringMod.Residential),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Residential.Monitoring.PricePerGiB, monitoringMod.Residential),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Residential.Monitoring.PricePerGiB, monitoringMod.Residential),
-					},
-				},
-				Other: &PriceByServiceType{
-					Wireguard: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Wireguard.PricePerHour, wireguardMod.Other),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Wireguard.PricePerHour, wireguardMod.Other),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Wireguard.PricePerGiB, wireguardMod.Other),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Wireguard.PricePerGiB, wireguardMod.Other),
-					},
-					Scraping: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Scraping.PricePerHour, scrapingMod.Other),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Scraping.PricePerHour, scrapingMod.Other),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Scraping.PricePerGiB, scrapingMod.Other),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Scraping.PricePerGiB, scrapingMod.Other),
-					},
-					QUICScraping: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.QUICScraping.PricePerHour, quicScrapingMod.Other),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.QUICScraping.PricePerHour, quicScrapingMod.Other),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.QUICScraping.PricePerGiB, quicScrapingMod.Other),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.QUICScraping.PricePerGiB, quicScrapingMod.Other),
-					},
-					DataTransfer: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.DataTransfer.PricePerHour, dataTransferMod.Other),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.DataTransfer.PricePerHour, dataTransferMod.Other),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.DataTransfer.PricePerGiB, dataTransferMod.Other),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.DataTransfer.PricePerGiB, dataTransferMod.Other),
-					},
-					DVPN: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.DVPN.PricePerHour, dvpnMod.Other),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.DVPN.PricePerHour, dvpnMod.Other),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.DVPN.PricePerGiB, dvpnMod.Other),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.DVPN.PricePerGiB, dvpnMod.Other),
-					},
-					Monitoring: Price{
-						PricePerHour:              calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Monitoring.PricePerHour, monitoringMod.Other),
-						PricePerHourHumanReadable: calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Monitoring.PricePerHour, monitoringMod.Other),
-						PricePerGiB:               calculatePriceMYST(mystUSD, cfg.BasePrices.Other.Monitoring.PricePerGiB, monitoringMod.Other),
-						PricePerGiBHumanReadable:  calculatePriceMystFloat(mystUSD, cfg.BasePrices.Other.Monitoring.PricePerGiB, monitoringMod.Other),
-					},
-				},
+				Residential: generateCountryPrices(mystRate, cfg, countryCode, true, modifierFor, tm),
+				Other:       generateCountryPrices(mystRate, cfg, countryCode, false, modifierFor, tm),
 			},
 		}
 
@@ -508,23 +617,71 @@ func (p *PriceUpdater) generateNewPerCountryWithModifier(mystUSD float64, cfg Co
 	return countries
 }
 
+// generateCountryPrices calculates the prices of residential or other nodes of a country,
+// an empty country stands for the defaults. The service modifiers of the country apply on top of its modifier, while active
+// campaigns apply on top of everything, absolute service prices included.
+func generateCountryPrices(mystRate float64, cfg Config, country ISO3166CountryCode, residential bool, modifierFor func(ISO3166CountryCode, ServiceType) Modifier, tm time.Time) PriceByServiceType {
+	base := cfg.BasePrices.Other
+	if residential {
+		base = cfg.BasePrices.Residential
+	}
+	serviceModifiers := cfg.CountryModifiers[country].Services
+
+	prices := make(PriceByServiceType, len(base))
+	for _, serviceType := range cfg.RegisteredServiceTypes() {
+		multiplier := modifierFor(country, serviceType).forNodeType(residential)
+		campaign := campaignModifier(cfg.Campaigns, tm, country, serviceType).forNodeType(residential)
+		override := serviceModifiers[serviceType].forNodeType(residential)
+		prices[serviceType] = calculateCountryPrice(mystRate, base[serviceType], multiplier, override, campaign)
+	}
+	return prices
+}
+
+func calculateCountryPrice(mystRate float64, base PriceUSD, multiplier float64, override *PriceOverride, campaign float64) Price {
+	hourPrice, hourMultiplier := base.PricePerHour, multiplier*campaign
+	gibPrice, gibMultiplier := base.PricePerGiB, multiplier*campaign
+	if override != nil {
+		if override.Multiplier != nil {
+			hourMultiplier *= *override.Multiplier
+			gibMultiplier *= *override.Multiplier
+		}
+		if override.PricePerHour != nil {
+			hourPrice, hourMultiplier = *override.PricePerHour, campaign
+		}
+		if override.PricePerGiB != nil {
+			gibPrice, gibMultiplier = *override.PricePerGiB, campaign
+		}
+	}
+
+	return Price{
+		PricePerHour:              calculatePriceMYST(mystRate, hourPrice, hourMultiplier),
+		PricePerHourHumanReadable: calculatePriceMystFloat(mystRate, hourPrice, hourMultiplier),
+		PricePerGiB:               calculatePriceMYST(mystRate, gibPrice, gibMultiplier),
+		PricePerGiBHumanReadable:  calculatePriceMystFloat(mystRate, gibPrice, gibMultiplier),
+	}
+}
+
 // Take note that this is not 100% correct as we're rounding a bit due to accuracy issues with floats.
 // This, however, is not important here as the accuracy will be more than good enough to a few zeroes after the dot.
-func calculatePriceMYST(mystUSD, priceUSD, multiplier float64) *big.Int {
-	return units.FloatEthToBigIntWei((priceUSD / mystUSD) * multiplier)
+func calculatePriceMYST(mystRate, price, multiplier float64) *big.Int {
+	return units.FloatEthToBigIntWei((price / mystRate) * multiplier)
 }
 
-func calculatePriceMystFloat(mystUSD, priceUSD, multiplier float64) float64 {
-	return units.BigIntWeiToFloatEth(calculatePriceMYST(mystUSD, priceUSD, multiplier))
+func calculatePriceMystFloat(mystRate, price, multiplier float64) float64 {
+	return units.BigIntWeiToFloatEth(calculatePriceMYST(mystRate, price, multiplier))
 }
 
-func (p *PriceUpdater) fetchMystPrice() (float64, error) {
-	mystUSD := p.priceAPI.MystUSD()
-	if err := p.withinBounds(mystUSD); err != nil {
-		return 0, err
+// fetchMystRates returns the MYST rates by fiat currency. The USD one must be within the sensible bounds.
+func (p *PriceUpdater) fetchMystRates() (map[string]float64, error) {
+	mystRates := make(map[string]float64)
+	for currency, rate := range p.priceAPI.MystRates() {
+		mystRates[currency] = rate
+	}
+	if err := p.withinBounds(mystRates[DefaultBaseCurrency]); err != nil {
+		return nil, err
 	}
 
-	return mystUSD, nil
+	return mystRates, nil
 }
 
 // withinBounds used to filter out any possible nonsense that the external pricing services might return.
@@ -559,23 +716,53 @@ func (lp *LatestPrices) isValid() bool {
 	return lp.CurrentValidUntil.UTC().After(time.Now().UTC())
 }
 
+// ForCountry returns the prices of the given country, falling back to the defaults
+// when the country has no prices of its own.
+func (lp LatestPrices) ForCountry(country string) *PriceHistory {
+	if ph, ok := lp.PerCountry[country]; ok && ph != nil {
+		return ph
+	}
+	return lp.Defaults
+}
+
 type PriceHistory struct {
 	Current  *PriceByType `json:"current"`
 	Previous *PriceByType `json:"previous"`
 }
 
 type PriceByType struct {
-	Residential *PriceByServiceType `json:"residential"`
-	Other       *PriceByServiceType `json:"other"`
+	Residential PriceByServiceType `json:"residential"`
+	Other       PriceByServiceType `json:"other"`
+}
+
+// ForNodeType returns the prices of residential or other nodes.
+func (p *PriceByType) ForNodeType(residential bool) PriceByServiceType {
+	if p == nil {
+		return nil
+	}
+	if residential {
+		return p.Residential
+	}
+	return p.Other
 }
 
-type PriceByServiceType struct {
-	Wireguard    Price `json:"wireguard"`
-	Scraping     Price `json:"scraping"`
-	QUICScraping Price `json:"quic_scraping"`
-	DataTransfer Price `json:"data_transfer"`
-	DVPN         Price `json:"dvpn"`
-	Monitoring   Price `json:"monitoring"`
+// PriceByServiceType holds the prices of each priced service type.
+type PriceByServiceType map[ServiceType]Price
+
+// ForServiceType returns the price of the given service type.
+func (p PriceByServiceType) ForServiceType(serviceType ServiceType) (Price, bool) {
+	price, ok := p[serviceType]
+	return price, ok
+}
+
+// ServiceTypes returns the priced service types in alphabetical order.
+func (p PriceByServiceType) ServiceTypes() []ServiceType {
+	res := make([]ServiceType, 0, len(p))
+	for serviceType := range p {
+		res = append(res, serviceType)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
 }
 
 type Price struct {
@@ -583,4 +770,6 @@ type Price struct {
 	PricePerHourHumanReadable float64  `json:"price_per_hour_human_readable" swaggertype:"number"`
 	PricePerGiB               *big.Int `json:"price_per_gib" swaggertype:"integer"`
 	PricePerGiBHumanReadable  float64  `json:"price_per_gib_human_readable" swaggertype:"number"`
+	// Fiat holds the equivalents of the price in the fiat currencies of the config.
+	Fiat map[string]FiatPrice `json:"fiat,omitempty"`
 }
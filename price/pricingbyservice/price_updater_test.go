@@ -1,6 +1,8 @@
 package pricingbyservice
 
 import (
+	"context"
+	"encoding/json"
 	"math"
 	"math/big"
 	"reflect"
@@ -35,6 +37,15 @@ func TestUpdateCountryModifiers(t *testing.T) {
 	if updateCountryModifiers(&cfg, map[ISO3166CountryCode]float64{"US": 1.5, "DE": 1}) {
 		t.Fatal("expected unchanged country modifiers not to trigger an update")
 	}
+	if updateCountryModifiers(&cfg, map[ISO3166CountryCode]float64{"US": 1.505, "DE": 0.995}) {
+		t.Fatal("expected changes within the tolerance not to trigger an update")
+	}
+	if cfg.CountryModifiers["US"].Residential != 1.5 {
+		t.Fatalf("expected the modifiers to be kept within the tolerance, got %+v", cfg.CountryModifiers["US"])
+	}
+	if !updateCountryModifiers(&cfg, map[ISO3166CountryCode]float64{"US": 1.53, "DE": 1}) {
+		t.Fatal("expected a change beyond the tolerance to trigger an update")
+	}
 }
 
 func TestDemandBoostMultipliers(t *testing.T) {
@@ -54,7 +65,7 @@ func TestDemandBoostMultipliers(t *testing.T) {
 		"DE": 0.1,
 		"US": 0.2,
 		"GB": 0.025,
-	})
+	}, nil)
 
 	want := map[ISO3166CountryCode]float64{"PL": 1.4514, "DE": 1, "US": 1, "GB": 1.5}
 	for country, wantMultiplier := range want {
@@ -73,7 +84,7 @@ func TestDemandBoostMultipliersDefaultsMissingDemandIndexToMaxBoost(t *testing.T
 		},
 	}
 
-	got := DemandBoostMultipliers(cfg, nil)
+	got := DemandBoostMultipliers(cfg, nil, nil)
 
 	if got["PL"] != 1.5 {
 		t.Fatalf("missing demand index multiplier = %v, want 1.5", got["PL"])
@@ -97,7 +108,7 @@ func TestDemandBoostServiceMultipliers(t *testing.T) {
 	got := DemandBoostServiceMultipliers(cfg, map[ISO3166CountryCode]float64{
 		"PL": 0,
 		"GB": 0,
-	})
+	}, nil)
 
 	if got["PL"][ServiceTypeDVPN] != 1.5 || got["PL"][ServiceTypeWireguard] != 1.5 {
 		t.Fatalf("expected PL selected service multipliers to be boosted, got %#v", got["PL"])
@@ -105,7 +116,7 @@ func TestDemandBoostServiceMultipliers(t *testing.T) {
 	if _, ok := got["PL"][ServiceTypeScraping]; ok {
 		t.Fatalf("expected PL scraping to be omitted, got %#v", got["PL"])
 	}
-	if len(got["GB"]) != len(allServiceTypes) {
+	if len(got["GB"]) != len(DefaultServiceTypes) {
 		t.Fatalf("expected empty GB service list to include all services, got %#v", got["GB"])
 	}
 }
@@ -114,13 +125,13 @@ func TestGenerateNewPerCountryWithServiceMultipliers(t *testing.T) {
 	price := PriceUSD{PricePerHour: 1, PricePerGiB: 2}
 	cfg := Config{
 		BasePrices: PriceByTypeUSD{
-			Residential: &PriceByServiceTypeUSD{
-				Wireguard: price, Scraping: price, QUICScraping: price,
-				DataTransfer: price, DVPN: price, Monitoring: price,
+			Residential: PriceByServiceTypeUSD{
+				ServiceTypeWireguard: price, ServiceTypeScraping: price, ServiceTypeQUICScraping: price,
+				ServiceTypeDataTransfer: price, ServiceTypeDVPN: price, ServiceTypeMonitoring: price,
 			},
-			Other: &PriceByServiceTypeUSD{
-				Wireguard: price, Scraping: price, QUICScraping: price,
-				DataTransfer: price, DVPN: price, Monitoring: price,
+			Other: PriceByServiceTypeUSD{
+				ServiceTypeWireguard: price, ServiceTypeScraping: price, ServiceTypeQUICScraping: price,
+				ServiceTypeDataTransfer: price, ServiceTypeDVPN: price, ServiceTypeMonitoring: price,
 			},
 		},
 	}
@@ -132,19 +143,19 @@ func TestGenerateNewPerCountryWithServiceMultipliers(t *testing.T) {
 		},
 	})
 
-	if prices["PL"].Current.Residential.DVPN.PricePerHourHumanReadable != 1.5 {
+	if prices["PL"].Current.Residential[ServiceTypeDVPN].PricePerHourHumanReadable != 1.5 {
 		t.Fatalf("expected PL DVPN to be boosted")
 	}
-	if prices["PL"].Current.Residential.Wireguard.PricePerHourHumanReadable != 1 {
+	if prices["PL"].Current.Residential[ServiceTypeWireguard].PricePerHourHumanReadable != 1 {
 		t.Fatalf("expected PL wireguard to remain unboosted")
 	}
-	if prices["PL"].Current.Other.DVPN.PricePerHourHumanReadable != 1.5 {
+	if prices["PL"].Current.Other[ServiceTypeDVPN].PricePerHourHumanReadable != 1.5 {
 		t.Fatalf("expected PL other DVPN to be boosted")
 	}
 }
 
 func TestDemandBoostMultipliersDisabledWhenConfigMissing(t *testing.T) {
-	if got := DemandBoostMultipliers(Config{}, nil); got != nil {
+	if got := DemandBoostMultipliers(Config{}, nil, nil); got != nil {
 		t.Fatalf("demandBoostMultipliers() = %#v, want nil", got)
 	}
 }
@@ -291,54 +302,54 @@ func TestPricer_generateNewDefaults(t *testing.T) {
 				lp: LatestPrices{},
 				cfg: Config{
 					BasePrices: PriceByTypeUSD{
-						Residential: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Residential: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
 						},
-						Other: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Other: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
@@ -351,76 +362,76 @@ func TestPricer_generateNewDefaults(t *testing.T) {
 			},
 			want: &PriceHistory{
 				Current: &PriceByType{
-					Residential: &PriceByServiceType{
-						Wireguard: Price{
+					Residential: PriceByServiceType{
+						ServiceTypeWireguard: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						Scraping: Price{
+						ServiceTypeScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						QUICScraping: Price{
+						ServiceTypeQUICScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						DataTransfer: Price{
+						ServiceTypeDataTransfer: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						DVPN: Price{
+						ServiceTypeDVPN: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						Monitoring: Price{
+						ServiceTypeMonitoring: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
 					},
-					Other: &PriceByServiceType{
-						Wireguard: Price{
+					Other: PriceByServiceType{
+						ServiceTypeWireguard: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						Scraping: Price{
+						ServiceTypeScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						QUICScraping: Price{
+						ServiceTypeQUICScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						DataTransfer: Price{
+						ServiceTypeDataTransfer: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						DVPN: Price{
+						ServiceTypeDVPN: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						Monitoring: Price{
+						ServiceTypeMonitoring: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
@@ -429,76 +440,76 @@ func TestPricer_generateNewDefaults(t *testing.T) {
 					},
 				},
 				Previous: &PriceByType{
-					Residential: &PriceByServiceType{
-						Wireguard: Price{
+					Residential: PriceByServiceType{
+						ServiceTypeWireguard: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						Scraping: Price{
+						ServiceTypeScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						QUICScraping: Price{
+						ServiceTypeQUICScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						DataTransfer: Price{
+						ServiceTypeDataTransfer: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						DVPN: Price{
+						ServiceTypeDVPN: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						Monitoring: Price{
+						ServiceTypeMonitoring: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
 					},
-					Other: &PriceByServiceType{
-						Wireguard: Price{
+					Other: PriceByServiceType{
+						ServiceTypeWireguard: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						Scraping: Price{
+						ServiceTypeScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						QUICScraping: Price{
+						ServiceTypeQUICScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						DataTransfer: Price{
+						ServiceTypeDataTransfer: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						DVPN: Price{
+						ServiceTypeDVPN: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						Monitoring: Price{
+						ServiceTypeMonitoring: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
@@ -514,76 +525,76 @@ func TestPricer_generateNewDefaults(t *testing.T) {
 				lp: LatestPrices{
 					Defaults: &PriceHistory{
 						Current: &PriceByType{
-							Residential: &PriceByServiceType{
-								Wireguard: Price{
+							Residential: PriceByServiceType{
+								ServiceTypeWireguard: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.05),
 									PricePerHourHumanReadable: 0.05,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 									PricePerGiBHumanReadable:  0.06,
 								},
-								Scraping: Price{
+								ServiceTypeScraping: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.05),
 									PricePerHourHumanReadable: 0.05,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 									PricePerGiBHumanReadable:  0.06,
 								},
-								QUICScraping: Price{
+								ServiceTypeQUICScraping: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.05),
 									PricePerHourHumanReadable: 0.05,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 									PricePerGiBHumanReadable:  0.06,
 								},
-								DataTransfer: Price{
+								ServiceTypeDataTransfer: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.05),
 									PricePerHourHumanReadable: 0.05,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 									PricePerGiBHumanReadable:  0.06,
 								},
-								DVPN: Price{
+								ServiceTypeDVPN: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.05),
 									PricePerHourHumanReadable: 0.05,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 									PricePerGiBHumanReadable:  0.06,
 								},
-								Monitoring: Price{
+								ServiceTypeMonitoring: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.05),
 									PricePerHourHumanReadable: 0.05,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 									PricePerGiBHumanReadable:  0.06,
 								},
 							},
-							Other: &PriceByServiceType{
-								Wireguard: Price{
+							Other: PriceByServiceType{
+								ServiceTypeWireguard: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.07),
 									PricePerHourHumanReadable: 0.07,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 									PricePerGiBHumanReadable:  0.08,
 								},
-								Scraping: Price{
+								ServiceTypeScraping: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.07),
 									PricePerHourHumanReadable: 0.07,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 									PricePerGiBHumanReadable:  0.08,
 								},
-								QUICScraping: Price{
+								ServiceTypeQUICScraping: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.07),
 									PricePerHourHumanReadable: 0.07,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 									PricePerGiBHumanReadable:  0.08,
 								},
-								DataTransfer: Price{
+								ServiceTypeDataTransfer: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.07),
 									PricePerHourHumanReadable: 0.07,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 									PricePerGiBHumanReadable:  0.08,
 								},
-								DVPN: Price{
+								ServiceTypeDVPN: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.07),
 									PricePerHourHumanReadable: 0.07,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 									PricePerGiBHumanReadable:  0.08,
 								},
-								Monitoring: Price{
+								ServiceTypeMonitoring: Price{
 									PricePerHour:              units.FloatEthToBigIntWei(0.07),
 									PricePerHourHumanReadable: 0.07,
 									PricePerGiB:               units.FloatEthToBigIntWei(0.08),
@@ -595,54 +606,54 @@ func TestPricer_generateNewDefaults(t *testing.T) {
 				},
 				cfg: Config{
 					BasePrices: PriceByTypeUSD{
-						Residential: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Residential: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
 						},
-						Other: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Other: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
@@ -655,76 +666,76 @@ func TestPricer_generateNewDefaults(t *testing.T) {
 			},
 			want: &PriceHistory{
 				Current: &PriceByType{
-					Residential: &PriceByServiceType{
-						Wireguard: Price{
+					Residential: PriceByServiceType{
+						ServiceTypeWireguard: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						Scraping: Price{
+						ServiceTypeScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						QUICScraping: Price{
+						ServiceTypeQUICScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						DataTransfer: Price{
+						ServiceTypeDataTransfer: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						DVPN: Price{
+						ServiceTypeDVPN: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
-						Monitoring: Price{
+						ServiceTypeMonitoring: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.01),
 							PricePerHourHumanReadable: 0.01,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.02),
 							PricePerGiBHumanReadable:  0.02,
 						},
 					},
-					Other: &PriceByServiceType{
-						Wireguard: Price{
+					Other: PriceByServiceType{
+						ServiceTypeWireguard: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						Scraping: Price{
+						ServiceTypeScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						QUICScraping: Price{
+						ServiceTypeQUICScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						DataTransfer: Price{
+						ServiceTypeDataTransfer: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						DVPN: Price{
+						ServiceTypeDVPN: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
 							PricePerGiBHumanReadable:  0.04,
 						},
-						Monitoring: Price{
+						ServiceTypeMonitoring: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.03),
 							PricePerHourHumanReadable: 0.03,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.04),
@@ -733,76 +744,76 @@ func TestPricer_generateNewDefaults(t *testing.T) {
 					},
 				},
 				Previous: &PriceByType{
-					Residential: &PriceByServiceType{
-						Wireguard: Price{
+					Residential: PriceByServiceType{
+						ServiceTypeWireguard: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.05),
 							PricePerHourHumanReadable: 0.05,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 							PricePerGiBHumanReadable:  0.06,
 						},
-						Scraping: Price{
+						ServiceTypeScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.05),
 							PricePerHourHumanReadable: 0.05,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 							PricePerGiBHumanReadable:  0.06,
 						},
-						QUICScraping: Price{
+						ServiceTypeQUICScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.05),
 							PricePerHourHumanReadable: 0.05,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 							PricePerGiBHumanReadable:  0.06,
 						},
-						DataTransfer: Price{
+						ServiceTypeDataTransfer: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.05),
 							PricePerHourHumanReadable: 0.05,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 							PricePerGiBHumanReadable:  0.06,
 						},
-						DVPN: Price{
+						ServiceTypeDVPN: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.05),
 							PricePerHourHumanReadable: 0.05,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 							PricePerGiBHumanReadable:  0.06,
 						},
-						Monitoring: Price{
+						ServiceTypeMonitoring: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.05),
 							PricePerHourHumanReadable: 0.05,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 							PricePerGiBHumanReadable:  0.06,
 						},
 					},
-					Other: &PriceByServiceType{
-						Wireguard: Price{
+					Other: PriceByServiceType{
+						ServiceTypeWireguard: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.07),
 							PricePerHourHumanReadable: 0.07,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 							PricePerGiBHumanReadable:  0.08,
 						},
-						Scraping: Price{
+						ServiceTypeScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.07),
 							PricePerHourHumanReadable: 0.07,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 							PricePerGiBHumanReadable:  0.08,
 						},
-						QUICScraping: Price{
+						ServiceTypeQUICScraping: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.07),
 							PricePerHourHumanReadable: 0.07,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 							PricePerGiBHumanReadable:  0.08,
 						},
-						DataTransfer: Price{
+						ServiceTypeDataTransfer: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.07),
 							PricePerHourHumanReadable: 0.07,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 							PricePerGiBHumanReadable:  0.08,
 						},
-						DVPN: Price{
+						ServiceTypeDVPN: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.07),
 							PricePerHourHumanReadable: 0.07,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 							PricePerGiBHumanReadable:  0.08,
 						},
-						Monitoring: Price{
+						ServiceTypeMonitoring: Price{
 							PricePerHour:              units.FloatEthToBigIntWei(0.07),
 							PricePerHourHumanReadable: 0.07,
 							PricePerGiB:               units.FloatEthToBigIntWei(0.08),
@@ -845,54 +856,54 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 				lp: LatestPrices{},
 				cfg: Config{
 					BasePrices: PriceByTypeUSD{
-						Residential: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Residential: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
 						},
-						Other: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Other: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
@@ -912,76 +923,76 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 			want: map[string]*PriceHistory{
 				"US": {
 					Current: &PriceByType{
-						Residential: &PriceByServiceType{
-							Wireguard: Price{
+						Residential: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
 						},
-						Other: &PriceByServiceType{
-							Wireguard: Price{
+						Other: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
@@ -990,76 +1001,76 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 						},
 					},
 					Previous: &PriceByType{
-						Residential: &PriceByServiceType{
-							Wireguard: Price{
+						Residential: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
 						},
-						Other: &PriceByServiceType{
-							Wireguard: Price{
+						Other: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
@@ -1078,76 +1089,76 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 					PerCountry: map[string]*PriceHistory{
 						"US": {
 							Current: &PriceByType{
-								Residential: &PriceByServiceType{
-									Wireguard: Price{
+								Residential: PriceByServiceType{
+									ServiceTypeWireguard: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.05),
 										PricePerHourHumanReadable: 0.05,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 										PricePerGiBHumanReadable:  0.06,
 									},
-									Scraping: Price{
+									ServiceTypeScraping: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.05),
 										PricePerHourHumanReadable: 0.05,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 										PricePerGiBHumanReadable:  0.06,
 									},
-									QUICScraping: Price{
+									ServiceTypeQUICScraping: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.05),
 										PricePerHourHumanReadable: 0.05,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 										PricePerGiBHumanReadable:  0.06,
 									},
-									DataTransfer: Price{
+									ServiceTypeDataTransfer: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.05),
 										PricePerHourHumanReadable: 0.05,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 										PricePerGiBHumanReadable:  0.06,
 									},
-									DVPN: Price{
+									ServiceTypeDVPN: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.05),
 										PricePerHourHumanReadable: 0.05,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 										PricePerGiBHumanReadable:  0.06,
 									},
-									Monitoring: Price{
+									ServiceTypeMonitoring: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.05),
 										PricePerHourHumanReadable: 0.05,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 										PricePerGiBHumanReadable:  0.06,
 									},
 								},
-								Other: &PriceByServiceType{
-									Wireguard: Price{
+								Other: PriceByServiceType{
+									ServiceTypeWireguard: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.07),
 										PricePerHourHumanReadable: 0.07,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 										PricePerGiBHumanReadable:  0.08,
 									},
-									Scraping: Price{
+									ServiceTypeScraping: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.07),
 										PricePerHourHumanReadable: 0.07,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 										PricePerGiBHumanReadable:  0.08,
 									},
-									QUICScraping: Price{
+									ServiceTypeQUICScraping: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.07),
 										PricePerHourHumanReadable: 0.07,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 										PricePerGiBHumanReadable:  0.08,
 									},
-									DataTransfer: Price{
+									ServiceTypeDataTransfer: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.07),
 										PricePerHourHumanReadable: 0.07,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 										PricePerGiBHumanReadable:  0.08,
 									},
-									DVPN: Price{
+									ServiceTypeDVPN: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.07),
 										PricePerHourHumanReadable: 0.07,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 										PricePerGiBHumanReadable:  0.08,
 									},
-									Monitoring: Price{
+									ServiceTypeMonitoring: Price{
 										PricePerHour:              units.FloatEthToBigIntWei(0.07),
 										PricePerHourHumanReadable: 0.07,
 										PricePerGiB:               units.FloatEthToBigIntWei(0.08),
@@ -1160,54 +1171,54 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 				},
 				cfg: Config{
 					BasePrices: PriceByTypeUSD{
-						Residential: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Residential: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
 						},
-						Other: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Other: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
@@ -1227,76 +1238,76 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 			want: map[string]*PriceHistory{
 				"US": {
 					Current: &PriceByType{
-						Residential: &PriceByServiceType{
-							Wireguard: Price{
+						Residential: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
 						},
-						Other: &PriceByServiceType{
-							Wireguard: Price{
+						Other: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
@@ -1305,76 +1316,76 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 						},
 					},
 					Previous: &PriceByType{
-						Residential: &PriceByServiceType{
-							Wireguard: Price{
+						Residential: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.05),
 								PricePerHourHumanReadable: 0.05,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 								PricePerGiBHumanReadable:  0.06,
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.05),
 								PricePerHourHumanReadable: 0.05,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 								PricePerGiBHumanReadable:  0.06,
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.05),
 								PricePerHourHumanReadable: 0.05,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 								PricePerGiBHumanReadable:  0.06,
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.05),
 								PricePerHourHumanReadable: 0.05,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 								PricePerGiBHumanReadable:  0.06,
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.05),
 								PricePerHourHumanReadable: 0.05,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 								PricePerGiBHumanReadable:  0.06,
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.05),
 								PricePerHourHumanReadable: 0.05,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.06),
 								PricePerGiBHumanReadable:  0.06,
 							},
 						},
-						Other: &PriceByServiceType{
-							Wireguard: Price{
+						Other: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.07),
 								PricePerHourHumanReadable: 0.07,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 								PricePerGiBHumanReadable:  0.08,
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.07),
 								PricePerHourHumanReadable: 0.07,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 								PricePerGiBHumanReadable:  0.08,
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.07),
 								PricePerHourHumanReadable: 0.07,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 								PricePerGiBHumanReadable:  0.08,
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.07),
 								PricePerHourHumanReadable: 0.07,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 								PricePerGiBHumanReadable:  0.08,
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.07),
 								PricePerHourHumanReadable: 0.07,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.08),
 								PricePerGiBHumanReadable:  0.08,
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              units.FloatEthToBigIntWei(0.07),
 								PricePerHourHumanReadable: 0.07,
 								PricePerGiB:               units.FloatEthToBigIntWei(0.08),
@@ -1394,54 +1405,54 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 				},
 				cfg: Config{
 					BasePrices: PriceByTypeUSD{
-						Residential: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Residential: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.01,
 								PricePerGiB:  0.02,
 							},
 						},
-						Other: &PriceByServiceTypeUSD{
-							Wireguard: PriceUSD{
+						Other: PriceByServiceTypeUSD{
+							ServiceTypeWireguard: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Scraping: PriceUSD{
+							ServiceTypeScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							QUICScraping: PriceUSD{
+							ServiceTypeQUICScraping: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DataTransfer: PriceUSD{
+							ServiceTypeDataTransfer: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							DVPN: PriceUSD{
+							ServiceTypeDVPN: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
-							Monitoring: PriceUSD{
+							ServiceTypeMonitoring: PriceUSD{
 								PricePerHour: 0.03,
 								PricePerGiB:  0.04,
 							},
@@ -1461,76 +1472,76 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 			want: map[string]*PriceHistory{
 				"US": {
 					Current: &PriceByType{
-						Residential: &PriceByServiceType{
-							Wireguard: Price{
+						Residential: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
 						},
-						Other: &PriceByServiceType{
-							Wireguard: Price{
+						Other: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
@@ -1539,76 +1550,76 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 						},
 					},
 					Previous: &PriceByType{
-						Residential: &PriceByServiceType{
-							Wireguard: Price{
+						Residential: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.01, 2),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.01, 2),
 								PricePerGiB:               calculatePriceMYST(1, 0.02, 2),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.02, 2),
 							},
 						},
-						Other: &PriceByServiceType{
-							Wireguard: Price{
+						Other: PriceByServiceType{
+							ServiceTypeWireguard: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Scraping: Price{
+							ServiceTypeScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							QUICScraping: Price{
+							ServiceTypeQUICScraping: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DataTransfer: Price{
+							ServiceTypeDataTransfer: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							DVPN: Price{
+							ServiceTypeDVPN: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
 								PricePerGiBHumanReadable:  calculatePriceMystFloat(1, 0.04, 3),
 							},
-							Monitoring: Price{
+							ServiceTypeMonitoring: Price{
 								PricePerHour:              calculatePriceMYST(1, 0.03, 3),
 								PricePerHourHumanReadable: calculatePriceMystFloat(1, 0.03, 3),
 								PricePerGiB:               calculatePriceMYST(1, 0.04, 3),
@@ -1635,3 +1646,139 @@ func TestPricer_generateNewPerCountry(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateNewPerCountryAppliesServiceModifiers(t *testing.T) {
+	multiplier, perGiB := 1.5, 10.0
+	cfg := testPreviewConfig()
+	cfg.CountryModifiers["US"] = Modifier{
+		Residential: 2,
+		Other:       1,
+		Services: map[ServiceType]ServiceModifier{
+			ServiceTypeDataTransfer: {
+				Residential: &PriceOverride{Multiplier: &multiplier},
+				Other:       &PriceOverride{PricePerGiB: &perGiB},
+			},
+		},
+	}
+
+	got := (&PriceUpdater{}).generateNewPerCountry(1, cfg)["US"].Current
+
+	if v := got.Residential[ServiceTypeDataTransfer].PricePerGiBHumanReadable; v != 6 {
+		t.Fatalf("residential data transfer per GiB = %v, want 6", v)
+	}
+	if v := got.Residential[ServiceTypeDataTransfer].PricePerHourHumanReadable; v != 3 {
+		t.Fatalf("residential data transfer per hour = %v, want 3", v)
+	}
+	if v := got.Other[ServiceTypeDataTransfer].PricePerGiBHumanReadable; v != 10 {
+		t.Fatalf("other data transfer per GiB = %v, want the override", v)
+	}
+	if v := got.Other[ServiceTypeDataTransfer].PricePerHourHumanReadable; v != 1 {
+		t.Fatalf("other data transfer per hour = %v, want the base price", v)
+	}
+	if v := got.Residential[ServiceTypeWireguard].PricePerGiBHumanReadable; v != 4 {
+		t.Fatalf("residential wireguard per GiB = %v, want only the country modifier", v)
+	}
+}
+
+func TestUpdateCountryModifiersKeepsServiceModifiers(t *testing.T) {
+	multiplier := 1.5
+	services := map[ServiceType]ServiceModifier{
+		ServiceTypeDVPN: {Other: &PriceOverride{Multiplier: &multiplier}},
+	}
+	cfg := Config{
+		CountryModifiers: map[ISO3166CountryCode]Modifier{
+			"US": {Residential: 1, Other: 1, Services: services},
+			"DE": {Residential: 1, Other: 1, Services: services},
+		},
+	}
+
+	if !updateCountryModifiers(&cfg, map[ISO3166CountryCode]float64{"US": 1.2}) {
+		t.Fatal("expected country modifiers to change")
+	}
+
+	want := map[ISO3166CountryCode]Modifier{
+		"US": {Residential: 1.2, Other: 1.2, Services: services},
+		"DE": {Residential: 1, Other: 1, Services: services},
+	}
+	if !reflect.DeepEqual(want, cfg.CountryModifiers) {
+		t.Fatalf("country modifiers = %#v, want %#v", cfg.CountryModifiers, want)
+	}
+
+	if updateCountryModifiers(&cfg, map[ISO3166CountryCode]float64{"US": 1.2}) {
+		t.Fatal("expected unchanged country modifiers not to trigger an update")
+	}
+}
+
+func TestGenerateNewPerCountryKeepsServicesMissingFromServiceOnlyModifier(t *testing.T) {
+	blob, err := json.Marshal(testPreviewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var posted map[string]json.RawMessage
+	if err := json.Unmarshal(blob, &posted); err != nil {
+		t.Fatal(err)
+	}
+	posted["country_modifiers"] = json.RawMessage(`{"DE": {"services": {"data_transfer": {"residential": {"multiplier": 2}, "other": {"multiplier": 2}}}}}`)
+	blob, err = json.Marshal(posted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(blob, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := (&PriceUpdater{}).generateNewPerCountry(1, cfg)["DE"].Current
+	for _, residential := range []bool{true, false} {
+		prices := got.ForNodeType(residential)
+		if price, _ := prices.ForServiceType(ServiceTypeDataTransfer); price.PricePerGiBHumanReadable != 4 {
+			t.Fatalf("data transfer per GiB = %v, want the doubled base price", price.PricePerGiBHumanReadable)
+		}
+		for _, serviceType := range []ServiceType{ServiceTypeWireguard, ServiceTypeScraping, ServiceTypeDVPN} {
+			price, _ := prices.ForServiceType(serviceType)
+			if v := price.PricePerGiBHumanReadable; v != 2 {
+				t.Fatalf("%v per GiB = %v, want the base price", serviceType, v)
+			}
+			if v := price.PricePerHourHumanReadable; v != 1 {
+				t.Fatalf("%v per hour = %v, want the base price", serviceType, v)
+			}
+		}
+	}
+}
+
+type staticMystRates map[string]float64
+
+func (r staticMystRates) MystRates() map[string]float64 {
+	return r
+}
+
+func TestGeneratePricesWithoutBaseCurrencyRateHasNoSideEffects(t *testing.T) {
+	cfg := testPreviewConfig()
+	cfg.BaseCurrency = "EUR"
+	cfg.DemandBoost = &DemandBoostConfig{Countries: map[ISO3166CountryCode]DemandBoostCountryCfg{
+		"DE": {TargetDemandIndex: 0.5, MaxBonus: 0.5},
+	}}
+	cfger := &simulationConfig{cfg: cfg}
+	demandIndexes := &stubCountryDemandIndexProvider{demandIndexes: map[ISO3166CountryCode]float64{"DE": 0}}
+	p := &PriceUpdater{
+		cfgProvider:   cfger,
+		priceAPI:      staticMystRates{"USD": 0.2},
+		demandIndexes: demandIndexes,
+		priceLifetime: DefaultPriceLifetime,
+		mystBound:     Bound{Min: 0, Max: math.Inf(1)},
+	}
+
+	if _, err := p.generatePrices(context.Background()); err == nil {
+		t.Fatal("expected an error without a MYST/EUR rate")
+	}
+	if demandIndexes.calls != 0 {
+		t.Fatalf("demand indexes loaded %d times, want none", demandIndexes.calls)
+	}
+	if !reflect.DeepEqual(cfger.cfg, cfg) {
+		t.Fatalf("config = %#v, want it unchanged", cfger.cfg)
+	}
+}
@@ -12,8 +12,11 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
+// CountryDemandIndexQuery is the default PromQL query of the country demand indexes.
 const CountryDemandIndexQuery = `WITH (
   requests = sum_over_time(dvpn_client_connect_requests_with_country{country!=""}[24h:10m]),
   VPN_DemandIndex =(0.4*sum by (country)(requests) / sum(requests) + 0.25*count by (country)(sum by (country,uuid)(requests)) / count(sum by (uuid)(requests))),
@@ -61,27 +64,50 @@ type CountryDemandIndexProvider interface {
 	DemandIndexes(context.Context) (map[ISO3166CountryCode]float64, error)
 }
 
-type DailyCountryDemandIndexProvider struct {
+// DemandIndexHistory persists the fetched demand indexes.
+type DemandIndexHistory interface {
+	Store(ctx context.Context, snapshot DemandIndexSnapshot) error
+	Latest(ctx context.Context) (*DemandIndexSnapshot, error)
+}
+
+// ScheduledCountryDemandIndexProvider caches the demand indexes of a provider until
+// the next refresh of its schedule. With a history, every fetch is stored and the
+// last one is restored on the first call, e.g. after a restart, while it is not due
+// for a refresh.
+type ScheduledCountryDemandIndexProvider struct {
 	provider CountryDemandIndexProvider
+	schedule RefreshSchedule
+	history  DemandIndexHistory
 	now      func() time.Time
 
 	lock          sync.Mutex
 	cached        map[ISO3166CountryCode]float64
 	nextRefreshAt time.Time
+	restored      bool
 }
 
-func NewDailyCountryDemandIndexProvider(provider CountryDemandIndexProvider) *DailyCountryDemandIndexProvider {
-	return &DailyCountryDemandIndexProvider{
+func NewScheduledCountryDemandIndexProvider(provider CountryDemandIndexProvider, schedule RefreshSchedule, history DemandIndexHistory) *ScheduledCountryDemandIndexProvider {
+	return &ScheduledCountryDemandIndexProvider{
 		provider: provider,
+		schedule: schedule,
+		history:  history,
 		now:      time.Now,
 	}
 }
 
-func (p *DailyCountryDemandIndexProvider) DemandIndexes(ctx context.Context) (map[ISO3166CountryCode]float64, error) {
+// NewDailyCountryDemandIndexProvider refreshes the demand indexes at UTC midnight without keeping them.
+func NewDailyCountryDemandIndexProvider(provider CountryDemandIndexProvider) *ScheduledCountryDemandIndexProvider {
+	return NewScheduledCountryDemandIndexProvider(provider, DailySchedule{}, nil)
+}
+
+func (p *ScheduledCountryDemandIndexProvider) DemandIndexes(ctx context.Context) (map[ISO3166CountryCode]float64, error) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
 	now := p.now()
+	if p.cached == nil && !p.restored {
+		p.restore(ctx)
+	}
 	if p.cached != nil && now.Before(p.nextRefreshAt) {
 		return cloneFloatMap(p.cached), nil
 	}
@@ -92,10 +118,36 @@ func (p *DailyCountryDemandIndexProvider) DemandIndexes(ctx context.Context) (ma
 	}
 
 	p.cached = cloneFloatMap(demandIndexes)
-	p.nextRefreshAt = nextUTCMidnight(now)
+	p.nextRefreshAt = p.schedule.Next(now)
+	if p.history != nil {
+		snapshot := DemandIndexSnapshot{Time: now.UTC(), DemandIndexes: cloneFloatMap(demandIndexes)}
+		if err := p.history.Store(ctx, snapshot); err != nil {
+			log.Err(err).Msg("failed to store demand indexes")
+		}
+	}
 	return cloneFloatMap(p.cached), nil
 }
 
+func (p *ScheduledCountryDemandIndexProvider) restore(ctx context.Context) {
+	p.restored = true
+	if p.history == nil {
+		return
+	}
+
+	latest, err := p.history.Latest(ctx)
+	if err != nil {
+		log.Err(err).Msg("failed to restore demand indexes")
+		return
+	}
+	if latest == nil {
+		return
+	}
+
+	p.cached = cloneFloatMap(latest.DemandIndexes)
+	p.nextRefreshAt = p.schedule.Next(latest.Time)
+	log.Info().Msgf("restored demand indexes fetched at %s, next refresh at %s", latest.Time, p.nextRefreshAt)
+}
+
 func nextUTCMidnight(now time.Time) time.Time {
 	utc := now.UTC()
 	return time.Date(utc.Year(), utc.Month(), utc.Day()+1, 0, 0, 0, 0, time.UTC)
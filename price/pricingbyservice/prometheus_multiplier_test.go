@@ -138,13 +138,13 @@ func TestGenerateNewPerCountryDefaultsMissingMultiplierToBalanced(t *testing.T)
 	price := PriceUSD{PricePerHour: 1, PricePerGiB: 2}
 	cfg := Config{
 		BasePrices: PriceByTypeUSD{
-			Residential: &PriceByServiceTypeUSD{
-				Wireguard: price, Scraping: price, QUICScraping: price,
-				DataTransfer: price, DVPN: price, Monitoring: price,
+			Residential: PriceByServiceTypeUSD{
+				ServiceTypeWireguard: price, ServiceTypeScraping: price, ServiceTypeQUICScraping: price,
+				ServiceTypeDataTransfer: price, ServiceTypeDVPN: price, ServiceTypeMonitoring: price,
 			},
-			Other: &PriceByServiceTypeUSD{
-				Wireguard: price, Scraping: price, QUICScraping: price,
-				DataTransfer: price, DVPN: price, Monitoring: price,
+			Other: PriceByServiceTypeUSD{
+				ServiceTypeWireguard: price, ServiceTypeScraping: price, ServiceTypeQUICScraping: price,
+				ServiceTypeDataTransfer: price, ServiceTypeDVPN: price, ServiceTypeMonitoring: price,
 			},
 		},
 	}
@@ -152,8 +152,50 @@ func TestGenerateNewPerCountryDefaultsMissingMultiplierToBalanced(t *testing.T)
 	pricer := &PriceUpdater{}
 	prices := pricer.generateNewPerCountryWithMultipliers(1, cfg, map[ISO3166CountryCode]float64{"US": 1.5})
 
-	require.Equal(t, 1.5, prices["US"].Current.Residential.Wireguard.PricePerHourHumanReadable)
-	require.Equal(t, 1.5, prices["US"].Current.Other.Wireguard.PricePerHourHumanReadable)
-	require.Equal(t, float64(1), prices["DE"].Current.Residential.Wireguard.PricePerHourHumanReadable)
-	require.Equal(t, float64(1), prices["DE"].Current.Other.Wireguard.PricePerHourHumanReadable)
+	require.Equal(t, 1.5, prices["US"].Current.Residential[ServiceTypeWireguard].PricePerHourHumanReadable)
+	require.Equal(t, 1.5, prices["US"].Current.Other[ServiceTypeWireguard].PricePerHourHumanReadable)
+	require.Equal(t, float64(1), prices["DE"].Current.Residential[ServiceTypeWireguard].PricePerHourHumanReadable)
+	require.Equal(t, float64(1), prices["DE"].Current.Other[ServiceTypeWireguard].PricePerHourHumanReadable)
+}
+
+func TestScheduledCountryDemandIndexProviderRestoresHistory(t *testing.T) {
+	fetchedAt := time.Date(2026, time.June, 18, 10, 0, 0, 0, time.UTC)
+	history := &memoryDemandIndexHistory{
+		snapshots: []DemandIndexSnapshot{{Time: fetchedAt, DemandIndexes: map[ISO3166CountryCode]float64{"US": 0.3}}},
+	}
+	source := &stubCountryDemandIndexProvider{
+		demandIndexes: map[ISO3166CountryCode]float64{"US": 0.05},
+	}
+	now := fetchedAt.Add(5 * time.Hour)
+	provider := NewScheduledCountryDemandIndexProvider(source, IntervalSchedule(6*time.Hour), history)
+	provider.now = func() time.Time { return now }
+
+	got, err := provider.DemandIndexes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0.3, got["US"])
+	require.Equal(t, 0, source.calls)
+
+	now = fetchedAt.Add(6 * time.Hour)
+	got, err = provider.DemandIndexes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0.05, got["US"])
+	require.Equal(t, 1, source.calls)
+	require.Len(t, history.snapshots, 2)
+	require.Equal(t, now, history.snapshots[1].Time)
+}
+
+type memoryDemandIndexHistory struct {
+	snapshots []DemandIndexSnapshot
+}
+
+func (h *memoryDemandIndexHistory) Store(_ context.Context, snapshot DemandIndexSnapshot) error {
+	h.snapshots = append(h.snapshots, snapshot)
+	return nil
+}
+
+func (h *memoryDemandIndexHistory) Latest(context.Context) (*DemandIndexSnapshot, error) {
+	if len(h.snapshots) == 0 {
+		return nil, nil
+	}
+	return &h.snapshots[len(h.snapshots)-1], nil
 }
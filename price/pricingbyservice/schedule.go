@@ -0,0 +1,170 @@
+package pricingbyservice
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RefreshSchedule tells when data fetched at a point in time should be fetched again.
+type RefreshSchedule interface {
+	Next(time.Time) time.Time
+}
+
+// ParseRefreshSchedule parses an interval, e.g. 6h, or a cron expression of five
+// fields (minute, hour, day of month, month and day of week) evaluated in UTC,
+// e.g. "0 */6 * * *". The @hourly and @daily shorthands are supported too.
+func ParseRefreshSchedule(spec string) (RefreshSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "":
+		return nil, errors.New("refresh schedule should not be empty")
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		return DailySchedule{}, nil
+	}
+
+	if interval, err := time.ParseDuration(spec); err == nil {
+		if interval <= 0 {
+			return nil, errors.New("refresh interval should be positive")
+		}
+		return IntervalSchedule(interval), nil
+	}
+
+	schedule, err := parseCronSchedule(spec)
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// IntervalSchedule refreshes a fixed time after the last fetch.
+type IntervalSchedule time.Duration
+
+func (s IntervalSchedule) Next(tm time.Time) time.Time {
+	return tm.Add(time.Duration(s))
+}
+
+// DailySchedule refreshes at the next UTC midnight.
+type DailySchedule struct{}
+
+func (DailySchedule) Next(tm time.Time) time.Time {
+	return nextUTCMidnight(tm)
+}
+
+// CronSchedule refreshes at the next minute matching all of its fields.
+type CronSchedule struct {
+	minutes, hours, days, months, weekdays []bool
+	// anyDay is set when either of the day fields is a wildcard, as in cron
+	// a day matches either field when both are restricted.
+	anyDay bool
+}
+
+// maxCronLookahead bounds the search for the next matching minute, e.g. of 30th of February.
+const maxCronLookahead = 5 * 366 * 24 * time.Hour
+
+func parseCronSchedule(spec string) (*CronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q should have 5 fields", spec)
+	}
+
+	s := &CronSchedule{}
+	var err error
+	if s.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron minutes: %w", err)
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron hours: %w", err)
+	}
+	if s.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron days of month: %w", err)
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron months: %w", err)
+	}
+	if s.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron days of week: %w", err)
+	}
+	// both 0 and 7 stand for sunday
+	s.weekdays[0] = s.weekdays[0] || s.weekdays[7]
+	s.anyDay = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*")
+
+	return s, nil
+}
+
+// parseCronField parses comma separated values, ranges and steps, e.g. "1,5-10,*/15".
+func parseCronField(field string, min, max int) ([]bool, error) {
+	res := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			rng = part[:i]
+		}
+
+		from, to := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("invalid value in %q", part)
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("invalid range in %q", part)
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, fmt.Errorf("%q is out of the %d-%d range", part, min, max)
+		}
+
+		for v := from; v <= to; v += step {
+			res[v] = true
+		}
+	}
+	return res, nil
+}
+
+func (s *CronSchedule) Next(tm time.Time) time.Time {
+	next := tm.UTC().Truncate(time.Minute).Add(time.Minute)
+	end := next.Add(maxCronLookahead)
+	for next.Before(end) {
+		if !s.months[int(next.Month())] {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.hours[next.Hour()] {
+			next = next.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !s.minutes[next.Minute()] {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+	return end
+}
+
+func (s *CronSchedule) dayMatches(tm time.Time) bool {
+	day, weekday := s.days[tm.Day()], s.weekdays[int(tm.Weekday())]
+	if s.anyDay {
+		return day && weekday
+	}
+	return day || weekday
+}
@@ -0,0 +1,43 @@
+package pricingbyservice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRefreshSchedule(t *testing.T) {
+	from := time.Date(2026, time.June, 18, 10, 7, 30, 0, time.UTC) // a thursday
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"6h", from.Add(6 * time.Hour)},
+		{"@daily", time.Date(2026, time.June, 19, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.June, 18, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.June, 18, 10, 15, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2026, time.June, 18, 12, 0, 0, 0, time.UTC)},
+		{"30 2 1,15 * *", time.Date(2026, time.July, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, time.June, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, time.June, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseRefreshSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Fatalf("next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRefreshScheduleRejectsInvalid(t *testing.T) {
+	for _, spec := range []string{"", "-1h", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseRefreshSchedule(spec); err == nil {
+			t.Fatalf("expected an error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package pricingbyservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SimulationStep is a recorded market state replayed by the simulation. Demand
+// indexes and supply are carried over from the previous step when empty.
+type SimulationStep struct {
+	Time          time.Time                      `json:"time"`
+	MystUSD       float64                        `json:"myst_usd,omitempty"`
+	MystRates     map[string]float64             `json:"myst_rates,omitempty"`
+	DemandIndexes map[ISO3166CountryCode]float64 `json:"demand_indexes,omitempty"`
+	Supply        map[ISO3166CountryCode]float64 `json:"supply,omitempty"`
+}
+
+// SimulationPoint is a simulated price of a country, empty for the defaults, at a step.
+// Prices are in MYST and the multiplier is the demand boost applied.
+type SimulationPoint struct {
+	Time            time.Time   `json:"time"`
+	Country         string      `json:"country,omitempty"`
+	NodeType        string      `json:"node_type"`
+	ServiceType     ServiceType `json:"service_type"`
+	MystRate        float64     `json:"myst_rate"`
+	PricePerHour    float64     `json:"price_per_hour"`
+	PricePerGiB     float64     `json:"price_per_gib"`
+	BoostMultiplier float64     `json:"boost_multiplier"`
+	Clamped         bool        `json:"clamped"`
+}
+
+// SimulationStats summarizes the simulated prices of a country, node and service type.
+// Volatility is the standard deviation of the relative price changes between steps and
+// BoostFrequency is the share of steps with a demand boost applied.
+type SimulationStats struct {
+	Country           string      `json:"country,omitempty"`
+	NodeType          string      `json:"node_type"`
+	ServiceType       ServiceType `json:"service_type"`
+	Steps             int         `json:"steps"`
+	MinPricePerGiB    float64     `json:"min_price_per_gib"`
+	MaxPricePerGiB    float64     `json:"max_price_per_gib"`
+	MeanPricePerGiB   float64     `json:"mean_price_per_gib"`
+	PerHourVolatility float64     `json:"per_hour_volatility"`
+	PerGiBVolatility  float64     `json:"per_gib_volatility"`
+	MaxPerGiBChange   float64     `json:"max_per_gib_change"`
+	BoostFrequency    float64     `json:"boost_frequency"`
+	Clamps            int         `json:"clamps"`
+}
+
+type SimulationResult struct {
+	Points []SimulationPoint `json:"points"`
+	Stats  []SimulationStats `json:"stats"`
+}
+
+// Simulate replays the steps through the price generation of the PriceUpdater with
+// the given config, smoothing, guardrails and demand boost included. The prices of
+// the given countries are returned, the defaults when a country is empty.
+func Simulate(cfg Config, steps []SimulationStep, countries []string) (SimulationResult, error) {
+	if err := cfg.Validate(); err != nil {
+		return SimulationResult{}, fmt.Errorf("config invalid: %w", err)
+	}
+	if len(steps) == 0 {
+		return SimulationResult{}, errors.New("no steps to simulate")
+	}
+	if len(countries) == 0 {
+		countries = []string{""}
+	}
+	for _, country := range countries {
+		if country == "" {
+			continue
+		}
+		if err := ISO3166CountryCode(country).Validate(); err != nil {
+			return SimulationResult{}, err
+		}
+	}
+
+	source := &simulationSource{}
+	p := &PriceUpdater{
+		cfgProvider:   &simulationConfig{cfg: cfg},
+		priceAPI:      source,
+		demandIndexes: source,
+		supply:        source,
+		priceLifetime: DefaultPriceLifetime,
+		mystBound:     Bound{Min: 0, Max: math.Inf(1)},
+		now:           func() time.Time { return source.step.Time },
+	}
+
+	var res SimulationResult
+	for i, step := range steps {
+		if i > 0 && step.Time.Before(steps[i-1].Time) {
+			return SimulationResult{}, fmt.Errorf("step %d at %s is before the previous one", i, step.Time)
+		}
+		if step.DemandIndexes == nil {
+			step.DemandIndexes = source.step.DemandIndexes
+		}
+		if step.Supply == nil {
+			step.Supply = source.step.Supply
+		}
+		source.step = step
+
+		generated, err := p.generatePrices(context.Background())
+		if err != nil {
+			return SimulationResult{}, fmt.Errorf("step %d at %s: %w", i, step.Time, err)
+		}
+		p.lp = generated.lp
+
+		for _, country := range countries {
+			res.Points = appendSimulationPoints(res.Points, step.Time, country, generated, generated.mystRates[cfg.Currency()])
+		}
+	}
+	res.Stats = simulationStats(res.Points)
+
+	return res, nil
+}
+
+func appendSimulationPoints(points []SimulationPoint, tm time.Time, country string, generated generatedPrices, mystRate float64) []SimulationPoint {
+	ph := generated.lp.ForCountry(country)
+	if ph == nil || ph.Current == nil {
+		return points
+	}
+
+	for _, nodeType := range []string{NodeTypeResidential, NodeTypeOther} {
+		prices := ph.Current.ForNodeType(nodeType == NodeTypeResidential)
+		for _, serviceType := range prices.ServiceTypes() {
+			multiplier := 1.0
+			if m, ok := generated.boost.Multipliers[ISO3166CountryCode(country)][serviceType]; ok {
+				multiplier = m
+			}
+			clamped := false
+			for _, c := range generated.clamps {
+				clamped = clamped || (c.Country == country && c.NodeType == nodeType && c.ServiceType == serviceType)
+			}
+
+			points = append(points, SimulationPoint{
+				Time:            tm,
+				Country:         country,
+				NodeType:        nodeType,
+				ServiceType:     serviceType,
+				MystRate:        mystRate,
+				PricePerHour:    prices[serviceType].PricePerHourHumanReadable,
+				PricePerGiB:     prices[serviceType].PricePerGiBHumanReadable,
+				BoostMultiplier: multiplier,
+				Clamped:         clamped,
+			})
+		}
+	}
+	return points
+}
+
+// simulationStats summarizes the points in the order their series first appear.
+func simulationStats(points []SimulationPoint) []SimulationStats {
+	type seriesKey struct {
+		country, nodeType string
+		serviceType       ServiceType
+	}
+
+	var keys []seriesKey
+	series := make(map[seriesKey][]SimulationPoint)
+	for _, point := range points {
+		key := seriesKey{point.Country, point.NodeType, point.ServiceType}
+		if _, ok := series[key]; !ok {
+			keys = append(keys, key)
+		}
+		series[key] = append(series[key], point)
+	}
+
+	res := make([]SimulationStats, 0, len(keys))
+	for _, key := range keys {
+		points := series[key]
+		stats := SimulationStats{
+			Country:        key.country,
+			NodeType:       key.nodeType,
+			ServiceType:    key.serviceType,
+			Steps:          len(points),
+			MinPricePerGiB: math.Inf(1),
+		}
+
+		var sum float64
+		var boosted int
+		perHourChanges := make([]float64, 0, len(points))
+		perGiBChanges := make([]float64, 0, len(points))
+		for i, point := range points {
+			sum += point.PricePerGiB
+			stats.MinPricePerGiB = math.Min(stats.MinPricePerGiB, point.PricePerGiB)
+			stats.MaxPricePerGiB = math.Max(stats.MaxPricePerGiB, point.PricePerGiB)
+			if point.BoostMultiplier > 1 {
+				boosted++
+			}
+			if point.Clamped {
+				stats.Clamps++
+			}
+			if i > 0 {
+				perHourChanges = append(perHourChanges, relativeChange(points[i-1].PricePerHour, point.PricePerHour))
+				perGiBChange := relativeChange(points[i-1].PricePerGiB, point.PricePerGiB)
+				perGiBChanges = append(perGiBChanges, perGiBChange)
+				stats.MaxPerGiBChange = math.Max(stats.MaxPerGiBChange, math.Abs(perGiBChange))
+			}
+		}
+		stats.MeanPricePerGiB = sum / float64(len(points))
+		stats.PerHourVolatility = stdDev(perHourChanges)
+		stats.PerGiBVolatility = stdDev(perGiBChanges)
+		stats.BoostFrequency = float64(boosted) / float64(len(points))
+
+		res = append(res, stats)
+	}
+	return res
+}
+
+func stdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
+
+// simulationSource provides the market state of the current step to the PriceUpdater.
+type simulationSource struct {
+	step SimulationStep
+}
+
+func (s *simulationSource) MystRates() map[string]float64 {
+	rates := make(map[string]float64, len(s.step.MystRates)+1)
+	for currency, rate := range s.step.MystRates {
+		rates[currency] = rate
+	}
+	if s.step.MystUSD > 0 {
+		rates[DefaultBaseCurrency] = s.step.MystUSD
+	}
+	return rates
+}
+
+func (s *simulationSource) DemandIndexes(context.Context) (map[ISO3166CountryCode]float64, error) {
+	return s.step.DemandIndexes, nil
+}
+
+func (s *simulationSource) CountrySupply(context.Context) (map[ISO3166CountryCode]float64, error) {
+	return s.step.Supply, nil
+}
+
+// simulationConfig keeps the config in memory, including the country modifiers updated by the demand boost.
+type simulationConfig struct {
+	cfg Config
+}
+
+func (s *simulationConfig) Get() (Config, error) {
+	return s.cfg, nil
+}
+
+func (s *simulationConfig) Update(cfg Config, _ ConfigChange) error {
+	s.cfg = cfg
+	return nil
+}
+
+func (s *simulationConfig) UpdateCountryModifiers(multipliers map[ISO3166CountryCode]float64, _ ConfigChange) error {
+	updateCountryModifiers(&s.cfg, multipliers)
+	return nil
+}
@@ -0,0 +1,117 @@
+package pricingbyservice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []SimulationStep{
+		{Time: start, MystUSD: 1},
+		{Time: start.Add(time.Hour), MystUSD: 0.5},
+		{Time: start.Add(2 * time.Hour), MystUSD: 0.5},
+	}
+
+	res, err := Simulate(testPreviewConfig(), steps, []string{"US", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := 3 * 2 * 2 * len(DefaultServiceTypes)
+	if len(res.Points) != want {
+		t.Fatalf("points = %d, want %d", len(res.Points), want)
+	}
+	for _, point := range res.Points {
+		if point.Country != "US" || point.NodeType != NodeTypeResidential || point.ServiceType != ServiceTypeWireguard {
+			continue
+		}
+		wantPrice := 4 / point.MystRate
+		if point.PricePerGiB != wantPrice {
+			t.Fatalf("US price at %s = %v, want %v", point.Time, point.PricePerGiB, wantPrice)
+		}
+	}
+
+	for _, stats := range res.Stats {
+		if stats.Steps != 3 {
+			t.Fatalf("steps of %#v = %d, want 3", stats, stats.Steps)
+		}
+		if stats.MaxPerGiBChange != 1 {
+			t.Fatalf("max change of %#v = %v, want 1", stats, stats.MaxPerGiBChange)
+		}
+		if stats.BoostFrequency != 0 {
+			t.Fatalf("boost frequency of %#v = %v, want 0", stats, stats.BoostFrequency)
+		}
+	}
+}
+
+func TestSimulateDemandBoostCarriesOverDemandIndexes(t *testing.T) {
+	cfg := testPreviewConfig()
+	cfg.DemandBoost = &DemandBoostConfig{
+		Countries: map[ISO3166CountryCode]DemandBoostCountryCfg{
+			"DE": {TargetDemandIndex: 0.1, MaxBonus: 0.5},
+		},
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []SimulationStep{
+		{Time: start, MystUSD: 1, DemandIndexes: map[ISO3166CountryCode]float64{"DE": 0.2}},
+		{Time: start.Add(time.Hour), MystUSD: 1, DemandIndexes: map[ISO3166CountryCode]float64{"DE": 0.05}},
+		{Time: start.Add(2 * time.Hour), MystUSD: 1},
+		{Time: start.Add(3 * time.Hour), MystUSD: 1, DemandIndexes: map[ISO3166CountryCode]float64{"DE": 0.2}},
+	}
+
+	res, err := Simulate(cfg, steps, []string{"DE"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, stats := range res.Stats {
+		if stats.BoostFrequency != 0.5 {
+			t.Fatalf("boost frequency of %s %s = %v, want 0.5", stats.NodeType, stats.ServiceType, stats.BoostFrequency)
+		}
+		if stats.MaxPricePerGiB <= stats.MinPricePerGiB {
+			t.Fatalf("prices of %s %s should change with the boost", stats.NodeType, stats.ServiceType)
+		}
+	}
+}
+
+func TestSimulateReportsClamps(t *testing.T) {
+	cfg := testPreviewConfig()
+	cfg.Guardrails = &Guardrails{MaxPriceChange: 0.1}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []SimulationStep{
+		{Time: start, MystUSD: 1},
+		{Time: start.Add(time.Hour), MystUSD: 0.5},
+	}
+
+	res, err := Simulate(cfg, steps, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, stats := range res.Stats {
+		if stats.Clamps != 1 {
+			t.Fatalf("clamps of %s %s = %d, want 1", stats.NodeType, stats.ServiceType, stats.Clamps)
+		}
+		if stats.MaxPerGiBChange > 0.1+1e-9 {
+			t.Fatalf("max change of %s %s = %v, want at most 0.1", stats.NodeType, stats.ServiceType, stats.MaxPerGiBChange)
+		}
+	}
+}
+
+func TestSimulateRejectsInvalidInput(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	unordered := []SimulationStep{
+		{Time: start.Add(time.Hour), MystUSD: 1},
+		{Time: start, MystUSD: 1},
+	}
+	if _, err := Simulate(testPreviewConfig(), unordered, nil); err == nil {
+		t.Fatal("expected an error for unordered steps")
+	}
+	if _, err := Simulate(testPreviewConfig(), nil, nil); err == nil {
+		t.Fatal("expected an error without steps")
+	}
+	if _, err := Simulate(testPreviewConfig(), unordered[1:], []string{"usa"}); err == nil {
+		t.Fatal("expected an error for an invalid country")
+	}
+}
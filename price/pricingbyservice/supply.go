@@ -0,0 +1,83 @@
+package pricingbyservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CountrySupplyProvider provides the number of providers in each country.
+type CountrySupplyProvider interface {
+	CountrySupply(context.Context) (map[ISO3166CountryCode]float64, error)
+}
+
+// DiscoverySupplyProvider reads the number of providers in each country from the
+// /countries endpoint of discovery.
+type DiscoverySupplyProvider struct {
+	baseURL *url.URL
+	client  *http.Client
+}
+
+// NewDiscoverySupplyProvider creates a supply provider of the discovery API at baseURL, e.g. http://discovery/api/v4.
+func NewDiscoverySupplyProvider(baseURL *url.URL) *DiscoverySupplyProvider {
+	return &DiscoverySupplyProvider{
+		baseURL: baseURL,
+		client:  http.DefaultClient,
+	}
+}
+
+func (p *DiscoverySupplyProvider) CountrySupply(ctx context.Context) (map[ISO3166CountryCode]float64, error) {
+	endpoint := *p.baseURL
+	endpoint.Path = strings.TrimRight(endpoint.Path, "/") + "/countries"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create countries request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("query countries: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 16*1024))
+		return nil, fmt.Errorf("query countries: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var countries map[string]int
+	if err := json.NewDecoder(resp.Body).Decode(&countries); err != nil {
+		return nil, fmt.Errorf("decode countries response: %w", err)
+	}
+
+	supply := make(map[ISO3166CountryCode]float64, len(countries))
+	for k, v := range countries {
+		country := ISO3166CountryCode(k)
+		if country.Validate() != nil || v <= 0 {
+			continue
+		}
+		supply[country] = float64(v)
+	}
+	return supply, nil
+}
+
+// PrometheusSupplyProvider reads the number of providers in each country from
+// a PromQL query, e.g. of the provider count gauges, returning a vector by country.
+type PrometheusSupplyProvider struct {
+	query *PrometheusDemandIndexProvider
+}
+
+func NewPrometheusSupplyProvider(baseURL *url.URL, username, password, query string) *PrometheusSupplyProvider {
+	return &PrometheusSupplyProvider{
+		query: NewPrometheusDemandIndexProvider(baseURL, username, password, query),
+	}
+}
+
+func (p *PrometheusSupplyProvider) CountrySupply(ctx context.Context) (map[ISO3166CountryCode]float64, error) {
+	return p.query.DemandIndexes(ctx)
+}
@@ -0,0 +1,35 @@
+package pricingbyservice
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverySupplyProvider(t *testing.T) {
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/api/v4/countries", r.URL.Path)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Body:       io.NopCloser(strings.NewReader(`{"US": 40, "DE": 60, "invalid": 5, "FR": 0}`)),
+			Header:     make(http.Header),
+		}, nil
+	})
+
+	discoveryURL, err := url.Parse("https://discovery.example/api/v4/")
+	require.NoError(t, err)
+	provider := NewDiscoverySupplyProvider(discoveryURL)
+	provider.client = &http.Client{Transport: transport}
+
+	got, err := provider.CountrySupply(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[ISO3166CountryCode]float64{"US": 40, "DE": 60}, got)
+}
@@ -0,0 +1,29 @@
+package price
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// SignedResponse wraps a payload so it can be verified later. Hash is the hex
+// encoded SHA-256 of the exact payload bytes. The response body is signed like
+// the other price lists, see the pricesig package.
+type SignedResponse struct {
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+	Hash    string          `json:"hash"`
+}
+
+func newSignedResponse(payload interface{}) (SignedResponse, error) {
+	blob, err := json.Marshal(payload)
+	if err != nil {
+		return SignedResponse{}, err
+	}
+
+	hash := sha256.Sum256(blob)
+	res := SignedResponse{
+		Payload: blob,
+		Hash:    hex.EncodeToString(hash[:]),
+	}
+	return res, nil
+}
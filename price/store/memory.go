@@ -0,0 +1,414 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// subscriptionBuffer is how many messages a subscriber can fall behind before they are dropped.
+const subscriptionBuffer = 64
+
+// fileSaveDelay is how long the file store waits after a write before saving the
+// file, so the writes of a price update are saved at once rather than one by one.
+const fileSaveDelay = 5 * time.Second
+
+// Memory is a Store kept in memory, persisted to a file when opened with NewFile.
+// Its pub/sub only reaches the subscribers of the same process.
+type Memory struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	data memoryData
+	subs map[string]map[*memorySubscription]struct{}
+	// path of the file the data is saved to shortly after the writes, empty when
+	// kept in memory only.
+	path      string
+	saveDelay time.Duration
+	// pendingSave is set while a save of the writes is scheduled.
+	pendingSave *time.Timer
+	// saveMu orders the saves of the file.
+	saveMu sync.Mutex
+}
+
+type memoryData struct {
+	Values     map[string]memoryValue    `json:"values"`
+	SortedSets map[string][]memoryMember `json:"sorted_sets"`
+	Lists      map[string][]string       `json:"lists"`
+}
+
+type memoryValue struct {
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+type memoryMember struct {
+	Score  float64 `json:"score"`
+	Member string  `json:"member"`
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		now: time.Now,
+		data: memoryData{
+			Values:     make(map[string]memoryValue),
+			SortedSets: make(map[string][]memoryMember),
+			Lists:      make(map[string][]string),
+		},
+		subs: make(map[string]map[*memorySubscription]struct{}),
+	}
+}
+
+// NewFile opens a Memory store saved to the file at path, loading the file if it exists.
+func NewFile(path string) (*Memory, error) {
+	m := NewMemory()
+	m.path = path
+	m.saveDelay = fileSaveDelay
+
+	blob, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read store file: %w", err)
+	}
+	if err := json.Unmarshal(blob, &m.data); err != nil {
+		return nil, fmt.Errorf("could not decode store file: %w", err)
+	}
+	if m.data.Values == nil {
+		m.data.Values = make(map[string]memoryValue)
+	}
+	if m.data.SortedSets == nil {
+		m.data.SortedSets = make(map[string][]memoryMember)
+	}
+	if m.data.Lists == nil {
+		m.data.Lists = make(map[string][]string)
+	}
+	return m, nil
+}
+
+// changed schedules a save of the file unless one is already pending. It should
+// be called holding the mutex.
+func (m *Memory) changed() {
+	if m.path == "" || m.pendingSave != nil {
+		return
+	}
+	m.pendingSave = time.AfterFunc(m.saveDelay, func() {
+		if err := m.save(); err != nil {
+			log.Err(err).Str("file", m.path).Msg("could not save the store")
+		}
+	})
+}
+
+// save writes the data to the file, replacing it at once so a crash can't leave it half written.
+func (m *Memory) save() error {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	m.mu.Lock()
+	if m.pendingSave != nil {
+		m.pendingSave.Stop()
+		m.pendingSave = nil
+	}
+	blob, err := json.Marshal(m.data)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), filepath.Base(m.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not save store file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(blob); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not save store file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not save store file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("could not save store file: %w", err)
+	}
+	return nil
+}
+
+// Close saves the pending writes to the file.
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	pending := m.pendingSave != nil
+	m.mu.Unlock()
+	if !pending {
+		return nil
+	}
+	return m.save()
+}
+
+// value returns the value of the key unless it expired. It should be called holding the mutex.
+func (m *Memory) value(key string) (memoryValue, bool) {
+	v, ok := m.data.Values[key]
+	if !ok {
+		return memoryValue{}, false
+	}
+	if !v.ExpiresAt.IsZero() && !m.now().Before(v.ExpiresAt) {
+		delete(m.data.Values, key)
+		return memoryValue{}, false
+	}
+	return v, true
+}
+
+func (m *Memory) Get(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.value(key)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v.Value, nil
+}
+
+func (m *Memory) Set(_ context.Context, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.Values[key] = memoryValue{Value: value}
+	m.changed()
+	return nil
+}
+
+func (m *Memory) Incr(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	if v, ok := m.value(key); ok {
+		var err error
+		if n, err = strconv.ParseInt(v.Value, 10, 64); err != nil {
+			return 0, fmt.Errorf("value of %q is not an integer", key)
+		}
+	}
+	n++
+	m.data.Values[key] = memoryValue{Value: strconv.FormatInt(n, 10)}
+	m.changed()
+	return n, nil
+}
+
+func (m *Memory) ZAdd(_ context.Context, key string, score float64, member string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := m.data.SortedSets[key]
+	for i, mm := range members {
+		if mm.Member == member {
+			members = append(members[:i], members[i+1:]...)
+			break
+		}
+	}
+	// ordered by score and then by member as in redis
+	i := sort.Search(len(members), func(i int) bool {
+		if members[i].Score != score {
+			return members[i].Score > score
+		}
+		return members[i].Member > member
+	})
+	members = append(members, memoryMember{})
+	copy(members[i+1:], members[i:])
+	members[i] = memoryMember{Score: score, Member: member}
+	m.data.SortedSets[key] = members
+	m.changed()
+	return nil
+}
+
+func (m *Memory) ZRange(_ context.Context, key string, min, max float64, reverse bool, limit int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := m.data.SortedSets[key]
+	res := make([]string, 0)
+	for i := range members {
+		mm := members[i]
+		if reverse {
+			mm = members[len(members)-1-i]
+		}
+		if mm.Score < min || mm.Score > max {
+			continue
+		}
+		res = append(res, mm.Member)
+		if limit > 0 && int64(len(res)) == limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (m *Memory) ZRemBelow(_ context.Context, key string, score float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := m.data.SortedSets[key]
+	i := sort.Search(len(members), func(i int) bool { return members[i].Score >= score })
+	if i == 0 {
+		return nil
+	}
+	m.data.SortedSets[key] = append([]memoryMember{}, members[i:]...)
+	m.changed()
+	return nil
+}
+
+func (m *Memory) LRange(_ context.Context, key string, start, stop int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := m.data.Lists[key]
+	n := int64(len(items))
+	// negative indexes count from the end as in redis
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return []string{}, nil
+	}
+	return append([]string{}, items[start:stop+1]...), nil
+}
+
+func (m *Memory) Apply(_ context.Context, b *Batch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range b.checks {
+		if v, _ := m.value(c.key); v.Value != c.value {
+			return ErrConflict
+		}
+	}
+	for _, op := range b.ops {
+		switch op.kind {
+		case batchSet:
+			m.data.Values[op.key] = memoryValue{Value: op.value}
+		case batchLPush:
+			items := append([]string{op.value}, m.data.Lists[op.key]...)
+			if op.maxLen > 0 && int64(len(items)) > op.maxLen {
+				items = items[:op.maxLen]
+			}
+			m.data.Lists[op.key] = items
+		}
+	}
+	m.changed()
+	return nil
+}
+
+func (m *Memory) SetNX(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.value(key); ok {
+		return false, nil
+	}
+	v := memoryValue{Value: value}
+	if ttl > 0 {
+		v.ExpiresAt = m.now().Add(ttl)
+	}
+	m.data.Values[key] = v
+	m.changed()
+	return true, nil
+}
+
+func (m *Memory) Expire(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.value(key)
+	if !ok || v.Value != value {
+		return false, nil
+	}
+	v.ExpiresAt = m.now().Add(ttl)
+	m.data.Values[key] = v
+	m.changed()
+	return true, nil
+}
+
+func (m *Memory) Delete(_ context.Context, key, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.value(key)
+	if !ok || v.Value != value {
+		return false, nil
+	}
+	delete(m.data.Values, key)
+	m.changed()
+	return true, nil
+}
+
+func (m *Memory) Publish(_ context.Context, channel, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for sub := range m.subs[channel] {
+		select {
+		case sub.messages <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Subscribe(_ context.Context, channel string) Subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &memorySubscription{
+		store:    m,
+		channel:  channel,
+		messages: make(chan string, subscriptionBuffer),
+	}
+	if m.subs[channel] == nil {
+		m.subs[channel] = make(map[*memorySubscription]struct{})
+	}
+	m.subs[channel][sub] = struct{}{}
+	return sub
+}
+
+type memorySubscription struct {
+	store    *Memory
+	channel  string
+	messages chan string
+	once     sync.Once
+}
+
+func (s *memorySubscription) Channel() <-chan string {
+	return s.messages
+}
+
+func (s *memorySubscription) Close() error {
+	s.once.Do(func() {
+		s.store.mu.Lock()
+		defer s.store.mu.Unlock()
+
+		delete(s.store.subs[s.channel], s)
+		close(s.messages)
+	})
+	return nil
+}
+
+func (m *Memory) Ping(context.Context) error {
+	return nil
+}
@@ -0,0 +1,300 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryValues(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	if _, err := m.Get(ctx, "key"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := m.Set(ctx, "key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Get(ctx, "key")
+	if err != nil || got != "value" {
+		t.Fatalf("expected value, got %q, %v", got, err)
+	}
+
+	for want := int64(1); want <= 2; want++ {
+		n, err := m.Incr(ctx, "counter")
+		if err != nil || n != want {
+			t.Fatalf("expected %d, got %d, %v", want, n, err)
+		}
+	}
+	if _, err := m.Incr(ctx, "key"); err == nil {
+		t.Fatal("expected an error incrementing a non integer")
+	}
+}
+
+func TestMemorySortedSets(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	for _, mm := range []memoryMember{{3, "c"}, {1, "a"}, {2, "b"}, {4, "d"}} {
+		if err := m.ZAdd(ctx, "set", mm.Score, mm.Member); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// re-adding moves the member
+	if err := m.ZAdd(ctx, "set", 5, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		min, max float64
+		reverse  bool
+		limit    int64
+		want     []string
+	}{
+		{"all", 0, 10, false, 0, []string{"b", "c", "d", "a"}},
+		{"range", 2, 4, false, 0, []string{"b", "c", "d"}},
+		{"reverse with limit", 0, 4, true, 2, []string{"d", "c"}},
+		{"empty", 6, 10, false, 0, []string{}},
+	}
+	for _, tt := range tests {
+		got, err := m.ZRange(ctx, "set", tt.min, tt.max, tt.reverse, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	if err := m.ZRemBelow(ctx, "set", 4); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := m.ZRange(ctx, "set", 0, 10, false, 0)
+	if !reflect.DeepEqual(got, []string{"d", "a"}) {
+		t.Fatalf("expected members scored from 4, got %v", got)
+	}
+}
+
+func TestMemoryBatchAndLists(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	b := &Batch{}
+	for _, v := range []string{"1", "2", "3", "4"} {
+		b.LPush("list", v, 3)
+	}
+	b.Set("key", "value")
+	b.LPush("uncapped", "1", 0)
+	b.LPush("uncapped", "2", 0)
+	if err := m.Apply(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := m.LRange(ctx, "list", 0, -1)
+	if !reflect.DeepEqual(got, []string{"4", "3", "2"}) {
+		t.Fatalf("expected the last 3 pushed first, got %v", got)
+	}
+	got, _ = m.LRange(ctx, "list", -2, 10)
+	if !reflect.DeepEqual(got, []string{"3", "2"}) {
+		t.Fatalf("expected the last 2, got %v", got)
+	}
+	got, _ = m.LRange(ctx, "missing", 0, -1)
+	if len(got) != 0 {
+		t.Fatalf("expected no items, got %v", got)
+	}
+	if v, _ := m.Get(ctx, "key"); v != "value" {
+		t.Fatalf("expected the batch to set the key, got %q", v)
+	}
+	got, _ = m.LRange(ctx, "uncapped", 0, -1)
+	if !reflect.DeepEqual(got, []string{"2", "1"}) {
+		t.Fatalf("expected an uncapped push to keep all items, got %v", got)
+	}
+	if _, err := m.Get(ctx, "uncapped"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected an uncapped push not to set a value, got %v", err)
+	}
+}
+
+func TestMemoryBatchChecks(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	b := &Batch{}
+	b.IfEqual("seq", "")
+	b.Set("seq", "1")
+	if err := m.Apply(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := &Batch{}
+	stale.IfEqual("seq", "")
+	stale.Set("seq", "1")
+	stale.Set("key", "stale")
+	if err := m.Apply(ctx, stale); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if _, err := m.Get(ctx, "key"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a conflicting batch not to write, got %v", err)
+	}
+
+	next := &Batch{}
+	next.IfEqual("seq", "1")
+	next.Set("seq", "2")
+	if err := m.Apply(ctx, next); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := m.Get(ctx, "seq"); v != "2" {
+		t.Fatalf("expected 2, got %q", v)
+	}
+}
+
+func TestMemoryLocks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	if ok, _ := m.SetNX(ctx, "lock", "a", time.Minute); !ok {
+		t.Fatal("expected a to acquire the lock")
+	}
+	if ok, _ := m.SetNX(ctx, "lock", "b", time.Minute); ok {
+		t.Fatal("expected b not to acquire a held lock")
+	}
+	if ok, _ := m.Expire(ctx, "lock", "b", time.Minute); ok {
+		t.Fatal("expected b not to renew the lock of a")
+	}
+
+	now = now.Add(50 * time.Second)
+	if ok, _ := m.Expire(ctx, "lock", "a", time.Minute); !ok {
+		t.Fatal("expected a to renew its lock")
+	}
+	now = now.Add(50 * time.Second)
+	if ok, _ := m.SetNX(ctx, "lock", "b", time.Minute); ok {
+		t.Fatal("expected the renewed lock to be held")
+	}
+
+	now = now.Add(time.Minute)
+	if ok, _ := m.SetNX(ctx, "lock", "b", time.Minute); !ok {
+		t.Fatal("expected b to acquire the expired lock")
+	}
+	if ok, _ := m.Delete(ctx, "lock", "a"); ok {
+		t.Fatal("expected a not to release the lock of b")
+	}
+	if ok, _ := m.Delete(ctx, "lock", "b"); !ok {
+		t.Fatal("expected b to release its lock")
+	}
+	if _, err := m.Get(ctx, "lock"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the lock to be released, got %v", err)
+	}
+}
+
+func TestMemoryPubSub(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	sub := m.Subscribe(ctx, "channel")
+	other := m.Subscribe(ctx, "other")
+	defer other.Close()
+
+	if err := m.Publish(ctx, "channel", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-sub.Channel():
+		if msg != "hello" {
+			t.Fatalf("expected hello, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a message")
+	}
+	select {
+	case msg := <-other.Channel():
+		t.Fatalf("expected no message on the other channel, got %q", msg)
+	default:
+	}
+
+	sub.Close()
+	if _, ok := <-sub.Channel(); ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if err := m.Publish(ctx, "channel", "bye"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFilePersists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	f, err := NewFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set(ctx, "key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ZAdd(ctx, "set", 1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	b := &Batch{}
+	b.LPush("list", "1", 10)
+	if err := f.Apply(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the writes to be saved later, got %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := reopened.Get(ctx, "key"); v != "value" {
+		t.Fatalf("expected value, got %q", v)
+	}
+	if got, _ := reopened.ZRange(ctx, "set", 0, 10, false, 0); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("expected [a], got %v", got)
+	}
+	if got, _ := reopened.LRange(ctx, "list", 0, -1); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Fatalf("expected [1], got %v", got)
+	}
+}
+
+func TestFileSavesAfterDelay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	f, err := NewFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.saveDelay = 10 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		if _, err := f.Incr(ctx, "counter"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	reopened, err := NewFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := reopened.Get(ctx, "counter"); v != "3" {
+		t.Fatalf("expected the writes to be saved at once, got %q", v)
+	}
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	expireIfValueScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`)
+	deleteIfValueScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`)
+)
+
+// Redis is a Store of a redis client.
+type Redis struct {
+	db redis.UniversalClient
+}
+
+func NewRedis(db redis.UniversalClient) *Redis {
+	return &Redis{db: db}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) (string, error) {
+	val, err := r.db.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return val, err
+}
+
+func (r *Redis) Set(ctx context.Context, key, value string) error {
+	return r.db.Set(ctx, key, value, 0).Err()
+}
+
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return r.db.Incr(ctx, key).Result()
+}
+
+func (r *Redis) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	return r.db.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+func (r *Redis) ZRange(ctx context.Context, key string, min, max float64, reverse bool, limit int64) ([]string, error) {
+	by := &redis.ZRangeBy{
+		Min: formatScore(min),
+		Max: formatScore(max),
+	}
+	if limit > 0 {
+		by.Count = limit
+	}
+	if reverse {
+		return r.db.ZRevRangeByScore(ctx, key, by).Result()
+	}
+	return r.db.ZRangeByScore(ctx, key, by).Result()
+}
+
+func (r *Redis) ZRemBelow(ctx context.Context, key string, score float64) error {
+	return r.db.ZRemRangeByScore(ctx, key, "-inf", "("+formatScore(score)).Err()
+}
+
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, -1):
+		return "-inf"
+	case math.IsInf(score, 1):
+		return "+inf"
+	}
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+func (r *Redis) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return r.db.LRange(ctx, key, start, stop).Result()
+}
+
+func (r *Redis) Apply(ctx context.Context, b *Batch) error {
+	if len(b.checks) == 0 {
+		_, err := r.db.TxPipelined(ctx, queueBatch(ctx, b))
+		return err
+	}
+
+	keys := make([]string, 0, len(b.checks))
+	for _, c := range b.checks {
+		keys = append(keys, c.key)
+	}
+	err := r.db.Watch(ctx, func(tx *redis.Tx) error {
+		for _, c := range b.checks {
+			val, err := tx.Get(ctx, c.key).Result()
+			if errors.Is(err, redis.Nil) {
+				val, err = "", nil
+			}
+			if err != nil {
+				return err
+			}
+			if val != c.value {
+				return ErrConflict
+			}
+		}
+		_, err := tx.TxPipelined(ctx, queueBatch(ctx, b))
+		return err
+	}, keys...)
+	if errors.Is(err, redis.TxFailedErr) {
+		return ErrConflict
+	}
+	return err
+}
+
+func queueBatch(ctx context.Context, b *Batch) func(redis.Pipeliner) error {
+	return func(pipe redis.Pipeliner) error {
+		for _, op := range b.ops {
+			switch op.kind {
+			case batchSet:
+				pipe.Set(ctx, op.key, op.value, 0)
+			case batchLPush:
+				pipe.LPush(ctx, op.key, op.value)
+				if op.maxLen > 0 {
+					pipe.LTrim(ctx, op.key, 0, op.maxLen-1)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func (r *Redis) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return r.db.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (r *Redis) Expire(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	res, err := expireIfValueScript.Run(ctx, r.db, []string{key}, value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+func (r *Redis) Delete(ctx context.Context, key, value string) (bool, error) {
+	res, err := deleteIfValueScript.Run(ctx, r.db, []string{key}, value).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+func (r *Redis) Publish(ctx context.Context, channel, message string) error {
+	return r.db.Publish(ctx, channel, message).Err()
+}
+
+func (r *Redis) Subscribe(ctx context.Context, channel string) Subscription {
+	pubsub := r.db.Subscribe(ctx, channel)
+	sub := &redisSubscription{
+		pubsub:   pubsub,
+		messages: make(chan string),
+	}
+	go func() {
+		defer close(sub.messages)
+		for msg := range pubsub.Channel() {
+			sub.messages <- msg.Payload
+		}
+	}()
+	return sub
+}
+
+type redisSubscription struct {
+	pubsub   *redis.PubSub
+	messages chan string
+}
+
+func (s *redisSubscription) Channel() <-chan string {
+	return s.messages
+}
+
+func (s *redisSubscription) Close() error {
+	return s.pubsub.Close()
+}
+
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.db.Ping(ctx).Err()
+}
+
+func (r *Redis) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,146 @@
+// Package store abstracts the storage of the pricer so it can run on Redis, in
+// memory or on a local file.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotFound is returned when a key does not exist.
+	ErrNotFound = errors.New("key not found")
+	// ErrConflict is returned by Apply when a key checked by the batch changed.
+	ErrConflict = errors.New("key changed")
+)
+
+// Store is a key value store with sorted sets, capped lists, counters, locks and pub/sub.
+type Store interface {
+	// Get returns ErrNotFound when the key does not exist.
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key, value string) error
+	Incr(ctx context.Context, key string) (int64, error)
+
+	// ZAdd adds a member to the sorted set scored by score.
+	ZAdd(ctx context.Context, key string, score float64, member string) error
+	// ZRange returns the members scored between min and max, inclusive, in the
+	// ascending order or the descending one when reverse. Limit is ignored unless positive.
+	ZRange(ctx context.Context, key string, min, max float64, reverse bool, limit int64) ([]string, error)
+	// ZRemBelow removes the members scored below score.
+	ZRemBelow(ctx context.Context, key string, score float64) error
+
+	// LRange returns the list items from start to stop, inclusive, the last pushed first.
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+
+	// Apply writes the batch atomically, unless a key checked by the batch changed
+	// in which case ErrConflict is returned and nothing is written.
+	Apply(ctx context.Context, b *Batch) error
+
+	// SetNX sets the key expiring after ttl unless it exists.
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Expire extends the ttl of the key if it still holds the value.
+	Expire(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Delete removes the key if it still holds the value.
+	Delete(ctx context.Context, key, value string) (bool, error)
+
+	Publish(ctx context.Context, channel, message string) error
+	// Subscribe delivers the messages published to the channel until the subscription is closed.
+	Subscribe(ctx context.Context, channel string) Subscription
+
+	Ping(ctx context.Context) error
+	// Close releases the store, saving the pending writes of the file store.
+	Close() error
+}
+
+// Subscription delivers the messages of a channel.
+type Subscription interface {
+	Channel() <-chan string
+	Close() error
+}
+
+type batchOpKind int
+
+const (
+	batchSet batchOpKind = iota
+	batchLPush
+)
+
+type batchOp struct {
+	kind       batchOpKind
+	key, value string
+	// maxLen caps the list of a push, unless 0.
+	maxLen int64
+}
+
+type batchCheck struct {
+	key, value string
+}
+
+// Batch holds writes applied atomically by Store.Apply.
+type Batch struct {
+	checks []batchCheck
+	ops    []batchOp
+}
+
+// IfEqual applies the batch only while the key holds the value, or does not exist
+// when the value is empty.
+func (b *Batch) IfEqual(key, value string) {
+	b.checks = append(b.checks, batchCheck{key: key, value: value})
+}
+
+func (b *Batch) Set(key, value string) {
+	b.ops = append(b.ops, batchOp{kind: batchSet, key: key, value: value})
+}
+
+// LPush pushes the value onto the list keeping at most maxLen of the last pushed
+// items, all of them when maxLen is not positive.
+func (b *Batch) LPush(key, value string, maxLen int64) {
+	if maxLen < 0 {
+		maxLen = 0
+	}
+	b.ops = append(b.ops, batchOp{kind: batchLPush, key: key, value: value, maxLen: maxLen})
+}
+
+const (
+	TypeRedis  = "redis"
+	TypeMemory = "memory"
+	TypeFile   = "file"
+)
+
+// Options selects the store to open.
+type Options struct {
+	Type string
+	// File is the path of the file store.
+	File  string
+	Redis *redis.UniversalOptions
+}
+
+// Open opens the store. Redis is pinged to make sure it is reachable.
+func Open(opts Options) (Store, error) {
+	switch opts.Type {
+	case TypeRedis, "":
+		if opts.Redis == nil || len(opts.Redis.Addrs) == 0 {
+			return nil, errors.New("redis store requires REDIS_ADDRESS")
+		}
+		st := NewRedis(redis.NewUniversalClient(opts.Redis))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := st.Ping(ctx); err != nil {
+			return nil, fmt.Errorf("could not reach redis: %w", err)
+		}
+		return st, nil
+	case TypeMemory:
+		return NewMemory(), nil
+	case TypeFile:
+		if opts.File == "" {
+			return nil, errors.New("file store requires STORE_FILE")
+		}
+		return NewFile(opts.File)
+	default:
+		return nil, fmt.Errorf("unknown store %q", opts.Type)
+	}
+}
@@ -0,0 +1,108 @@
+package aggregate
+
+// CountryBreakdown selects the optional breakdowns of CountCountries.
+type CountryBreakdown struct {
+	IPType      bool
+	ServiceType bool
+	Quality     bool
+}
+
+// CountryNumbers holds the number of unique providers in a country.
+type CountryNumbers struct {
+	Providers    int            `json:"providers"`
+	IPTypes      map[string]int `json:"ip_types,omitempty"`
+	ServiceTypes map[string]int `json:"service_types,omitempty"`
+	Quality      map[string]int `json:"quality,omitempty"`
+}
+
+// Quality buckets used by the country quality breakdown.
+const (
+	QualityBucketUnknown = "unknown"
+	QualityBucketLow     = "low"
+	QualityBucketMedium  = "medium"
+	QualityBucketHigh    = "high"
+)
+
+// CountCountries counts unique providers per country. A provider offering services
+// from several countries is counted in each of them. When serviceType is given,
+// only that service of the provider is considered.
+func CountCountries(proposals []Proposal, serviceType string, breakdown CountryBreakdown) map[string]*CountryNumbers {
+	res := make(map[string]*CountryNumbers)
+
+	for _, p := range proposals {
+		seen := make(map[string]map[string]struct{})
+		for _, s := range p.Services {
+			if serviceType != "" && s.ServiceType != serviceType {
+				continue
+			}
+
+			service := p.getService(s.ServiceType)
+			if service.Location == nil || service.Location.Country == "" {
+				continue
+			}
+			country := service.Location.Country
+
+			numbers, ok := res[country]
+			if !ok {
+				numbers = &CountryNumbers{}
+				if breakdown.IPType {
+					numbers.IPTypes = make(map[string]int)
+				}
+				if breakdown.ServiceType {
+					numbers.ServiceTypes = make(map[string]int)
+				}
+				if breakdown.Quality {
+					numbers.Quality = make(map[string]int)
+				}
+				res[country] = numbers
+			}
+
+			keys, ok := seen[country]
+			if !ok {
+				keys = make(map[string]struct{})
+				seen[country] = keys
+				numbers.Providers++
+			}
+
+			if breakdown.IPType {
+				countOnce(numbers.IPTypes, keys, "ip_type:", string(service.Location.IPType))
+			}
+			if breakdown.ServiceType {
+				countOnce(numbers.ServiceTypes, keys, "service_type:", s.ServiceType)
+			}
+			if breakdown.Quality {
+				quality := p.Quality
+				if serviceType != "" {
+					quality = service.Quality
+				}
+
+				bucket := QualityBucketUnknown
+				if quality != nil {
+					bucket = qualityBucket(quality.Quality)
+				}
+				countOnce(numbers.Quality, keys, "quality:", bucket)
+			}
+		}
+	}
+
+	return res
+}
+
+func countOnce(counts map[string]int, seen map[string]struct{}, prefix, key string) {
+	if _, ok := seen[prefix+key]; ok {
+		return
+	}
+	seen[prefix+key] = struct{}{}
+	counts[key]++
+}
+
+func qualityBucket(quality float64) string {
+	switch {
+	case quality < 1:
+		return QualityBucketLow
+	case quality < 2:
+		return QualityBucketMedium
+	default:
+		return QualityBucketHigh
+	}
+}
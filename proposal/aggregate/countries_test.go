@@ -0,0 +1,61 @@
+package aggregate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
+)
+
+func TestCountCountries(t *testing.T) {
+	proposals := []Proposal{
+		{
+			ProviderID: "0x1",
+			Meta: Meta{
+				Quality:  &v3.Quality{Quality: 2.5},
+				Location: &v3.Location{Country: "US", IPType: "residential"},
+			},
+			Services: []ProviderService{
+				{ServiceType: "wireguard", Meta: &Meta{Quality: &v3.Quality{Quality: 0.5}}},
+				{ServiceType: "scraping"},
+				{ServiceType: "dvpn", Meta: &Meta{Location: &v3.Location{Country: "CA", IPType: "hosting"}}},
+			},
+		},
+		{
+			ProviderID: "0x2",
+			Meta: Meta{
+				Location: &v3.Location{Country: "US", IPType: "hosting"},
+			},
+			Services: []ProviderService{
+				{ServiceType: "wireguard"},
+			},
+		},
+	}
+
+	res := CountCountries(proposals, "", CountryBreakdown{IPType: true, ServiceType: true, Quality: true})
+
+	require.Equal(t, map[string]*CountryNumbers{
+		"US": {
+			Providers:    2,
+			IPTypes:      map[string]int{"residential": 1, "hosting": 1},
+			ServiceTypes: map[string]int{"wireguard": 2, "scraping": 1},
+			Quality:      map[string]int{QualityBucketHigh: 1, QualityBucketUnknown: 1},
+		},
+		"CA": {
+			Providers:    1,
+			IPTypes:      map[string]int{"hosting": 1},
+			ServiceTypes: map[string]int{"dvpn": 1},
+			Quality:      map[string]int{QualityBucketHigh: 1},
+		},
+	}, res)
+
+	res = CountCountries(proposals, "wireguard", CountryBreakdown{Quality: true})
+
+	require.Equal(t, map[string]*CountryNumbers{
+		"US": {
+			Providers: 2,
+			Quality:   map[string]int{QualityBucketLow: 1, QualityBucketUnknown: 1},
+		},
+	}, res)
+}
@@ -1,6 +1,9 @@
 package aggregate
 
 import (
+	"fmt"
+
+	v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
 	"github.com/mysteriumnetwork/discovery/quality/oracleapi"
 )
 
@@ -10,6 +13,46 @@ type Filters struct {
 	QualityMin              float64
 	BandwidthMin            float64
 	PresetID                int
+	// ServiceType makes the filters above target the quality of the given service
+	// instead of the aggregated quality of the provider.
+	ServiceType string
+}
+
+// QualityAggregation defines how the provider's quality is computed from the quality of its services.
+type QualityAggregation string
+
+const (
+	// QualityAggregationMax takes the quality of the best service.
+	QualityAggregationMax QualityAggregation = "max"
+	// QualityAggregationMean averages the quality of all services.
+	QualityAggregationMean QualityAggregation = "mean"
+	// QualityAggregationWeighted averages the quality of all services using per service type weights.
+	QualityAggregationWeighted QualityAggregation = "weighted"
+)
+
+type QualityAggregator struct {
+	Mode QualityAggregation
+	// Weights by service type, used by the weighted mode. Service types without weight count as 1.
+	Weights map[string]float64
+}
+
+func NewQualityAggregator(mode string, weights map[string]float64) (QualityAggregator, error) {
+	switch QualityAggregation(mode) {
+	case QualityAggregationMax, QualityAggregationMean, QualityAggregationWeighted:
+	default:
+		return QualityAggregator{}, fmt.Errorf("unknown quality aggregation %q", mode)
+	}
+
+	for serviceType, weight := range weights {
+		if weight < 0 {
+			return QualityAggregator{}, fmt.Errorf("weight of %v should be non negative", serviceType)
+		}
+	}
+
+	return QualityAggregator{
+		Mode:    QualityAggregation(mode),
+		Weights: weights,
+	}, nil
 }
 
 var emptyQuality = oracleapi.DetailedQuality{
@@ -17,45 +60,71 @@ var emptyQuality = oracleapi.DetailedQuality{
 	RestrictedNode:   true,
 }
 
-func EnhanceWithMetrics(proposals []Proposal, or map[string]*oracleapi.DetailedQuality, f Filters) (res []Proposal) {
+type serviceQuality struct {
+	serviceType string
+	quality     *oracleapi.DetailedQuality
+}
+
+func EnhanceWithMetrics(proposals []Proposal, or map[string]*oracleapi.DetailedQuality, f Filters, agg QualityAggregator) (res []Proposal) {
 	for _, p := range proposals {
 		if len(or) == 0 {
 			res = append(res, p)
 			continue
 		}
 
-		q := &emptyQuality
-		for _, s := range []string{"data_transfer", "scraping", "wireguard", "dvpn", "monitoring"} {
-			if pq, ok := or[p.ProviderID+"."+s]; ok {
-				q = pq
-				break
+		var (
+			found    []serviceQuality
+			target   *oracleapi.DetailedQuality
+			services = make([]ProviderService, 0, len(p.Services))
+		)
+		for _, s := range p.Services {
+			q, ok := or[qualityKey(p.ProviderID, s.ServiceType)]
+			if ok {
+				found = append(found, serviceQuality{serviceType: s.ServiceType, quality: q})
+			} else {
+				q = &emptyQuality
+			}
+			if s.ServiceType == f.ServiceType {
+				target = q
+			}
+
+			meta := Meta{}
+			if s.Meta != nil {
+				meta = *s.Meta
 			}
+			meta.Quality = toQuality(q)
+			s.Meta = &meta
+			services = append(services, s)
 		}
+		p.Services = services
 
-		if f.NATCompatibility == "symmetric" && q.RestrictedNode {
-			continue
+		aggregated := agg.aggregate(found)
+		p.Quality = toQuality(aggregated)
+
+		location := p.Location
+		if target == nil {
+			target = aggregated
+		} else if service := p.getService(f.ServiceType); service != nil {
+			location = service.Location
 		}
 
-		if !f.IncludeMonitoringFailed && q.MonitoringFailed {
+		if f.NATCompatibility == "symmetric" && target.RestrictedNode {
 			continue
 		}
 
-		if q.Quality < f.QualityMin {
+		if !f.IncludeMonitoringFailed && target.MonitoringFailed {
 			continue
 		}
 
-		if f.BandwidthMin > 0 && q.Bandwidth < f.BandwidthMin {
+		if target.Quality < f.QualityMin {
 			continue
 		}
 
-		p.Quality.Quality = q.Quality
-		p.Quality.Latency = q.Latency
-		p.Quality.Bandwidth = q.Bandwidth
-		p.Quality.Uptime = q.Uptime
-		p.Quality.PacketLoss = q.PacketLoss
-		p.Quality.MonitoringFailed = q.MonitoringFailed
+		if f.BandwidthMin > 0 && target.Bandwidth < f.BandwidthMin {
+			continue
+		}
 
-		if !matchPreset(f.PresetID, p) {
+		if !matchPreset(f.PresetID, location, target) {
 			continue
 		}
 
@@ -65,18 +134,107 @@ func EnhanceWithMetrics(proposals []Proposal, or map[string]*oracleapi.DetailedQ
 	return res
 }
 
-func matchPreset(presetID int, p Proposal) bool {
+// aggregate computes the provider's quality from the quality of its services.
+// Services with failed monitoring are ignored unless all of them failed.
+func (a QualityAggregator) aggregate(qualities []serviceQuality) *oracleapi.DetailedQuality {
+	if len(qualities) == 0 {
+		return &emptyQuality
+	}
+
+	var considered []serviceQuality
+	for _, sq := range qualities {
+		if !sq.quality.MonitoringFailed {
+			considered = append(considered, sq)
+		}
+	}
+	if len(considered) == 0 {
+		considered = qualities
+	}
+
+	res := oracleapi.DetailedQuality{MonitoringFailed: true}
+	for _, sq := range considered {
+		res.MonitoringFailed = res.MonitoringFailed && sq.quality.MonitoringFailed
+		res.RestrictedNode = res.RestrictedNode || sq.quality.RestrictedNode
+	}
+
+	if a.Mode == QualityAggregationMax || a.Mode == "" {
+		best := considered[0].quality
+		for _, sq := range considered[1:] {
+			if sq.quality.Quality > best.Quality {
+				best = sq.quality
+			}
+		}
+		res.Quality = best.Quality
+		res.Latency = best.Latency
+		res.Uptime = best.Uptime
+		res.Bandwidth = best.Bandwidth
+		res.PacketLoss = best.PacketLoss
+		return &res
+	}
+
+	var total float64
+	for _, sq := range considered {
+		weight := 1.0
+		if a.Mode == QualityAggregationWeighted {
+			if w, ok := a.Weights[sq.serviceType]; ok {
+				weight = w
+			}
+		}
+		total += weight
+		res.Quality += weight * sq.quality.Quality
+		res.Latency += weight * sq.quality.Latency
+		res.Uptime += weight * sq.quality.Uptime
+		res.Bandwidth += weight * sq.quality.Bandwidth
+		res.PacketLoss += weight * sq.quality.PacketLoss
+	}
+	if total == 0 {
+		return QualityAggregator{Mode: QualityAggregationMean}.aggregate(qualities)
+	}
+
+	res.Quality /= total
+	res.Latency /= total
+	res.Uptime /= total
+	res.Bandwidth /= total
+	res.PacketLoss /= total
+	return &res
+}
+
+func qualityKey(providerID, serviceType string) string {
+	if serviceType == "quic_scraping" {
+		// TODO: remove this once we have proper service type for scraping
+		serviceType = "scraping"
+	}
+	return providerID + "." + serviceType
+}
+
+func toQuality(q *oracleapi.DetailedQuality) *v3.Quality {
+	return &v3.Quality{
+		Quality:          q.Quality,
+		Latency:          q.Latency,
+		Bandwidth:        q.Bandwidth,
+		Uptime:           q.Uptime,
+		PacketLoss:       q.PacketLoss,
+		MonitoringFailed: q.MonitoringFailed,
+	}
+}
+
+func matchPreset(presetID int, location *v3.Location, q *oracleapi.DetailedQuality) bool {
+	var ipType v3.IPType
+	if location != nil {
+		ipType = location.IPType
+	}
+
 	switch presetID {
 	case 1:
-		if p.Location.IPType != "residential" || p.Quality.Quality < 1 || p.Quality.Bandwidth < 5 {
+		if ipType != "residential" || q.Quality < 1 || q.Bandwidth < 5 {
 			return false
 		}
 	case 2:
-		if p.Quality.Quality < 1 {
+		if q.Quality < 1 {
 			return false
 		}
 	case 3:
-		if p.Location.IPType != "hosting" {
+		if ipType != "hosting" {
 			return false
 		}
 	}
@@ -0,0 +1,95 @@
+package aggregate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/discovery/quality/oracleapi"
+)
+
+func testProposal() Proposal {
+	return Proposal{
+		ProviderID: "0x1",
+		Services: []ProviderService{
+			{ServiceType: "wireguard"},
+			{ServiceType: "scraping"},
+			{ServiceType: "dvpn"},
+		},
+	}
+}
+
+var testQuality = map[string]*oracleapi.DetailedQuality{
+	"0x1.wireguard": {Quality: 1, Bandwidth: 10},
+	"0x1.scraping":  {Quality: 3, Bandwidth: 2},
+	"0x1.dvpn":      {Quality: 2.5, MonitoringFailed: true},
+}
+
+func TestEnhanceWithMetricsAttachesServiceQuality(t *testing.T) {
+	res := EnhanceWithMetrics([]Proposal{testProposal()}, testQuality, Filters{IncludeMonitoringFailed: true}, QualityAggregator{})
+
+	require.Len(t, res, 1)
+	require.Equal(t, float64(1), res[0].Services[0].Quality.Quality)
+	require.Equal(t, float64(3), res[0].Services[1].Quality.Quality)
+	require.True(t, res[0].Services[2].Quality.MonitoringFailed)
+	require.Equal(t, float64(3), res[0].Quality.Quality)
+	require.False(t, res[0].Quality.MonitoringFailed)
+}
+
+func TestQualityAggregator(t *testing.T) {
+	tests := []struct {
+		name          string
+		aggregator    QualityAggregator
+		wantQuality   float64
+		wantBandwidth float64
+	}{
+		{
+			name:          "max takes the best service",
+			aggregator:    QualityAggregator{Mode: QualityAggregationMax},
+			wantQuality:   3,
+			wantBandwidth: 2,
+		},
+		{
+			name:          "mean ignores services with failed monitoring",
+			aggregator:    QualityAggregator{Mode: QualityAggregationMean},
+			wantQuality:   2,
+			wantBandwidth: 6,
+		},
+		{
+			name: "weighted uses service type weights",
+			aggregator: QualityAggregator{
+				Mode:    QualityAggregationWeighted,
+				Weights: map[string]float64{"wireguard": 3},
+			},
+			wantQuality:   1.5,
+			wantBandwidth: 8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := EnhanceWithMetrics([]Proposal{testProposal()}, testQuality, Filters{}, tt.aggregator)
+
+			require.Len(t, res, 1)
+			require.InDelta(t, tt.wantQuality, res[0].Quality.Quality, 0.0000001)
+			require.InDelta(t, tt.wantBandwidth, res[0].Quality.Bandwidth, 0.0000001)
+		})
+	}
+}
+
+func TestEnhanceWithMetricsFiltersTargetService(t *testing.T) {
+	proposals := []Proposal{testProposal()}
+
+	require.Len(t, EnhanceWithMetrics(proposals, testQuality, Filters{QualityMin: 2}, QualityAggregator{}), 1)
+	require.Empty(t, EnhanceWithMetrics(proposals, testQuality, Filters{QualityMin: 2, ServiceType: "wireguard"}, QualityAggregator{}))
+	require.Len(t, EnhanceWithMetrics(proposals, testQuality, Filters{BandwidthMin: 5, ServiceType: "wireguard"}, QualityAggregator{}), 1)
+	require.Empty(t, EnhanceWithMetrics(proposals, testQuality, Filters{BandwidthMin: 5, ServiceType: "scraping"}, QualityAggregator{}))
+	require.Empty(t, EnhanceWithMetrics(proposals, testQuality, Filters{ServiceType: "dvpn"}, QualityAggregator{}))
+}
+
+func TestNewQualityAggregatorRejectsUnknownMode(t *testing.T) {
+	_, err := NewQualityAggregator("median", nil)
+	require.Error(t, err)
+
+	_, err = NewQualityAggregator("weighted", map[string]float64{"wireguard": -1})
+	require.Error(t, err)
+}
@@ -17,29 +17,27 @@ type Repository struct {
 	expirationJobDelay           time.Duration
 	expirationDuration           time.Duration
 	mu                           sync.RWMutex
-	proposals                    map[string]record
+	proposals                    map[string]Proposal
 	proposalsHardLimitPerCountry int
 	proposalsSoftLimitPerCountry int
 }
 
 type RepoListOpts struct {
-	ProviderIDS  []string
-	ServiceType  string
-	Country      string
-	IpType       string
-	AccessPolicy string
-}
-
-type record struct {
-	proposal  Proposal
-	expiresAt time.Time
+	ProviderIDS        []string
+	ServiceType        string
+	Country            string
+	IpType             string
+	AccessPolicy       string
+	AccessPolicySource string
+	CompatibilityMin   int
+	CompatibilityMax   int
 }
 
 func NewRepository(proposalsHardLimitPerCountry, proposalsSoftLimitPerCountry int) *Repository {
 	return &Repository{
 		expirationDuration:           3*time.Minute + 10*time.Second,
 		expirationJobDelay:           20 * time.Second,
-		proposals:                    make(map[string]record),
+		proposals:                    make(map[string]Proposal),
 		proposalsHardLimitPerCountry: proposalsHardLimitPerCountry,
 		proposalsSoftLimitPerCountry: proposalsSoftLimitPerCountry,
 	}
@@ -55,7 +53,7 @@ func (r *Repository) List(opts RepoListOpts) (res []Proposal) {
 		// short path: skip iteration over collection,
 		// lookup specific entries in reduced collection
 		// instead
-		proposals = make(map[string]record)
+		proposals = make(map[string]Proposal)
 		for _, reqProviderID := range opts.ProviderIDS {
 			if proposalFound, ok := r.proposals[reqProviderID]; ok {
 				proposals[reqProviderID] = proposalFound
@@ -64,52 +62,94 @@ func (r *Repository) List(opts RepoListOpts) (res []Proposal) {
 	}
 
 	for _, p := range proposals {
-		if !match(p.proposal, opts) {
+		if !match(p, opts) {
 			continue
 		}
-		res = append(res, p.proposal)
+		res = append(res, p)
 	}
 
 	return res
 }
 
+// Store stores a proposal received in the native v4 format.
+func (r *Repository) Store(proposal Proposal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store(proposal)
+	return nil
+}
+
 func (r *Repository) StoreV3(proposalV3 v3.Proposal) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	proposal := removeOldBrokerIPs(fromV3(proposalV3))
+	r.store(fromV3(proposalV3))
+	return nil
+}
 
-	if existing, ok := r.proposals[proposal.ProviderID]; ok {
-		existing.proposal.mergeProposal(proposal)
-		r.proposals[proposal.ProviderID] = record{
-			proposal:  existing.proposal,
-			expiresAt: time.Now().Add(r.expirationDuration),
-		}
-		return nil
+func (r *Repository) store(proposal Proposal) {
+	proposal = removeOldBrokerIPs(proposal)
+
+	expiresAt := time.Now().Add(r.expirationDuration)
+	services := make([]ProviderService, len(proposal.Services))
+	for i, s := range proposal.Services {
+		s.expiresAt = expiresAt
+		services[i] = s
 	}
+	proposal.Services = services
 
-	r.proposals[proposal.ProviderID] = record{
-		proposal:  proposal,
-		expiresAt: time.Now().Add(r.expirationDuration),
+	if existing, ok := r.proposals[proposal.ProviderID]; ok {
+		existing.mergeProposal(proposal)
+		r.proposals[proposal.ProviderID] = existing
+		return
 	}
-	return nil
+
+	r.proposals[proposal.ProviderID] = proposal
 }
 
+// Expire removes the expired services and the providers which have no services left.
+// It returns the number of expired services.
 func (r *Repository) Expire() (count int64) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	now := time.Now()
 	for k, v := range r.proposals {
-		if time.Now().After(v.expiresAt) {
+		p, removed := v.removeServices(func(s ProviderService) bool {
+			return now.After(s.expiresAt)
+		})
+		if removed == 0 {
+			continue
+		}
+
+		count += int64(removed)
+		if len(p.Services) == 0 {
 			delete(r.proposals, k)
-			count++
+		} else {
+			r.proposals[k] = p
 		}
 	}
 	return count
 }
 
 func removeOldBrokerIPs(proposal Proposal) Proposal {
-	for i, c := range *proposal.Contacts {
+	removeOldBrokerIPsFromContacts(proposal.Contacts)
+	for _, s := range proposal.Services {
+		if s.Meta != nil {
+			removeOldBrokerIPsFromContacts(s.Meta.Contacts)
+		}
+	}
+
+	return proposal
+}
+
+func removeOldBrokerIPsFromContacts(contacts *[]v3.Contact) {
+	if contacts == nil {
+		return
+	}
+
+	for i, c := range *contacts {
 		if c.Type != "nats/p2p/v1" {
 			continue
 		}
@@ -144,20 +184,40 @@ func removeOldBrokerIPs(proposal Proposal) Proposal {
 
 		msg := json.RawMessage(`{"broker_addresses":` + string(result) + `}`)
 
-		(*proposal.Contacts)[i] = v3.Contact{
+		(*contacts)[i] = v3.Contact{
 			Type:       c.Type,
 			Definition: &msg,
 		}
 	}
+}
 
-	return proposal
+// Remove removes a single service of the provider. The provider is removed
+// together with its last service.
+func (r *Repository) Remove(providerID, serviceType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.proposals[providerID]
+	if !ok {
+		return
+	}
+
+	p, _ := existing.removeServices(func(s ProviderService) bool {
+		return s.ServiceType == serviceType
+	})
+	if len(p.Services) == 0 {
+		delete(r.proposals, providerID)
+		return
+	}
+	r.proposals[providerID] = p
 }
 
-func (r *Repository) Remove(key string) {
+// RemoveProvider removes the provider with all of its services.
+func (r *Repository) RemoveProvider(providerID string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	delete(r.proposals, key)
+	delete(r.proposals, providerID)
 }
 
 func match(p Proposal, opts RepoListOpts) bool {
@@ -176,7 +236,7 @@ func match(p Proposal, opts RepoListOpts) bool {
 		}
 	}
 
-	service := &ProviderService{}
+	service := &ProviderService{Meta: &p.Meta}
 	if opts.ServiceType != "" {
 		service = p.getService(opts.ServiceType)
 		if service == nil {
@@ -184,11 +244,24 @@ func match(p Proposal, opts RepoListOpts) bool {
 		}
 	}
 
-	if opts.Country != "" && service.Location.Country != opts.Country {
+	location := v3.Location{}
+	if service.Location != nil {
+		location = *service.Location
+	}
+
+	if opts.Country != "" && location.Country != opts.Country {
 		return false
 	}
 
-	if opts.IpType != "" && service.Location.IPType != v3.IPType(opts.IpType) {
+	if opts.IpType != "" && location.IPType != v3.IPType(opts.IpType) {
+		return false
+	}
+
+	if opts.CompatibilityMin != 0 && p.Compatibility < opts.CompatibilityMin {
+		return false
+	}
+
+	if opts.CompatibilityMax != 0 && p.Compatibility > opts.CompatibilityMax {
 		return false
 	}
 
@@ -208,12 +281,27 @@ func match(p Proposal, opts RepoListOpts) bool {
 		return false
 	}
 
+	if opts.AccessPolicySource != "" {
+		found := false
+
+		for _, v := range p.AccessPolicies {
+			if v.Source == opts.AccessPolicySource {
+				found = true
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
 	return true
 }
 
 func fromV3(p v3.Proposal) Proposal {
 	return Proposal{
 		ProviderID:     p.ProviderID,
+		Compatibility:  p.Compatibility,
 		AccessPolicies: p.AccessPolicies,
 		Meta: Meta{
 			Quality:  &p.Quality,
@@ -222,6 +310,10 @@ func fromV3(p v3.Proposal) Proposal {
 		},
 		Services: []ProviderService{
 			{
+				Meta: &Meta{
+					Location: &p.Location,
+					Contacts: &p.Contacts,
+				},
 				ServiceType: p.ServiceType,
 			},
 		},
@@ -1,10 +1,36 @@
 package aggregate
 
-import v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
+import (
+	"reflect"
+	"time"
+
+	v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
+)
+
+// ProposalPingMessage is the native v4 register/ping message which carries
+// a provider with all of its services at once.
+type ProposalPingMessage struct {
+	Proposal Proposal `json:"proposal"`
+}
+
+func (p ProposalPingMessage) IsEmpty() bool {
+	return reflect.DeepEqual(p, ProposalPingMessage{})
+}
+
+// ProposalUnregisterMessage unregisters the listed services of a provider,
+// or the whole provider when no services are listed.
+type ProposalUnregisterMessage struct {
+	Proposal Proposal `json:"proposal"`
+}
+
+func (p ProposalUnregisterMessage) IsEmpty() bool {
+	return reflect.DeepEqual(p, ProposalUnregisterMessage{})
+}
 
 type Proposal struct {
 	Meta           `json:",inline"`
 	ProviderID     string            `json:"provider_id"`
+	Compatibility  int               `json:"compatibility,omitempty"`
 	AccessPolicies []v3.AccessPolicy `json:"access_policies,omitempty"`
 
 	Services []ProviderService `json:"services"`
@@ -13,6 +39,8 @@ type Proposal struct {
 type ProviderService struct {
 	*Meta       `json:",inline,omitempty"`
 	ServiceType string `json:"service_type"`
+
+	expiresAt time.Time
 }
 
 type Meta struct {
@@ -30,36 +58,73 @@ func NewProposal(providerID string) *Proposal {
 func (p *Proposal) mergeProposal(newProposal Proposal) {
 	// Update provider's meta with the latest data
 	p.Meta = newProposal.Meta
+	p.Compatibility = newProposal.Compatibility
+	p.AccessPolicies = newProposal.AccessPolicies
+
+	// Services are copied as listed proposals may still share the old slice.
+	services := make([]ProviderService, len(p.Services), len(p.Services)+len(newProposal.Services))
+	copy(services, p.Services)
+	p.Services = services
 
 	for _, s := range newProposal.Services {
 		p.addService(s)
 	}
 }
 
+// addService adds a new service or refreshes the meta and expiration of an existing one.
 func (p *Proposal) addService(service ProviderService) {
-	for _, s := range p.Services {
+	for i, s := range p.Services {
 		if s.ServiceType == service.ServiceType {
+			if service.Meta != nil {
+				p.Services[i].Meta = service.Meta
+			}
+			p.Services[i].expiresAt = service.expiresAt
 			return
 		}
 	}
-	p.Services = append(p.Services, ProviderService{
-		ServiceType: service.ServiceType,
-	})
+	p.Services = append(p.Services, service)
 }
 
+// removeServices returns a copy of the proposal without the services matching the predicate
+// and the number of removed services.
+func (p Proposal) removeServices(remove func(ProviderService) bool) (Proposal, int) {
+	var services []ProviderService
+	for _, s := range p.Services {
+		if remove(s) {
+			continue
+		}
+		services = append(services, s)
+	}
+
+	removed := len(p.Services) - len(services)
+	p.Services = services
+	return p, removed
+}
+
+// getService returns a copy of the service with its meta completed
+// by the provider's meta where the service does not define its own.
 func (p *Proposal) getService(serviceType string) *ProviderService {
 	for _, s := range p.Services {
-		if s.ServiceType == serviceType {
-			if s.Meta.Contacts == nil {
-				s.Meta.Contacts = p.Meta.Contacts
+		if s.ServiceType != serviceType {
+			continue
+		}
+
+		meta := p.Meta
+		if s.Meta != nil {
+			if s.Meta.Contacts != nil {
+				meta.Contacts = s.Meta.Contacts
 			}
-			if s.Meta.Location == nil {
-				s.Meta.Location = p.Meta.Location
+			if s.Meta.Location != nil {
+				meta.Location = s.Meta.Location
 			}
-			if s.Meta.Quality == nil {
-				s.Meta.Quality = p.Meta.Quality
+			if s.Meta.Quality != nil {
+				meta.Quality = s.Meta.Quality
 			}
-			return &s
+		}
+
+		return &ProviderService{
+			Meta:        &meta,
+			ServiceType: s.ServiceType,
 		}
 	}
 	return nil
@@ -0,0 +1,121 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
+)
+
+func TestRepositoryStoreV3KeepsServiceMeta(t *testing.T) {
+	repo := NewRepository(100, 100)
+
+	require.NoError(t, repo.StoreV3(v3.Proposal{
+		ProviderID:  "0x1",
+		ServiceType: "wireguard",
+		Location:    v3.Location{Country: "DE", IPType: "residential"},
+	}))
+	require.NoError(t, repo.StoreV3(v3.Proposal{
+		ProviderID:  "0x1",
+		ServiceType: "scraping",
+		Location:    v3.Location{Country: "FR", IPType: "hosting"},
+	}))
+
+	res := repo.List(RepoListOpts{ServiceType: "wireguard", Country: "DE"})
+	require.Len(t, res, 1)
+	require.Len(t, res[0].Services, 2)
+
+	res = repo.List(RepoListOpts{ServiceType: "scraping", Country: "DE"})
+	require.Empty(t, res)
+
+	res = repo.List(RepoListOpts{Country: "FR"})
+	require.Len(t, res, 1)
+}
+
+func TestRepositoryStoreNativeProposal(t *testing.T) {
+	repo := NewRepository(100, 100)
+
+	var msg ProposalPingMessage
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"proposal": {
+			"provider_id": "0x1",
+			"location": {"country": "US", "ip_type": "residential"},
+			"services": [
+				{"service_type": "wireguard"},
+				{"service_type": "scraping", "location": {"country": "CA", "ip_type": "hosting"}}
+			]
+		}
+	}`), &msg))
+	require.False(t, msg.IsEmpty())
+	require.NoError(t, repo.Store(msg.Proposal))
+
+	res := repo.List(RepoListOpts{ServiceType: "wireguard", Country: "US"})
+	require.Len(t, res, 1)
+
+	res = repo.List(RepoListOpts{ServiceType: "scraping", Country: "CA", IpType: "hosting"})
+	require.Len(t, res, 1)
+
+	blob, err := json.Marshal(res[0].Services)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"service_type": "wireguard"},
+		{"service_type": "scraping", "location": {"country": "CA", "ip_type": "hosting"}}
+	]`, string(blob))
+}
+
+func TestProposalGetServiceDoesNotModifyStoredMeta(t *testing.T) {
+	providerLocation := &v3.Location{Country: "US"}
+	p := Proposal{
+		ProviderID: "0x1",
+		Meta:       Meta{Location: providerLocation},
+		Services: []ProviderService{
+			{ServiceType: "wireguard", Meta: &Meta{}},
+			{ServiceType: "scraping"},
+		},
+	}
+
+	require.Equal(t, providerLocation, p.getService("wireguard").Location)
+	require.Equal(t, providerLocation, p.getService("scraping").Location)
+	require.Nil(t, p.Services[0].Meta.Location)
+	require.Nil(t, p.Services[1].Meta)
+	require.Nil(t, p.getService("dvpn"))
+}
+
+func TestRepositoryRemoveKeepsOtherServices(t *testing.T) {
+	repo := NewRepository(100, 100)
+	require.NoError(t, repo.StoreV3(v3.Proposal{ProviderID: "0x1", ServiceType: "wireguard"}))
+	require.NoError(t, repo.StoreV3(v3.Proposal{ProviderID: "0x1", ServiceType: "scraping"}))
+
+	repo.Remove("0x1", "wireguard")
+
+	res := repo.List(RepoListOpts{})
+	require.Len(t, res, 1)
+	require.Len(t, res[0].Services, 1)
+	require.Equal(t, "scraping", res[0].Services[0].ServiceType)
+
+	repo.Remove("0x1", "scraping")
+	require.Empty(t, repo.List(RepoListOpts{}))
+}
+
+func TestRepositoryExpiresServicesIndependently(t *testing.T) {
+	repo := NewRepository(100, 100)
+	require.NoError(t, repo.StoreV3(v3.Proposal{ProviderID: "0x1", ServiceType: "wireguard"}))
+	require.NoError(t, repo.StoreV3(v3.Proposal{ProviderID: "0x1", ServiceType: "scraping"}))
+
+	p := repo.proposals["0x1"]
+	p.Services[0].expiresAt = time.Now().Add(-time.Second)
+
+	require.Equal(t, int64(1), repo.Expire())
+	res := repo.List(RepoListOpts{})
+	require.Len(t, res, 1)
+	require.Equal(t, "scraping", res[0].Services[0].ServiceType)
+
+	p = repo.proposals["0x1"]
+	p.Services[0].expiresAt = time.Now().Add(-time.Second)
+
+	require.Equal(t, int64(1), repo.Expire())
+	require.Empty(t, repo.List(RepoListOpts{}))
+}
@@ -7,11 +7,14 @@ package proposal
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	cache "github.com/chenyahui/gin-cache"
 	"github.com/chenyahui/gin-cache/persist"
 	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/discovery/proposal/aggregate"
 )
 
 type API struct {
@@ -19,6 +22,7 @@ type API struct {
 	proposalsCache           *persist.MemoryStore
 	countriesCache           *persist.MemoryStore
 	aggregatedProposalsCache *persist.MemoryStore
+	statsCache               *persist.MemoryStore
 	proposalsCacheTTL        time.Duration
 }
 
@@ -26,6 +30,7 @@ func NewAPI(service *Service,
 	proposalsCacheTTL time.Duration,
 	proposalsCacheLimit int,
 	countriesCacheLimit int,
+	statsCacheLimit int,
 ) *API {
 	a := &API{
 		service:           service,
@@ -38,6 +43,8 @@ func NewAPI(service *Service,
 		a.countriesCache.Cache.SetCacheSizeLimit(countriesCacheLimit)
 		a.aggregatedProposalsCache = persist.NewMemoryStore(proposalsCacheTTL)
 		a.aggregatedProposalsCache.Cache.SetCacheSizeLimit(proposalsCacheLimit)
+		a.statsCache = persist.NewMemoryStore(proposalsCacheTTL)
+		a.statsCache.Cache.SetCacheSizeLimit(statsCacheLimit)
 	}
 	return a
 }
@@ -55,6 +62,10 @@ func NewAPI(service *Service,
 // @Param compatibility_min query number false "Minimum compatibility. When empty, will not filter by it."
 // @Param compatibility_max query number false "Maximum compatibility. When empty, will not filter by it."
 // @Param quality_min query number false "Minimal quality threshold. When empty will be defaulted to 0. Quality ranges from [0.0; 3.0]"
+// @Param include_prices query bool false "Include the current and previous price of each proposal"
+// @Param price_max_per_gib query number false "Maximum current price per GiB in MYST"
+// @Param price_max_per_hour query number false "Maximum current price per hour in MYST"
+// @Param sort query string false "Sort by price: price_per_gib or price_per_hour"
 // @Accept json
 // @Product json
 // @Success 200 {array} v3.Proposal
@@ -79,6 +90,10 @@ func (a *API) Proposals(c *gin.Context) {
 // @Param compatibility_min query number false "Minimum compatibility. When empty, will not filter by it."
 // @Param compatibility_max query number false "Maximum compatibility. When empty, will not filter by it."
 // @Param quality_min query number false "Minimal quality threshold. When empty will be defaulted to 0. Quality ranges from [0.0; 3.0]"
+// @Param include_prices query bool false "Include the current and previous price of each proposal"
+// @Param price_max_per_gib query number false "Maximum current price per GiB in MYST"
+// @Param price_max_per_hour query number false "Maximum current price per hour in MYST"
+// @Param sort query string false "Sort by price: price_per_gib or price_per_hour"
 // @Accept json
 // @Product json
 // @Success 200 {array} v3.Proposal
@@ -102,11 +117,12 @@ func (a *API) AllProposals(c *gin.Context) {
 // @Param access_policy_source query string false "Access policy source"
 // @Param compatibility_min query number false "Minimum compatibility. When empty, will not filter by it."
 // @Param compatibility_max query number false "Maximum compatibility. When empty, will not filter by it."
-// @Param quality_min query number false "Minimal quality threshold. When empty will be defaulted to 0. Quality ranges from [0.0; 3.0]"
+// @Param quality_min query number false "Minimal quality threshold. When empty will be defaulted to 0. Quality ranges from [0.0; 3.0]. Applies to the service_type service when given, otherwise to the aggregated provider quality."
+// @Param bandwidth_min query number false "Minimal bandwidth threshold. Applies to the service_type service when given, otherwise to the aggregated provider quality."
 //
 // @Accept json
 // @Product json
-// @Success 200 {array} v3.Proposal
+// @Success 200 {array} aggregate.Proposal
 // @Router /proposals/aggregated [get]
 // @Tags proposals
 func (a *API) AggregatedProposals(c *gin.Context) {
@@ -138,6 +154,72 @@ func (a *API) CountriesNumbers(c *gin.Context) {
 	c.JSON(http.StatusOK, a.service.ListCountriesNumbers(opts, false))
 }
 
+// AggregatedCountriesNumbers list number of unique aggregated providers in each country.
+// @Summary List number of unique aggregated providers in each country
+// @Description List number of unique aggregated providers in each country with optional breakdowns
+// @Param provider_id query string false "Provider ID"
+// @Param service_type query string false "Service type. When given, only this service of the provider is counted and quality filters apply to it."
+// @Param location_country query string false "Provider country"
+// @Param ip_type query string false "IP type (residential, datacenter, etc.)"
+// @Param access_policy query string false "Access policy. When empty, returns only public proposals (default). Use 'all' to return all."
+// @Param access_policy_source query string false "Access policy source"
+// @Param compatibility_min query number false "Minimum compatibility. When empty, will not filter by it."
+// @Param compatibility_max query number false "Maximum compatibility. When empty, will not filter by it."
+// @Param quality_min query number false "Minimal quality threshold. When empty will be defaulted to 0. Quality ranges from [0.0; 3.0]"
+// @Param bandwidth_min query number false "Minimal bandwidth threshold"
+// @Param include_monitoring_failed query bool false "Include providers which failed monitoring"
+// @Param breakdown query string false "Comma separated breakdowns: ip_type, service_type, quality"
+// @Accept json
+// @Product json
+// @Success 200 {object} map[string]aggregate.CountryNumbers
+// @Router /countries/aggregated [get]
+// @Tags countries
+func (a *API) AggregatedCountriesNumbers(c *gin.Context) {
+	opts := a.proposalArgs(c)
+
+	var breakdown aggregate.CountryBreakdown
+	values, _ := c.GetQueryArray("breakdown")
+	for _, value := range values {
+		for _, b := range strings.Split(value, ",") {
+			switch strings.TrimSpace(b) {
+			case "ip_type":
+				breakdown.IPType = true
+			case "service_type":
+				breakdown.ServiceType = true
+			case "quality":
+				breakdown.Quality = true
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, a.service.ListAggregatedCountriesNumbers(opts, breakdown))
+}
+
+// Stats summarize the supply of the network.
+// @Summary Summarize the supply of the network
+// @Description Number of providers per country, service type, IP type, ASN and ISP, quality histograms, share of providers with failed monitoring and churn in the last hour
+// @Param from query string false "Consumer country"
+// @Param provider_id query string false "Provider ID"
+// @Param service_type query string false "Service type"
+// @Param location_country query string false "Provider country"
+// @Param ip_type query string false "IP type (residential, datacenter, etc.)"
+// @Param access_policy query string false "Access policy. When empty, returns only public proposals (default). Use 'all' to return all."
+// @Param access_policy_source query string false "Access policy source"
+// @Param compatibility_min query number false "Minimum compatibility. When empty, will not filter by it."
+// @Param compatibility_max query number false "Maximum compatibility. When empty, will not filter by it."
+// @Param quality_min query number false "Minimal quality threshold. When empty will be defaulted to 0. Quality ranges from [0.0; 3.0]"
+// @Param include_monitoring_failed query bool false "Include proposals which failed monitoring in the breakdowns and histograms"
+// @Accept json
+// @Product json
+// @Success 200 {object} Stats
+// @Router /stats [get]
+// @Tags stats
+func (a *API) Stats(c *gin.Context) {
+	opts := a.proposalArgs(c)
+
+	c.JSON(http.StatusOK, a.service.Stats(opts))
+}
+
 func (a *API) RegisterRoutes(r gin.IRoutes) {
 	cacheStrategy := a.newCacheStrategy()
 	if a.proposalsCacheTTL > 0 {
@@ -150,6 +232,15 @@ func (a *API) RegisterRoutes(r gin.IRoutes) {
 			),
 			a.CountriesNumbers,
 		)
+		r.GET(
+			"/countries/aggregated",
+			cache.Cache(
+				a.countriesCache,
+				a.proposalsCacheTTL,
+				cache.WithCacheStrategyByRequest(cacheStrategy),
+			),
+			a.AggregatedCountriesNumbers,
+		)
 		r.GET(
 			"/proposals",
 			cache.Cache(
@@ -159,9 +250,20 @@ func (a *API) RegisterRoutes(r gin.IRoutes) {
 			),
 			a.Proposals,
 		)
+		r.GET(
+			"/stats",
+			cache.Cache(
+				a.statsCache,
+				a.proposalsCacheTTL,
+				cache.WithCacheStrategyByRequest(cacheStrategy),
+			),
+			a.Stats,
+		)
 	} else {
 		r.GET("/countries", a.CountriesNumbers)
+		r.GET("/countries/aggregated", a.AggregatedCountriesNumbers)
 		r.GET("/proposals", a.Proposals)
+		r.GET("/stats", a.Stats)
 	}
 	r.GET("/proposals-metadata", a.ProposalsMetadata) // TODO move this into internal routes only once we migrate existing services to use it.
 }
@@ -241,6 +343,20 @@ func (a *API) proposalArgs(c *gin.Context) ListOpts {
 	presetID, _ := strconv.ParseInt(c.Query("preset_id"), 10, 16)
 	opts.presetID = int(presetID)
 
+	includePrices, _ := strconv.ParseBool(c.Query("include_prices"))
+	opts.priceFilters.includePrices = includePrices
+
+	pricePerGiBMax, _ := strconv.ParseFloat(c.Query("price_max_per_gib"), 64)
+	opts.priceFilters.pricePerGiBMax = pricePerGiBMax
+
+	pricePerHourMax, _ := strconv.ParseFloat(c.Query("price_max_per_hour"), 64)
+	opts.priceFilters.pricePerHourMax = pricePerHourMax
+
+	switch sortBy := c.Query("sort"); sortBy {
+	case sortByPricePerGiB, sortByPricePerHour:
+		opts.priceFilters.sortBy = sortBy
+	}
+
 	return opts
 }
 
@@ -29,6 +29,8 @@ type Repository struct {
 	proposalsHardLimitPerCountry int
 	proposalsSoftLimitPerCountry int
 	compatibilityMin             int
+	churnWindow                  time.Duration
+	churn                        []churnEvent
 }
 
 type repoListOpts struct {
@@ -46,6 +48,20 @@ type repoMetadataOpts struct {
 	providerID string
 }
 
+type churnKind int
+
+const (
+	churnAdded churnKind = iota
+	churnExpired
+	churnRemoved
+)
+
+type churnEvent struct {
+	kind     churnKind
+	at       time.Time
+	proposal v3.Proposal
+}
+
 type record struct {
 	proposal  v3.Proposal
 	expiresAt time.Time
@@ -59,6 +75,7 @@ func NewRepository(proposalsHardLimitPerCountry, proposalsSoftLimitPerCountry, c
 		proposalsHardLimitPerCountry: proposalsHardLimitPerCountry,
 		proposalsSoftLimitPerCountry: proposalsSoftLimitPerCountry,
 		compatibilityMin:             compatibilityMin,
+		churnWindow:                  time.Hour,
 	}
 }
 
@@ -163,6 +180,10 @@ func (r *Repository) Store(proposal v3.Proposal) error {
 
 	proposal = removeOldBrokerIPs(proposal)
 
+	if _, ok := r.proposals[proposal.Key()]; !ok {
+		r.recordChurn(churnAdded, proposal)
+	}
+
 	r.proposals[proposal.Key()] = record{
 		proposal:  proposal,
 		expiresAt: time.Now().Add(r.expirationDuration),
@@ -181,6 +202,7 @@ func (r *Repository) Expire() (count int64) {
 	for k, v := range r.proposals {
 		if time.Now().After(v.expiresAt) {
 			proposalExpired(r.proposals[k].proposal)
+			r.recordChurn(churnExpired, v.proposal)
 			delete(r.proposals, k)
 			count++
 		} else {
@@ -189,6 +211,7 @@ func (r *Repository) Expire() (count int64) {
 	}
 
 	proposalActive(activeProposals)
+	r.pruneChurn()
 
 	return count
 }
@@ -242,10 +265,66 @@ func (r *Repository) Remove(key string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	proposalRemoved(r.proposals[key].proposal)
+	p, ok := r.proposals[key]
+	if !ok {
+		return
+	}
+
+	proposalRemoved(p.proposal)
+	r.recordChurn(churnRemoved, p.proposal)
 	delete(r.proposals, key)
 }
 
+// Churn counts proposals matching the given options which were added, expired
+// or removed within the churn window.
+func (r *Repository) Churn(opts repoListOpts) Churn {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := Churn{Window: r.churnWindow.String()}
+	since := time.Now().Add(-r.churnWindow)
+
+	for _, e := range r.churn {
+		if e.at.Before(since) || !match(e.proposal, opts) {
+			continue
+		}
+
+		switch e.kind {
+		case churnAdded:
+			res.Added++
+		case churnExpired:
+			res.Expired++
+		case churnRemoved:
+			res.Removed++
+		}
+	}
+
+	return res
+}
+
+func (r *Repository) recordChurn(kind churnKind, proposal v3.Proposal) {
+	r.churn = append(r.churn, churnEvent{
+		kind:     kind,
+		at:       time.Now(),
+		proposal: proposal,
+	})
+}
+
+// pruneChurn drops churn events older than the churn window. Events are
+// recorded in order, so the stale ones are always at the beginning.
+func (r *Repository) pruneChurn() {
+	since := time.Now().Add(-r.churnWindow)
+
+	i := 0
+	for i < len(r.churn) && r.churn[i].at.Before(since) {
+		i++
+	}
+
+	if i > 0 {
+		r.churn = append([]churnEvent(nil), r.churn[i:]...)
+	}
+}
+
 func match(p v3.Proposal, opts repoListOpts) bool {
 	if len(opts.providerIDS) > 0 {
 		found := false
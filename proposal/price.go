@@ -0,0 +1,113 @@
+// Copyright (c) 2022 BlockDev AG
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package proposal
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
+	"github.com/mysteriumnetwork/payments/v3/units"
+)
+
+// Pricer provides the latest prices set by the pricer.
+type Pricer interface {
+	GetPrices() pricingbyservice.LatestPrices
+}
+
+const (
+	sortByPricePerGiB  = "price_per_gib"
+	sortByPricePerHour = "price_per_hour"
+)
+
+type priceFilters struct {
+	includePrices   bool
+	pricePerGiBMax  float64
+	pricePerHourMax float64
+	sortBy          string
+}
+
+func (f priceFilters) needsPrices() bool {
+	return f.includePrices || f.pricePerGiBMax > 0 || f.pricePerHourMax > 0 || f.sortBy != ""
+}
+
+// withPrices attaches the effective current and previous price to the proposals,
+// filters out the ones exceeding the maximum prices and sorts them by price.
+// Proposals without a known price are dropped by the price filters and put last
+// when sorting.
+func withPrices(proposals []v3.Proposal, lp pricingbyservice.LatestPrices, f priceFilters) []v3.Proposal {
+	res := make([]v3.Proposal, 0, len(proposals))
+	for _, p := range proposals {
+		p.Price = proposalPrice(lp, p)
+
+		if f.pricePerGiBMax > 0 && (p.Price == nil || units.BigIntWeiToFloatEth(p.Price.Current.PerGiB) > f.pricePerGiBMax) {
+			continue
+		}
+		if f.pricePerHourMax > 0 && (p.Price == nil || units.BigIntWeiToFloatEth(p.Price.Current.PerHour) > f.pricePerHourMax) {
+			continue
+		}
+
+		res = append(res, p)
+	}
+
+	switch f.sortBy {
+	case sortByPricePerGiB:
+		sortByPrice(res, func(p v3.Price) *big.Int { return p.PerGiB })
+	case sortByPricePerHour:
+		sortByPrice(res, func(p v3.Price) *big.Int { return p.PerHour })
+	}
+
+	if !f.includePrices {
+		for i := range res {
+			res[i].Price = nil
+		}
+	}
+
+	return res
+}
+
+func sortByPrice(proposals []v3.Proposal, price func(v3.Price) *big.Int) {
+	sort.SliceStable(proposals, func(i, j int) bool {
+		if proposals[i].Price == nil {
+			return false
+		}
+		if proposals[j].Price == nil {
+			return true
+		}
+		return price(proposals[i].Price.Current).Cmp(price(proposals[j].Price.Current)) < 0
+	})
+}
+
+func proposalPrice(lp pricingbyservice.LatestPrices, p v3.Proposal) *v3.ProposalPrice {
+	ph := lp.ForCountry(p.Location.Country)
+	if ph == nil {
+		return nil
+	}
+
+	residential := p.Location.IPType.IsResidential()
+	serviceType := pricingbyservice.ServiceType(p.ServiceType)
+
+	current, ok := ph.Current.ForNodeType(residential).ForServiceType(serviceType)
+	if !ok || current.PricePerGiB == nil || current.PricePerHour == nil {
+		return nil
+	}
+
+	previous, ok := ph.Previous.ForNodeType(residential).ForServiceType(serviceType)
+	if !ok || previous.PricePerGiB == nil || previous.PricePerHour == nil {
+		previous = current
+	}
+
+	return &v3.ProposalPrice{
+		Current: v3.Price{
+			PerHour: current.PricePerHour,
+			PerGiB:  current.PricePerGiB,
+		},
+		Previous: v3.Price{
+			PerHour: previous.PricePerHour,
+			PerGiB:  previous.PricePerGiB,
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package proposal
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
+)
+
+func testPrice(perHour, perGiB int64) pricingbyservice.Price {
+	return pricingbyservice.Price{
+		PricePerHour: big.NewInt(perHour * 1e15),
+		PricePerGiB:  big.NewInt(perGiB * 1e15),
+	}
+}
+
+func testPrices() pricingbyservice.LatestPrices {
+	defaults := &pricingbyservice.PriceByType{
+		Residential: pricingbyservice.PriceByServiceType{pricingbyservice.ServiceTypeWireguard: testPrice(10, 300)},
+		Other:       pricingbyservice.PriceByServiceType{pricingbyservice.ServiceTypeWireguard: testPrice(10, 100)},
+	}
+	us := &pricingbyservice.PriceByType{
+		Residential: pricingbyservice.PriceByServiceType{pricingbyservice.ServiceTypeWireguard: testPrice(20, 200)},
+		Other:       pricingbyservice.PriceByServiceType{pricingbyservice.ServiceTypeWireguard: testPrice(20, 150)},
+	}
+
+	return pricingbyservice.LatestPrices{
+		Defaults: &pricingbyservice.PriceHistory{Current: defaults, Previous: defaults},
+		PerCountry: map[string]*pricingbyservice.PriceHistory{
+			"US": {Current: us, Previous: defaults},
+		},
+	}
+}
+
+func TestWithPrices(t *testing.T) {
+	proposals := []v3.Proposal{
+		{ProviderID: "0x1", ServiceType: "wireguard", Location: v3.Location{Country: "US", IPType: "residential"}},
+		{ProviderID: "0x2", ServiceType: "wireguard", Location: v3.Location{Country: "DE", IPType: "hosting"}},
+		{ProviderID: "0x3", ServiceType: "unknown", Location: v3.Location{Country: "DE", IPType: "hosting"}},
+		{ProviderID: "0x4", ServiceType: "wireguard", Location: v3.Location{Country: "US", IPType: "hosting"}},
+	}
+
+	res := withPrices(proposals, testPrices(), priceFilters{includePrices: true, sortBy: sortByPricePerGiB})
+	require.Len(t, res, 4)
+	require.Equal(t, []string{"0x2", "0x4", "0x1", "0x3"}, providerIDs(res))
+	require.Equal(t, big.NewInt(200e15), res[2].Price.Current.PerGiB)
+	require.Equal(t, big.NewInt(300e15), res[2].Price.Previous.PerGiB)
+	require.Nil(t, res[3].Price)
+
+	res = withPrices(proposals, testPrices(), priceFilters{pricePerGiBMax: 0.15})
+	require.Equal(t, []string{"0x2", "0x4"}, providerIDs(res))
+	require.Nil(t, res[0].Price)
+
+	res = withPrices(proposals, testPrices(), priceFilters{pricePerHourMax: 0.01})
+	require.Equal(t, []string{"0x2"}, providerIDs(res))
+}
+
+func providerIDs(proposals []v3.Proposal) (res []string) {
+	for _, p := range proposals {
+		res = append(res, p.ProviderID)
+	}
+	return res
+}
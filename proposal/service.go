@@ -17,16 +17,22 @@ import (
 
 type Service struct {
 	*Repository
-	Aggregated     *aggregate.Repository
-	qualityService *quality.Service
-	shutdown       chan struct{}
+	Aggregated        *aggregate.Repository
+	qualityService    *quality.Service
+	qualityAggregator aggregate.QualityAggregator
+	pricer            Pricer
+	shutdown          chan struct{}
 }
 
-func NewService(repository *Repository, aggregated *aggregate.Repository, qualityService *quality.Service) *Service {
+// NewService creates a proposal service. The pricer is optional, without it
+// proposals are listed without prices and the price filters are ignored.
+func NewService(repository *Repository, aggregated *aggregate.Repository, qualityService *quality.Service, qualityAggregator aggregate.QualityAggregator, pricer Pricer) *Service {
 	return &Service{
-		Repository:     repository,
-		Aggregated:     aggregated,
-		qualityService: qualityService,
+		Repository:        repository,
+		Aggregated:        aggregated,
+		qualityService:    qualityService,
+		qualityAggregator: qualityAggregator,
+		pricer:            pricer,
 	}
 }
 
@@ -44,6 +50,7 @@ type ListOpts struct {
 	includeMonitoringFailed bool
 	natCompatibility        string
 	presetID                int
+	priceFilters            priceFilters
 }
 
 func (s *Service) List(opts ListOpts, limited bool) []v3.Proposal {
@@ -61,22 +68,31 @@ func (s *Service) List(opts ListOpts, limited bool) []v3.Proposal {
 	or := &metrics.OracleResponses{}
 	or.Load(s.qualityService)
 
-	return metrics.EnhanceWithMetrics(proposals, or.QualityResponse, metrics.Filters{
+	proposals = metrics.EnhanceWithMetrics(proposals, or.QualityResponse, metrics.Filters{
 		IncludeMonitoringFailed: opts.includeMonitoringFailed,
 		NATCompatibility:        opts.natCompatibility,
 		BandwidthMin:            opts.bandwidthMin,
 		QualityMin:              opts.qualityMin,
 		PresetID:                opts.presetID,
 	})
+
+	if s.pricer != nil && opts.priceFilters.needsPrices() {
+		proposals = withPrices(proposals, s.pricer.GetPrices(), opts.priceFilters)
+	}
+
+	return proposals
 }
 
 func (s *Service) ListAggregated(opts ListOpts) []aggregate.Proposal {
 	proposals := s.Aggregated.List(aggregate.RepoListOpts{
-		ProviderIDS:  opts.providerIDS,
-		ServiceType:  opts.serviceType,
-		Country:      opts.locationCountry,
-		IpType:       opts.ipType,
-		AccessPolicy: opts.accessPolicy,
+		ProviderIDS:        opts.providerIDS,
+		ServiceType:        opts.serviceType,
+		Country:            opts.locationCountry,
+		IpType:             opts.ipType,
+		AccessPolicy:       opts.accessPolicy,
+		AccessPolicySource: opts.accessPolicySource,
+		CompatibilityMin:   opts.compatibilityMin,
+		CompatibilityMax:   opts.compatibilityMax,
 	})
 
 	or := &metrics.OracleResponses{}
@@ -88,7 +104,48 @@ func (s *Service) ListAggregated(opts ListOpts) []aggregate.Proposal {
 		BandwidthMin:            opts.bandwidthMin,
 		QualityMin:              opts.qualityMin,
 		PresetID:                opts.presetID,
+		ServiceType:             opts.serviceType,
+	}, s.qualityAggregator)
+}
+
+// ListAggregatedCountriesNumbers counts unique aggregated providers per country
+// after applying all the filters of ListAggregated.
+func (s *Service) ListAggregatedCountriesNumbers(opts ListOpts, breakdown aggregate.CountryBreakdown) map[string]*aggregate.CountryNumbers {
+	// the repository matches the country of the provider only, while the providers
+	// are counted by the countries of their services, so it is filtered after counting
+	country := opts.locationCountry
+	opts.locationCountry = ""
+	res := aggregate.CountCountries(s.ListAggregated(opts), opts.serviceType, breakdown)
+	if country != "" {
+		for c := range res {
+			if c != country {
+				delete(res, c)
+			}
+		}
+	}
+
+	return res
+}
+
+// Stats summarizes the proposals matching all the filters of List. Churn only
+// takes the non quality filters into account.
+func (s *Service) Stats(opts ListOpts) Stats {
+	withFailed := opts
+	withFailed.includeMonitoringFailed = true
+
+	res := newStats(s.List(withFailed, false), opts.includeMonitoringFailed)
+	res.Churn = s.Repository.Churn(repoListOpts{
+		providerIDS:        opts.providerIDS,
+		serviceType:        opts.serviceType,
+		country:            opts.locationCountry,
+		ipType:             opts.ipType,
+		accessPolicy:       opts.accessPolicy,
+		accessPolicySource: opts.accessPolicySource,
+		compatibilityMin:   opts.compatibilityMin,
+		compatibilityMax:   opts.compatibilityMax,
 	})
+
+	return res
 }
 
 func (s *Service) Metadata(opts repoMetadataOpts) []v3.Metadata {
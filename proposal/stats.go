@@ -0,0 +1,142 @@
+// Copyright (c) 2022 BlockDev AG
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package proposal
+
+import (
+	"strconv"
+
+	v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
+)
+
+// Stats summarizes the supply of the network.
+type Stats struct {
+	Providers        int                   `json:"providers"`
+	Proposals        int                   `json:"proposals"`
+	Countries        map[string]int        `json:"countries"`
+	ServiceTypes     map[string]int        `json:"service_types"`
+	IPTypes          map[string]int        `json:"ip_types"`
+	ASNs             map[string]int        `json:"asns"`
+	ISPs             map[string]int        `json:"isps"`
+	Quality          []HistogramBucket     `json:"quality"`
+	Bandwidth        []HistogramBucket     `json:"bandwidth"`
+	Latency          []HistogramBucket     `json:"latency"`
+	MonitoringFailed MonitoringFailedStats `json:"monitoring_failed"`
+	Churn            Churn                 `json:"churn"`
+}
+
+// HistogramBucket holds the number of proposals with a value less or equal to LE
+// and greater than LE of the previous bucket.
+type HistogramBucket struct {
+	LE    string `json:"le"`
+	Count int    `json:"count"`
+}
+
+// MonitoringFailedStats holds the number of providers for which monitoring failed
+// on all of their services.
+type MonitoringFailedStats struct {
+	Providers int     `json:"providers"`
+	Share     float64 `json:"share"`
+}
+
+// Churn holds the number of proposals added, expired and removed within the window.
+type Churn struct {
+	Window  string `json:"window"`
+	Added   int    `json:"added"`
+	Expired int    `json:"expired"`
+	Removed int    `json:"removed"`
+}
+
+var (
+	qualityBuckets   = []float64{0.5, 1, 1.5, 2, 2.5, 3}
+	bandwidthBuckets = []float64{1, 5, 10, 25, 50, 100, 250}
+	latencyBuckets   = []float64{50, 100, 200, 500, 1000, 2000}
+)
+
+// newStats computes the stats of the given proposals. The proposals are expected
+// to include the ones which failed monitoring, so that their share can be computed;
+// they are counted in the rest of the stats only when includeMonitoringFailed is set.
+func newStats(proposals []v3.Proposal, includeMonitoringFailed bool) Stats {
+	res := Stats{
+		Countries:    make(map[string]int),
+		ServiceTypes: make(map[string]int),
+		IPTypes:      make(map[string]int),
+		ASNs:         make(map[string]int),
+		ISPs:         make(map[string]int),
+		Quality:      newHistogram(qualityBuckets),
+		Bandwidth:    newHistogram(bandwidthBuckets),
+		Latency:      newHistogram(latencyBuckets),
+	}
+
+	failed := make(map[string]bool)
+	for _, p := range proposals {
+		providerFailed, ok := failed[p.ProviderID]
+		failed[p.ProviderID] = p.Quality.MonitoringFailed && (providerFailed || !ok)
+	}
+	for _, providerFailed := range failed {
+		if providerFailed {
+			res.MonitoringFailed.Providers++
+		}
+	}
+	if len(failed) > 0 {
+		res.MonitoringFailed.Share = float64(res.MonitoringFailed.Providers) / float64(len(failed))
+	}
+
+	seen := make(map[string]struct{})
+	countOnce := func(counts map[string]int, prefix, key, providerID string) {
+		if key == "" {
+			return
+		}
+		if _, ok := seen[prefix+key+"|"+providerID]; ok {
+			return
+		}
+		seen[prefix+key+"|"+providerID] = struct{}{}
+		counts[key]++
+	}
+
+	providers := make(map[string]struct{})
+	for _, p := range proposals {
+		if !includeMonitoringFailed && p.Quality.MonitoringFailed {
+			continue
+		}
+
+		res.Proposals++
+		providers[p.ProviderID] = struct{}{}
+
+		countOnce(res.Countries, "country:", p.Location.Country, p.ProviderID)
+		countOnce(res.ServiceTypes, "service_type:", p.ServiceType, p.ProviderID)
+		countOnce(res.IPTypes, "ip_type:", string(p.Location.IPType), p.ProviderID)
+		if p.Location.ASN != 0 {
+			countOnce(res.ASNs, "asn:", strconv.Itoa(p.Location.ASN), p.ProviderID)
+		}
+		countOnce(res.ISPs, "isp:", p.Location.ISP, p.ProviderID)
+
+		observe(res.Quality, qualityBuckets, p.Quality.Quality)
+		observe(res.Bandwidth, bandwidthBuckets, p.Quality.Bandwidth)
+		observe(res.Latency, latencyBuckets, p.Quality.Latency)
+	}
+	res.Providers = len(providers)
+
+	return res
+}
+
+func newHistogram(bounds []float64) []HistogramBucket {
+	res := make([]HistogramBucket, 0, len(bounds)+1)
+	for _, b := range bounds {
+		res = append(res, HistogramBucket{LE: strconv.FormatFloat(b, 'f', -1, 64)})
+	}
+
+	return append(res, HistogramBucket{LE: "+Inf"})
+}
+
+func observe(histogram []HistogramBucket, bounds []float64, value float64) {
+	for i, b := range bounds {
+		if value <= b {
+			histogram[i].Count++
+			return
+		}
+	}
+
+	histogram[len(bounds)].Count++
+}
@@ -0,0 +1,66 @@
+package proposal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	v3 "github.com/mysteriumnetwork/discovery/proposal/v3"
+)
+
+func TestNewStats(t *testing.T) {
+	proposals := []v3.Proposal{
+		{
+			ProviderID:  "0x1",
+			ServiceType: "wireguard",
+			Location:    v3.Location{Country: "US", IPType: "residential", ASN: 7922, ISP: "Comcast"},
+			Quality:     v3.Quality{Quality: 2.2, Bandwidth: 30, Latency: 80},
+		},
+		{
+			ProviderID:  "0x1",
+			ServiceType: "scraping",
+			Location:    v3.Location{Country: "US", IPType: "residential", ASN: 7922, ISP: "Comcast"},
+			Quality:     v3.Quality{Quality: 1, Bandwidth: 3, Latency: 300, MonitoringFailed: true},
+		},
+		{
+			ProviderID:  "0x2",
+			ServiceType: "wireguard",
+			Location:    v3.Location{Country: "DE", IPType: "hosting", ASN: 24940, ISP: "Hetzner"},
+			Quality:     v3.Quality{MonitoringFailed: true},
+		},
+	}
+
+	res := newStats(proposals, false)
+
+	require.Equal(t, 1, res.Providers)
+	require.Equal(t, 1, res.Proposals)
+	require.Equal(t, map[string]int{"US": 1}, res.Countries)
+	require.Equal(t, map[string]int{"wireguard": 1}, res.ServiceTypes)
+	require.Equal(t, map[string]int{"7922": 1}, res.ASNs)
+	require.Equal(t, MonitoringFailedStats{Providers: 1, Share: 0.5}, res.MonitoringFailed)
+	require.Equal(t, HistogramBucket{LE: "2.5", Count: 1}, res.Quality[4])
+	require.Equal(t, HistogramBucket{LE: "50", Count: 1}, res.Bandwidth[4])
+
+	res = newStats(proposals, true)
+
+	require.Equal(t, 2, res.Providers)
+	require.Equal(t, 3, res.Proposals)
+	require.Equal(t, map[string]int{"US": 1, "DE": 1}, res.Countries)
+	require.Equal(t, map[string]int{"wireguard": 2, "scraping": 1}, res.ServiceTypes)
+	require.Equal(t, map[string]int{"residential": 1, "hosting": 1}, res.IPTypes)
+	require.Equal(t, map[string]int{"Comcast": 1, "Hetzner": 1}, res.ISPs)
+	require.Equal(t, HistogramBucket{LE: "+Inf", Count: 0}, res.Latency[len(res.Latency)-1])
+}
+
+func TestRepositoryChurn(t *testing.T) {
+	repo := NewRepository(100, 100, 0)
+
+	require.NoError(t, repo.Store(v3.Proposal{ProviderID: "0x1", ServiceType: "wireguard", Location: v3.Location{Country: "US"}}))
+	require.NoError(t, repo.Store(v3.Proposal{ProviderID: "0x1", ServiceType: "wireguard", Location: v3.Location{Country: "US"}}))
+	require.NoError(t, repo.Store(v3.Proposal{ProviderID: "0x2", ServiceType: "wireguard", Location: v3.Location{Country: "DE"}}))
+	repo.Remove("0x2.wireguard")
+	repo.Remove("0x3.wireguard")
+
+	require.Equal(t, Churn{Window: "1h0m0s", Added: 2, Removed: 1}, repo.Churn(repoListOpts{}))
+	require.Equal(t, Churn{Window: "1h0m0s", Added: 1}, repo.Churn(repoListOpts{country: "US"}))
+}
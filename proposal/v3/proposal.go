@@ -42,6 +42,7 @@ type Proposal struct {
 	Contacts       []Contact      `json:"contacts"`
 	AccessPolicies []AccessPolicy `json:"access_policies,omitempty"`
 	Quality        Quality        `json:"quality"`
+	Price          *ProposalPrice `json:"price,omitempty"`
 }
 
 func NewProposal(providerID, serviceType string) *Proposal {
@@ -91,6 +92,12 @@ type Price struct {
 	PerGiB  *big.Int `json:"per_gib" swaggertype:"integer"`
 }
 
+// ProposalPrice holds the current and the previous effective price of the proposal.
+type ProposalPrice struct {
+	Current  Price `json:"current"`
+	Previous Price `json:"previous"`
+}
+
 type Contact struct {
 	Type       string           `json:"type"`
 	Definition *json.RawMessage `json:"definition" swaggertype:"object"`
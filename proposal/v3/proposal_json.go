@@ -185,7 +185,80 @@ func (v *ProposalUnregisterMessage) UnmarshalJSON(data []byte) error {
 func (v *ProposalUnregisterMessage) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV31(l, v)
 }
-func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV32(in *jlexer.Lexer, out *ProposalPingMessage) {
+func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV32(in *jlexer.Lexer, out *ProposalPrice) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "current":
+			(out.Current).UnmarshalEasyJSON(in)
+		case "previous":
+			(out.Previous).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV32(out *jwriter.Writer, in ProposalPrice) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"current\":"
+		out.RawString(prefix[1:])
+		(in.Current).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"previous\":"
+		out.RawString(prefix)
+		(in.Previous).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ProposalPrice) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV32(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ProposalPrice) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV32(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ProposalPrice) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV32(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ProposalPrice) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV32(l, v)
+}
+func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV33(in *jlexer.Lexer, out *ProposalPingMessage) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -216,7 +289,7 @@ func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV32(in *jle
 		in.Consumed()
 	}
 }
-func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV32(out *jwriter.Writer, in ProposalPingMessage) {
+func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV33(out *jwriter.Writer, in ProposalPingMessage) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -231,27 +304,27 @@ func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV32(out *jw
 // MarshalJSON supports json.Marshaler interface
 func (v ProposalPingMessage) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV32(&w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV33(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v ProposalPingMessage) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV32(w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV33(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *ProposalPingMessage) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV32(&r, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV33(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *ProposalPingMessage) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV32(l, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV33(l, v)
 }
-func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV33(in *jlexer.Lexer, out *Proposal) {
+func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV34(in *jlexer.Lexer, out *Proposal) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -330,6 +403,16 @@ func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV33(in *jle
 			}
 		case "quality":
 			(out.Quality).UnmarshalEasyJSON(in)
+		case "price":
+			if in.IsNull() {
+				in.Skip()
+				out.Price = nil
+			} else {
+				if out.Price == nil {
+					out.Price = new(ProposalPrice)
+				}
+				(*out.Price).UnmarshalEasyJSON(in)
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -340,7 +423,7 @@ func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV33(in *jle
 		in.Consumed()
 	}
 }
-func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV33(out *jwriter.Writer, in Proposal) {
+func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV34(out *jwriter.Writer, in Proposal) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -409,33 +492,38 @@ func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV33(out *jw
 		out.RawString(prefix)
 		(in.Quality).MarshalEasyJSON(out)
 	}
+	if in.Price != nil {
+		const prefix string = ",\"price\":"
+		out.RawString(prefix)
+		(*in.Price).MarshalEasyJSON(out)
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Proposal) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV33(&w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV34(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Proposal) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV33(w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV34(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Proposal) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV33(&r, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV34(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Proposal) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV33(l, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV34(l, v)
 }
-func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV34(in *jlexer.Lexer, out *Price) {
+func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV35(in *jlexer.Lexer, out *Price) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -488,7 +576,7 @@ func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV34(in *jle
 		in.Consumed()
 	}
 }
-func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV34(out *jwriter.Writer, in Price) {
+func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV35(out *jwriter.Writer, in Price) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -516,27 +604,27 @@ func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV34(out *jw
 // MarshalJSON supports json.Marshaler interface
 func (v Price) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV34(&w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV35(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Price) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV34(w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV35(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Price) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV34(&r, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV35(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Price) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV34(l, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV35(l, v)
 }
-func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV35(in *jlexer.Lexer, out *Location) {
+func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV36(in *jlexer.Lexer, out *Location) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -579,7 +667,7 @@ func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV35(in *jle
 		in.Consumed()
 	}
 }
-func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV35(out *jwriter.Writer, in Location) {
+func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV36(out *jwriter.Writer, in Location) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -655,27 +743,27 @@ func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV35(out *jw
 // MarshalJSON supports json.Marshaler interface
 func (v Location) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV35(&w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV36(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Location) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV35(w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV36(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Location) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV35(&r, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV36(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Location) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV35(l, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV36(l, v)
 }
-func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV36(in *jlexer.Lexer, out *Contact) {
+func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV37(in *jlexer.Lexer, out *Contact) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -718,7 +806,7 @@ func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV36(in *jle
 		in.Consumed()
 	}
 }
-func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV36(out *jwriter.Writer, in Contact) {
+func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV37(out *jwriter.Writer, in Contact) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -742,27 +830,27 @@ func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV36(out *jw
 // MarshalJSON supports json.Marshaler interface
 func (v Contact) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV36(&w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV37(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Contact) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV36(w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV37(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Contact) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV36(&r, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV37(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Contact) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV36(l, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV37(l, v)
 }
-func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV37(in *jlexer.Lexer, out *AccessPolicy) {
+func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV38(in *jlexer.Lexer, out *AccessPolicy) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -795,7 +883,7 @@ func easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV37(in *jle
 		in.Consumed()
 	}
 }
-func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV37(out *jwriter.Writer, in AccessPolicy) {
+func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV38(out *jwriter.Writer, in AccessPolicy) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -815,23 +903,23 @@ func easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV37(out *jw
 // MarshalJSON supports json.Marshaler interface
 func (v AccessPolicy) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV37(&w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV38(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v AccessPolicy) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV37(w, v)
+	easyjsonAd058f64EncodeGithubComMysteriumnetworkDiscoveryProposalV38(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *AccessPolicy) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV37(&r, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV38(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *AccessPolicy) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV37(l, v)
+	easyjsonAd058f64DecodeGithubComMysteriumnetworkDiscoveryProposalV38(l, v)
 }
@@ -5,87 +5,48 @@ import (
 	"fmt"
 	stdlog "log"
 	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/mysteriumnetwork/discovery/config"
 	"github.com/mysteriumnetwork/discovery/metrics"
 	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	"github.com/mysteriumnetwork/discovery/price/store"
+	"github.com/mysteriumnetwork/discovery/sidecar"
 	mlog "github.com/mysteriumnetwork/logger"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 
 	// unconfuse the number of cores go can use in k8s
-	"github.com/mysteriumnetwork/payments/v3/exchange/coingecko"
-	"github.com/mysteriumnetwork/payments/v3/exchange/coinranking"
 	_ "go.uber.org/automaxprocs"
 )
 
 func main() {
 	configureLogger()
-	cfg, err := ReadConfig()
+	cfg, err := sidecar.ReadConfig()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to read config")
 	}
 
-	prometheusDemandIndexes := pricingbyservice.NewPrometheusDemandIndexProvider(
-		&cfg.PrometheusURL,
-		cfg.PrometheusUsername,
-		cfg.PrometheusPassword,
-		pricingbyservice.CountryDemandIndexQuery,
-	)
-	countryDemandIndexes := pricingbyservice.NewDailyCountryDemandIndexProvider(prometheusDemandIndexes)
-
-	rdb := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs:    cfg.RedisAddress,
-		Password: cfg.RedisPass,
-		DB:       cfg.RedisDB,
-	})
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	st := rdb.Ping(ctx)
-	err = st.Err()
-	if err != nil {
-		log.Fatal().Err(err).Msg("could not reach redis")
-	}
-	cancel()
-
-	mrkt := buildMarket(cfg)
-	err = mrkt.Start()
+	st, err := store.Open(cfg.StoreOptions())
 	if err != nil {
-		log.Fatal().Err(err).Msg("could not build market")
+		log.Fatal().Err(err).Msg("could not open store")
 	}
-	defer mrkt.Stop()
-	log.Info().Msg("market started")
-
-	cfger := pricingbyservice.NewConfigProviderDB(rdb)
-	_, err = cfger.Get()
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to load cfg")
-	}
-	log.Info().Msg("cfger started")
+	defer func() {
+		if err := st.Close(); err != nil {
+			log.Err(err).Msg("could not close store")
+		}
+	}()
 
 	metrics.InitialiseMonitoring()
-	pricer, err := pricingbyservice.NewPricer(
-		cfger,
-		mrkt,
-		countryDemandIndexes,
-		time.Minute*5,
-		pricingbyservice.Bound{Min: 0.01, Max: 3.0},
-		rdb,
-	)
+	updater, err := sidecar.StartUpdater(cfg, st)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to initialize Pricer")
-		return
+		log.Fatal().Err(err).Msg("Failed to start price updater")
 	}
-	log.Info().Msg("pricer started")
-	defer pricer.Stop()
+	defer updater.Stop()
 
 	router := gin.New()
 	router.Use(gin.Recovery())
@@ -93,12 +54,16 @@ func main() {
 	router.GET("/status", func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		err := rdb.Ping(ctx).Err()
+		if err := st.Ping(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, statusResponse{Store: err.Error()})
+			return
+		}
+		leader, err := updater.LeaderStatus(ctx)
 		if err != nil {
-			c.String(http.StatusInternalServerError, err.Error())
+			c.JSON(http.StatusInternalServerError, statusResponse{Store: err.Error(), Leader: leader})
 			return
 		}
-		c.String(http.StatusOK, "OK")
+		c.JSON(http.StatusOK, statusResponse{Store: "OK", Leader: leader})
 	})
 
 	srv := &http.Server{
@@ -123,6 +88,11 @@ func main() {
 	}
 }
 
+type statusResponse struct {
+	Store  string                        `json:"store"`
+	Leader pricingbyservice.LeaderStatus `json:"leader"`
+}
+
 func getPort() int {
 	p := os.Getenv("PORT")
 	if p == "" {
@@ -133,91 +103,8 @@ func getPort() int {
 	return port
 }
 
-func buildMarket(cfg *Options) *pricingbyservice.Market {
-	apis := []pricingbyservice.ExternalPriceAPI{
-		coingecko.NewAPI(cfg.GeckoURL.String(), cfg.TokenRateCacheTTL),
-		coinranking.NewAPI(cfg.CoinRankingURL.String(), cfg.CoinRankingToken, cfg.TokenRateCacheTTL),
-	}
-	mrkt := pricingbyservice.NewMarket(apis, time.Minute*15)
-	return mrkt
-}
-
 func configureLogger() {
 	mlog.BootstrapDefaultLogger()
 	stdlog.SetFlags(0)
 	stdlog.SetOutput(log.Logger)
 }
-
-type Options struct {
-	RedisAddress       []string
-	RedisPass          string
-	RedisDB            int
-	QualityOracleURL   url.URL
-	GeckoURL           url.URL
-	CoinRankingURL     url.URL
-	TokenRateCacheTTL  time.Duration
-	CoinRankingToken   string
-	PrometheusURL      url.URL
-	PrometheusUsername string
-	PrometheusPassword string
-}
-
-func ReadConfig() (*Options, error) {
-	prometheusURL, err := config.RequiredEnvURL("PROMETHEUS_URL")
-	if err != nil {
-		return nil, err
-	}
-	prometheusUsername := config.OptionalEnv("PROMETHEUS_USERNAME", "")
-	prometheusPassword := config.OptionalEnv("PROMETHEUS_PASSWORD", "")
-
-	redisAddress, err := config.RequiredEnv("REDIS_ADDRESS")
-	if err != nil {
-		return nil, err
-	}
-
-	redisPass := config.OptionalEnv("REDIS_PASS", "")
-
-	redisDBint := 0
-	redisDB := config.OptionalEnv("REDIS_DB", "0")
-	if redisDB != "" {
-		res, err := strconv.Atoi(redisDB)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse redis db from %q: %w", redisDB, err)
-		}
-		redisDBint = res
-	}
-
-	qualityOracleURL, err := config.RequiredEnvURL("QUALITY_ORACLE_URL")
-	if err != nil {
-		return nil, err
-	}
-	geckoURL, err := config.OptionalEnvURL("GECKO_URL", coingecko.DefaultGeckoURI)
-	if err != nil {
-		return nil, err
-	}
-	coinRankingURL, err := config.OptionalEnvURL("COINRANKING_URL", coinranking.DefaultCoinRankingURI)
-	if err != nil {
-		return nil, err
-	}
-	tokenRateCacheTTL, err := config.OptionalEnvDuration("TOKEN_RATE_CACHE_TTL", "1m")
-	if err != nil {
-		return nil, err
-	}
-	coinRankingToken, err := config.RequiredEnv("COINRANKING_TOKEN")
-	if err != nil {
-		return nil, err
-	}
-	return &Options{
-		RedisAddress:       strings.Split(redisAddress, ";"),
-		RedisPass:          redisPass,
-		RedisDB:            redisDBint,
-		QualityOracleURL:   *qualityOracleURL,
-		GeckoURL:           *geckoURL,
-		CoinRankingURL:     *coinRankingURL,
-		TokenRateCacheTTL:  *tokenRateCacheTTL,
-		CoinRankingToken:   coinRankingToken,
-		PrometheusURL:      *prometheusURL,
-		PrometheusUsername: prometheusUsername,
-		PrometheusPassword: prometheusPassword,
-	}, nil
-}
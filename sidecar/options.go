@@ -0,0 +1,243 @@
+package sidecar
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/v3/exchange"
+	"github.com/mysteriumnetwork/payments/v3/exchange/coingecko"
+	"github.com/mysteriumnetwork/payments/v3/exchange/coinranking"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/mysteriumnetwork/discovery/config"
+	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+type Options struct {
+	StoreType          string
+	StoreFile          string
+	RedisAddress       []string
+	RedisPass          string
+	RedisDB            int
+	QualityOracleURL   url.URL
+	GeckoURL           url.URL
+	CoinRankingURL     url.URL
+	TokenRateCacheTTL  time.Duration
+	CoinRankingToken   string
+	PrometheusURL      url.URL
+	PrometheusUsername string
+	PrometheusPassword string
+	// PrometheusDemandIndexQuery is the PromQL query of the Prometheus demand index provider.
+	PrometheusDemandIndexQuery string
+	DemandIndexProvider        string
+	DemandIndexFile            string
+	DemandIndexRedisKey        string
+	DemandIndexWeights         map[string]float64
+	DemandIndexSchedule        pricingbyservice.RefreshSchedule
+	DemandIndexRetention       time.Duration
+	SupplyProvider             string
+	DiscoveryAPIURL            url.URL
+	PrometheusSupplyQuery      string
+	PriceHistoryRetention      time.Duration
+	MarketAggregation          string
+	MarketMaxDeviation         float64
+	MarketMaxStaleness         time.Duration
+	MarketSourceWeights        map[string]float64
+	MarketCurrencies           []exchange.Currency
+	LeaderID                   string
+	LeaderLease                time.Duration
+}
+
+// ReadConfig reads the config of the sidecar service.
+func ReadConfig() (*Options, error) {
+	return readConfig(false)
+}
+
+// ReadEmbeddedConfig reads the config of the price updater embedded into the pricer.
+// The quality oracle and the coinranking token are optional, coinranking is not
+// queried without the token.
+func ReadEmbeddedConfig() (*Options, error) {
+	return readConfig(true)
+}
+
+func readConfig(embedded bool) (*Options, error) {
+	prometheusURL, err := config.OptionalEnvURL("PROMETHEUS_URL", "")
+	if err != nil {
+		return nil, err
+	}
+	prometheusUsername := config.OptionalEnv("PROMETHEUS_USERNAME", "")
+	prometheusPassword := config.OptionalEnv("PROMETHEUS_PASSWORD", "")
+	prometheusDemandIndexQuery := config.OptionalEnv("PROMETHEUS_DEMAND_INDEX_QUERY", pricingbyservice.CountryDemandIndexQuery)
+	if path := config.OptionalEnv("PROMETHEUS_DEMAND_INDEX_QUERY_FILE", ""); path != "" {
+		query, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read PROMETHEUS_DEMAND_INDEX_QUERY_FILE: %w", err)
+		}
+		prometheusDemandIndexQuery = string(query)
+	}
+	demandIndexProvider := config.OptionalEnv("DEMAND_INDEX_PROVIDER", demandIndexProviderPrometheus)
+	demandIndexFile := config.OptionalEnv("DEMAND_INDEX_FILE", "")
+	demandIndexRedisKey := config.OptionalEnv("DEMAND_INDEX_REDIS_KEY", pricingbyservice.DemandIndexRedisKey)
+	demandIndexWeights, err := config.OptionalEnvFloatMap("DEMAND_INDEX_WEIGHTS", "")
+	if err != nil {
+		return nil, err
+	}
+	demandIndexSchedule, err := pricingbyservice.ParseRefreshSchedule(config.OptionalEnv("DEMAND_INDEX_SCHEDULE", "@daily"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse DEMAND_INDEX_SCHEDULE: %w", err)
+	}
+	demandIndexRetention, err := config.OptionalEnvDuration("DEMAND_INDEX_RETENTION", "2160h")
+	if err != nil {
+		return nil, err
+	}
+	supplyProvider := config.OptionalEnv("SUPPLY_PROVIDER", "")
+	discoveryAPIURL, err := config.OptionalEnvURL("DISCOVERY_API_URL", "")
+	if err != nil {
+		return nil, err
+	}
+	prometheusSupplyQuery := config.OptionalEnv("PROMETHEUS_SUPPLY_QUERY", "")
+
+	storeType := config.OptionalEnv("STORE", store.TypeRedis)
+	storeFile := config.OptionalEnv("STORE_FILE", "")
+	var redisAddress []string
+	if addr := config.OptionalEnv("REDIS_ADDRESS", ""); addr != "" {
+		redisAddress = strings.Split(addr, ";")
+	}
+
+	redisPass := config.OptionalEnv("REDIS_PASS", "")
+
+	redisDBint := 0
+	redisDB := config.OptionalEnv("REDIS_DB", "0")
+	if redisDB != "" {
+		res, err := strconv.Atoi(redisDB)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse redis db from %q: %w", redisDB, err)
+		}
+		redisDBint = res
+	}
+
+	var qualityOracleURL *url.URL
+	if embedded {
+		qualityOracleURL, err = config.OptionalEnvURL("QUALITY_ORACLE_URL", "")
+	} else {
+		qualityOracleURL, err = config.RequiredEnvURL("QUALITY_ORACLE_URL")
+	}
+	if err != nil {
+		return nil, err
+	}
+	geckoURL, err := config.OptionalEnvURL("GECKO_URL", coingecko.DefaultGeckoURI)
+	if err != nil {
+		return nil, err
+	}
+	coinRankingURL, err := config.OptionalEnvURL("COINRANKING_URL", coinranking.DefaultCoinRankingURI)
+	if err != nil {
+		return nil, err
+	}
+	tokenRateCacheTTL, err := config.OptionalEnvDuration("TOKEN_RATE_CACHE_TTL", "1m")
+	if err != nil {
+		return nil, err
+	}
+	coinRankingToken := config.OptionalEnv("COINRANKING_TOKEN", "")
+	if !embedded && coinRankingToken == "" {
+		return nil, errors.New("COINRANKING_TOKEN is required")
+	}
+	priceHistoryRetention, err := config.OptionalEnvDuration("PRICE_HISTORY_RETENTION", "720h")
+	if err != nil {
+		return nil, err
+	}
+	marketAggregation := config.OptionalEnv("MARKET_AGGREGATION", string(pricingbyservice.MarketAggregationMedian))
+	switch pricingbyservice.MarketAggregation(marketAggregation) {
+	case pricingbyservice.MarketAggregationMedian, pricingbyservice.MarketAggregationWeightedAverage:
+	default:
+		return nil, fmt.Errorf("unknown market aggregation %q", marketAggregation)
+	}
+	marketMaxDeviation, err := strconv.ParseFloat(config.OptionalEnv("MARKET_MAX_DEVIATION", "0.1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse MARKET_MAX_DEVIATION: %w", err)
+	}
+	marketMaxStaleness, err := config.OptionalEnvDuration("MARKET_MAX_STALENESS", "1h")
+	if err != nil {
+		return nil, err
+	}
+	marketSourceWeights, err := config.OptionalEnvFloatMap("MARKET_SOURCE_WEIGHTS", "")
+	if err != nil {
+		return nil, err
+	}
+	var marketCurrencies []exchange.Currency
+	for _, currency := range strings.Split(config.OptionalEnv("MARKET_CURRENCIES", ""), ",") {
+		if currency == "" {
+			continue
+		}
+		if !exchange.Currency(currency).IsFiat() {
+			return nil, fmt.Errorf("unsupported market currency %q", currency)
+		}
+		marketCurrencies = append(marketCurrencies, exchange.Currency(currency))
+	}
+	leaderID := config.OptionalEnv("LEADER_ID", "")
+	if leaderID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("could not get hostname for LEADER_ID: %w", err)
+		}
+		leaderID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	leaderLease, err := config.OptionalEnvDuration("LEADER_LEASE", pricingbyservice.DefaultLeaderLease.String())
+	if err != nil {
+		return nil, err
+	}
+	if *leaderLease < 3*time.Second {
+		return nil, errors.New("LEADER_LEASE should be at least 3s")
+	}
+	return &Options{
+		StoreType:                  storeType,
+		StoreFile:                  storeFile,
+		RedisAddress:               redisAddress,
+		RedisPass:                  redisPass,
+		RedisDB:                    redisDBint,
+		QualityOracleURL:           *qualityOracleURL,
+		GeckoURL:                   *geckoURL,
+		CoinRankingURL:             *coinRankingURL,
+		TokenRateCacheTTL:          *tokenRateCacheTTL,
+		CoinRankingToken:           coinRankingToken,
+		PrometheusURL:              *prometheusURL,
+		PrometheusUsername:         prometheusUsername,
+		PrometheusPassword:         prometheusPassword,
+		PrometheusDemandIndexQuery: prometheusDemandIndexQuery,
+		DemandIndexProvider:        demandIndexProvider,
+		DemandIndexFile:            demandIndexFile,
+		DemandIndexRedisKey:        demandIndexRedisKey,
+		DemandIndexWeights:         demandIndexWeights,
+		DemandIndexSchedule:        demandIndexSchedule,
+		DemandIndexRetention:       *demandIndexRetention,
+		SupplyProvider:             supplyProvider,
+		DiscoveryAPIURL:            *discoveryAPIURL,
+		PrometheusSupplyQuery:      prometheusSupplyQuery,
+		PriceHistoryRetention:      *priceHistoryRetention,
+		MarketAggregation:          marketAggregation,
+		MarketMaxDeviation:         marketMaxDeviation,
+		MarketMaxStaleness:         *marketMaxStaleness,
+		MarketSourceWeights:        marketSourceWeights,
+		MarketCurrencies:           marketCurrencies,
+		LeaderID:                   leaderID,
+		LeaderLease:                *leaderLease,
+	}, nil
+}
+
+// StoreOptions selects the store the prices are kept in.
+func (o *Options) StoreOptions() store.Options {
+	return store.Options{
+		Type: o.StoreType,
+		File: o.StoreFile,
+		Redis: &redis.UniversalOptions{
+			Addrs:    o.RedisAddress,
+			Password: o.RedisPass,
+			DB:       o.RedisDB,
+		},
+	}
+}
@@ -0,0 +1,201 @@
+// Package sidecar runs the price updater of the pricer, either as the sidecar
+// service or embedded into the pricer API.
+package sidecar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/v3/exchange/coingecko"
+	"github.com/mysteriumnetwork/payments/v3/exchange/coinranking"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/discovery/price/pricingbyservice"
+	"github.com/mysteriumnetwork/discovery/price/store"
+)
+
+// Updater updates the prices while its replica leads the election.
+type Updater struct {
+	market   *pricingbyservice.Market
+	election *pricingbyservice.LeaderElection
+	pricer   *pricingbyservice.PriceUpdater
+}
+
+// StartUpdater starts the market, the leader election and the price updater on the store.
+func StartUpdater(cfg *Options, db store.Store) (*Updater, error) {
+	demandIndexes, err := buildDemandIndexProvider(cfg, db)
+	if err != nil {
+		return nil, fmt.Errorf("could not build demand index provider: %w", err)
+	}
+	demandIndexHistory := pricingbyservice.NewDemandIndexStorage(db, cfg.DemandIndexRetention)
+	countryDemandIndexes := pricingbyservice.NewScheduledCountryDemandIndexProvider(demandIndexes, cfg.DemandIndexSchedule, demandIndexHistory)
+
+	supply, err := buildSupplyProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not build supply provider: %w", err)
+	}
+
+	cfger := pricingbyservice.NewConfigProviderDB(db)
+	if _, err := cfger.Get(); err != nil {
+		return nil, fmt.Errorf("could not load cfg: %w", err)
+	}
+	log.Info().Msg("cfger started")
+
+	u := &Updater{market: buildMarket(cfg)}
+	if err := u.market.Start(); err != nil {
+		return nil, fmt.Errorf("could not start market: %w", err)
+	}
+	log.Info().Msg("market started")
+
+	u.election = pricingbyservice.NewLeaderElection(
+		pricingbyservice.NewStoreLeaderLock(db, pricingbyservice.LeaderRedisKey),
+		cfg.LeaderID,
+		cfg.LeaderLease,
+	)
+	u.election.Start()
+
+	pricerOpts := []pricingbyservice.PricerOption{
+		pricingbyservice.WithDemandBoostStorage(demandIndexHistory),
+		pricingbyservice.WithLeader(u.election),
+	}
+	if supply != nil {
+		pricerOpts = append(pricerOpts, pricingbyservice.WithSupplyProvider(supply))
+	}
+
+	u.pricer, err = pricingbyservice.NewPricer(
+		cfger,
+		u.market,
+		countryDemandIndexes,
+		pricingbyservice.DefaultPriceLifetime,
+		pricingbyservice.Bound{Min: 0.01, Max: 3.0},
+		db,
+		pricingbyservice.NewPriceHistoryStorage(db, cfg.PriceHistoryRetention),
+		pricerOpts...,
+	)
+	if err != nil {
+		u.election.Stop()
+		u.market.Stop()
+		return nil, fmt.Errorf("could not initialize pricer: %w", err)
+	}
+	log.Info().Msg("pricer started")
+
+	return u, nil
+}
+
+// LeaderStatus returns the leadership of this replica.
+func (u *Updater) LeaderStatus(ctx context.Context) (pricingbyservice.LeaderStatus, error) {
+	return u.election.Status(ctx)
+}
+
+// Stop stops the price updates before handing over the leadership.
+func (u *Updater) Stop() {
+	u.pricer.Stop()
+	u.election.Stop()
+	u.market.Stop()
+}
+
+func buildMarket(cfg *Options) *pricingbyservice.Market {
+	sources := []pricingbyservice.MarketSource{
+		{
+			Name:   "coingecko",
+			API:    coingecko.NewAPI(cfg.GeckoURL.String(), cfg.TokenRateCacheTTL),
+			Weight: cfg.MarketSourceWeights["coingecko"],
+		},
+	}
+	if cfg.CoinRankingToken != "" {
+		sources = append(sources, pricingbyservice.MarketSource{
+			Name:   "coinranking",
+			API:    coinranking.NewAPI(cfg.CoinRankingURL.String(), cfg.CoinRankingToken, cfg.TokenRateCacheTTL),
+			Weight: cfg.MarketSourceWeights["coinranking"],
+		})
+	}
+	mrkt := pricingbyservice.NewMarket(sources, time.Minute*15, pricingbyservice.MarketConfig{
+		Aggregation:  pricingbyservice.MarketAggregation(cfg.MarketAggregation),
+		MaxDeviation: cfg.MarketMaxDeviation,
+		MaxStaleness: cfg.MarketMaxStaleness,
+		Currencies:   cfg.MarketCurrencies,
+	})
+	return mrkt
+}
+
+const (
+	demandIndexProviderPrometheus = "prometheus"
+	demandIndexProviderFile       = "file"
+	demandIndexProviderRedis      = "redis"
+	demandIndexProviderComposite  = "composite"
+)
+
+func buildDemandIndexProvider(cfg *Options, db store.Store) (pricingbyservice.CountryDemandIndexProvider, error) {
+	if cfg.DemandIndexProvider != demandIndexProviderComposite {
+		return buildSingleDemandIndexProvider(cfg.DemandIndexProvider, cfg, db)
+	}
+
+	sources := make([]pricingbyservice.WeightedDemandIndexSource, 0, len(cfg.DemandIndexWeights))
+	for name, weight := range cfg.DemandIndexWeights {
+		provider, err := buildSingleDemandIndexProvider(name, cfg, db)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, pricingbyservice.WeightedDemandIndexSource{Name: name, Provider: provider, Weight: weight})
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("composite demand index provider requires DEMAND_INDEX_WEIGHTS")
+	}
+	return pricingbyservice.NewWeightedDemandIndexProvider(sources), nil
+}
+
+func buildSingleDemandIndexProvider(name string, cfg *Options, db store.Store) (pricingbyservice.CountryDemandIndexProvider, error) {
+	switch name {
+	case demandIndexProviderPrometheus:
+		if cfg.PrometheusURL.String() == "" {
+			return nil, errors.New("prometheus demand index provider requires PROMETHEUS_URL")
+		}
+		return pricingbyservice.NewPrometheusDemandIndexProvider(
+			&cfg.PrometheusURL,
+			cfg.PrometheusUsername,
+			cfg.PrometheusPassword,
+			cfg.PrometheusDemandIndexQuery,
+		), nil
+	case demandIndexProviderFile:
+		if cfg.DemandIndexFile == "" {
+			return nil, errors.New("file demand index provider requires DEMAND_INDEX_FILE")
+		}
+		return pricingbyservice.NewStaticDemandIndexProvider(cfg.DemandIndexFile), nil
+	case demandIndexProviderRedis:
+		return pricingbyservice.NewStoreDemandIndexProvider(db, cfg.DemandIndexRedisKey), nil
+	default:
+		return nil, fmt.Errorf("unknown demand index provider %q", name)
+	}
+}
+
+const (
+	supplyProviderDiscovery  = "discovery"
+	supplyProviderPrometheus = "prometheus"
+)
+
+// buildSupplyProvider returns nil when no supply provider is configured.
+func buildSupplyProvider(cfg *Options) (pricingbyservice.CountrySupplyProvider, error) {
+	switch cfg.SupplyProvider {
+	case "":
+		return nil, nil
+	case supplyProviderDiscovery:
+		if cfg.DiscoveryAPIURL.String() == "" {
+			return nil, errors.New("discovery supply provider requires DISCOVERY_API_URL")
+		}
+		return pricingbyservice.NewDiscoverySupplyProvider(&cfg.DiscoveryAPIURL), nil
+	case supplyProviderPrometheus:
+		if cfg.PrometheusURL.String() == "" || cfg.PrometheusSupplyQuery == "" {
+			return nil, errors.New("prometheus supply provider requires PROMETHEUS_URL and PROMETHEUS_SUPPLY_QUERY")
+		}
+		return pricingbyservice.NewPrometheusSupplyProvider(
+			&cfg.PrometheusURL,
+			cfg.PrometheusUsername,
+			cfg.PrometheusPassword,
+			cfg.PrometheusSupplyQuery,
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown supply provider %q", cfg.SupplyProvider)
+	}
+}